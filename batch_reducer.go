@@ -0,0 +1,53 @@
+package gocurrent
+
+import "time"
+
+// Batch is a window of items collected by [NewBatchReducer] together with
+// its metadata.
+type Batch[T any] struct {
+	// Items holds the collected items in arrival order.
+	Items []T
+	// Count is len(Items).
+	Count int
+	// Start and End are the arrival times of the first and last item. Both
+	// are zero for an empty batch.
+	Start, End time.Time
+}
+
+// BatchReducerOption configures a reducer created by NewBatchReducer.
+type BatchReducerOption[T any] = ReducerOption2[T, Batch[T]]
+
+// NewBatchReducer creates a reducer that collects items like NewIDReducer but
+// emits each window as a Batch carrying the item count and the arrival times
+// of the first and last item, taken from the reducer's clock (see
+// WithReducerClock). Like NewIDReducer it emits on every flush, including an
+// empty Batch for a window without items.
+//
+// Example:
+//
+//	batches := NewBatchReducer(WithFlushPeriod[Event, Batch[Event], Batch[Event]](time.Second))
+//	defer batches.Stop()
+//	b := <-batches.OutputChan()
+//	log.Printf("%d events in %v", b.Count, b.End.Sub(b.Start))
+func NewBatchReducer[T any](opts ...BatchReducerOption[T]) *Reducer2[T, Batch[T]] {
+	// The clock is read when collecting, once NewReducer has applied every
+	// option and defaulted it.
+	collectOpt := func(r *Reducer2[T, Batch[T]]) {
+		r.CollectFunc = func(b Batch[T], inputs ...T) (Batch[T], bool) {
+			if len(inputs) == 0 {
+				return b, false
+			}
+			now := r.clock.Now()
+			if b.Count == 0 {
+				b.Start = now
+			}
+			b.End = now
+			b.Items = append(b.Items, inputs...)
+			b.Count = len(b.Items)
+			return b, false
+		}
+	}
+	countOpt := WithCountFunc[T, Batch[T], Batch[T]](func(b Batch[T]) int { return b.Count })
+	allOpts := append([]BatchReducerOption[T]{collectOpt, countOpt}, opts...)
+	return NewReducer2(allOpts...)
+}
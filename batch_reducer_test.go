@@ -0,0 +1,57 @@
+package gocurrent
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBatchReducerMetadata(t *testing.T) {
+	reducer := NewBatchReducer(WithFlushPeriod[int, Batch[int], Batch[int]](10 * time.Second))
+	defer reducer.Stop()
+
+	before := time.Now()
+	reducer.Send(1)
+	time.Sleep(5 * time.Millisecond)
+	reducer.Send(2)
+	reducer.Send(3)
+	reducer.Flush()
+	after := time.Now()
+
+	b := withTimeout(t, reducer.OutputChan())
+	assert.Equal(t, []int{1, 2, 3}, b.Items)
+	assert.Equal(t, 3, b.Count)
+	assert.False(t, b.Start.Before(before))
+	assert.False(t, b.End.After(after))
+	assert.GreaterOrEqual(t, b.End.Sub(b.Start), 5*time.Millisecond)
+
+	// The next window starts fresh.
+	reducer.Flush()
+	empty := withTimeout(t, reducer.OutputChan())
+	assert.Zero(t, empty.Count)
+	assert.True(t, empty.Start.IsZero())
+}
+
+// TestBatchReducerClock verifies that batch times come from the reducer's
+// clock.
+func TestBatchReducerClock(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+	reducer := NewBatchReducer(
+		WithFlushPeriod[int, Batch[int], Batch[int]](time.Hour),
+		WithReducerClock[int, Batch[int], Batch[int]](clock))
+	defer reducer.Stop()
+
+	reducer.Send(1)
+	reducer.Flush()
+	b := withTimeout(t, reducer.OutputChan())
+	assert.Equal(t, start, b.Start)
+	assert.Equal(t, start, b.End)
+
+	clock.Advance(time.Minute)
+	reducer.Send(2)
+	reducer.Flush()
+	b = withTimeout(t, reducer.OutputChan())
+	assert.Equal(t, start.Add(time.Minute), b.Start)
+}
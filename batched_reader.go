@@ -0,0 +1,164 @@
+package gocurrent
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// BatchedReader wires a [Reader] into an identity [Reducer] so that the
+// values produced by a ReaderFunc are delivered in batches. A batch is
+// emitted when it reaches MaxItems values or when MaxWait has elapsed since
+// the previous flush, whichever comes first. Empty windows are not emitted.
+//
+// The BatchedReader owns and manages all of its internal components:
+//
+//	Reader ──► Mapper (unwrap Message, detect errors) ──► Reducer ──► Mapper (drop empty) ──► OutputChan()
+//
+// When the ReaderFunc returns an error, or when Stop() is called, the reader
+// is stopped, the final partial batch is flushed, the output channel is
+// closed and ClosedChan() receives the read error (nil on a normal stop).
+// Consumers should keep reading OutputChan() until it is closed so the final
+// flush can be delivered.
+type BatchedReader[R any] struct {
+	maxItems int
+	maxWait  time.Duration
+
+	reader   *Reader[R]
+	unwrap   *Mapper[Message[R], R]
+	reducer  *Reducer2[R, []R]
+	drop     *Mapper[[]R, []R]
+	batches  chan []R
+	outChan  chan []R
+	running  atomic.Bool
+	stopOnce sync.Once
+	ready    chan struct{} // closed once all components are constructed
+	stopped  chan struct{}
+
+	err        error
+	closedChan chan error
+}
+
+// BatchedReaderOption is a functional option for configuring a BatchedReader
+type BatchedReaderOption[R any] func(*BatchedReader[R])
+
+// WithMaxItems flushes a batch as soon as it holds n values. A value <= 0
+// (the default) disables size-based flushing.
+func WithMaxItems[R any](n int) BatchedReaderOption[R] {
+	return func(b *BatchedReader[R]) {
+		b.maxItems = n
+	}
+}
+
+// WithMaxWait sets the longest time values are held before a (possibly
+// partial) batch is flushed. Defaults to 100ms.
+func WithMaxWait[R any](d time.Duration) BatchedReaderOption[R] {
+	return func(b *BatchedReader[R]) {
+		b.maxWait = d
+	}
+}
+
+// NewBatchedReader creates a BatchedReader that calls read continuously and
+// emits the results as []R batches. It starts running immediately.
+//
+// Example:
+//
+//	br := NewBatchedReader(readRow, WithMaxItems[Row](100), WithMaxWait[Row](time.Second))
+//	defer br.Stop()
+//	for batch := range br.OutputChan() {
+//	    insertRows(batch)
+//	}
+func NewBatchedReader[R any](read ReaderFunc[R], opts ...BatchedReaderOption[R]) *BatchedReader[R] {
+	b := &BatchedReader[R]{
+		maxWait:    100 * time.Millisecond,
+		batches:    make(chan []R),
+		outChan:    make(chan []R),
+		ready:      make(chan struct{}),
+		stopped:    make(chan struct{}),
+		closedChan: make(chan error, 1),
+	}
+	for _, opt := range opts {
+		opt(b)
+	}
+	b.running.Store(true)
+
+	maxItems := b.maxItems
+	b.reducer = NewIDReducer(
+		WithFlushPeriod[R, []R, []R](b.maxWait),
+		WithOutputChan[R, []R](b.batches),
+		WithCollectFunc[R, []R, []R](func(collection []R, inputs ...R) ([]R, bool) {
+			collection = append(collection, inputs...)
+			return collection, maxItems > 0 && len(collection) >= maxItems
+		}))
+	b.drop = NewMapper(b.batches, b.outChan, func(batch []R) ([]R, bool, bool) {
+		return batch, len(batch) == 0, false
+	})
+	b.reader = NewReader(read)
+	b.unwrap = NewMapper(b.reader.OutputChan(), b.reducer.InputChan(),
+		func(msg Message[R]) (R, bool, bool) {
+			if msg.Error != nil {
+				b.err = msg.Error
+				return msg.Value, true, true
+			}
+			return msg.Value, false, false
+		},
+		WithMapperOnDone(func(*Mapper[Message[R], R]) {
+			// The unwrap mapper exits on a read error or on Stop. Shut down
+			// asynchronously since we are inside its goroutine.
+			go b.shutdown()
+		}))
+	close(b.ready)
+	return b
+}
+
+// OutputChan returns the channel on which batches are delivered. It is
+// closed once the BatchedReader has shut down and the final batch has been
+// delivered.
+func (b *BatchedReader[R]) OutputChan() <-chan []R {
+	return b.outChan
+}
+
+// ClosedChan returns the channel used to signal when the batched reader is
+// done. It receives the read error that terminated it, or nil on Stop().
+func (b *BatchedReader[R]) ClosedChan() <-chan error {
+	return b.closedChan
+}
+
+// IsRunning returns true until the batched reader has shut down.
+func (b *BatchedReader[R]) IsRunning() bool {
+	return b.running.Load()
+}
+
+// Stop stops the reader, flushes the final partial batch and closes the
+// output channel. It blocks until the final batch has been consumed. Safe to
+// call multiple times.
+func (b *BatchedReader[R]) Stop() error {
+	b.shutdown()
+	<-b.stopped
+	return nil
+}
+
+// shutdown tears the pipeline down from source to sink exactly once.
+func (b *BatchedReader[R]) shutdown() {
+	// A read error can end the unwrap mapper before the constructor has
+	// finished assigning the component fields.
+	<-b.ready
+	b.stopOnce.Do(func() {
+		b.reader.Stop()
+		b.unwrap.Stop()
+		b.reducer.Flush()
+		b.reducer.Stop()
+		// The drop mapper exits once batches is closed and drained, after
+		// delivering the final flush.
+		close(b.batches)
+		<-b.drop.ClosedChan()
+		close(b.outChan)
+
+		if b.err != nil {
+			b.closedChan <- b.err
+		}
+		close(b.closedChan)
+		b.running.Store(false)
+		close(b.stopped)
+	})
+}
@@ -0,0 +1,87 @@
+package gocurrent
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// counterSource returns a ReaderFunc yielding 0, 1, 2, ... and, if failAt is
+// positive, an error once failAt values have been produced.
+func counterSource(failAt int) ReaderFunc[int] {
+	next := 0
+	return func() (int, error) {
+		if failAt > 0 && next >= failAt {
+			return 0, errors.New("source exhausted")
+		}
+		next++
+		return next - 1, nil
+	}
+}
+
+func TestBatchedReaderBatchesBySize(t *testing.T) {
+	br := NewBatchedReader(counterSource(0),
+		WithMaxItems[int](5),
+		WithMaxWait[int](10*time.Second))
+
+	expected := 0
+	for range 4 {
+		batch := withTimeout(t, br.OutputChan())
+		assert.Len(t, batch, 5, "Batches should be flushed at MaxItems")
+		for _, v := range batch {
+			assert.Equal(t, expected, v, "Values should be contiguous and ordered")
+			expected++
+		}
+	}
+
+	// Keep draining so the final flush on Stop can be delivered.
+	go func() {
+		for range br.OutputChan() {
+		}
+	}()
+	assert.NoError(t, br.Stop())
+	assert.NoError(t, <-br.ClosedChan())
+	assert.False(t, br.IsRunning())
+}
+
+func TestBatchedReaderFlushesPartialBatchOnError(t *testing.T) {
+	br := NewBatchedReader(counterSource(7),
+		WithMaxItems[int](5),
+		WithMaxWait[int](10*time.Second))
+
+	var batches [][]int
+	for batch := range br.OutputChan() {
+		batches = append(batches, batch)
+	}
+	assert.Equal(t, [][]int{{0, 1, 2, 3, 4}, {5, 6}}, batches)
+
+	select {
+	case err := <-br.ClosedChan():
+		assert.EqualError(t, err, "source exhausted")
+	case <-time.After(testTimeout):
+		t.Fatal("Timed out waiting for ClosedChan")
+	}
+	assert.NoError(t, br.Stop(), "Stop after self-termination should be a no-op")
+}
+
+func TestBatchedReaderFlushesOnMaxWait(t *testing.T) {
+	unblock := make(chan struct{})
+	produced := 0
+	br := NewBatchedReader(func() (int, error) {
+		if produced == 3 {
+			<-unblock
+			return 0, errors.New("closed")
+		}
+		produced++
+		return produced, nil
+	}, WithMaxItems[int](100), WithMaxWait[int](20*time.Millisecond))
+
+	batch := withTimeout(t, br.OutputChan())
+	assert.Equal(t, []int{1, 2, 3}, batch, "Partial batch should flush after MaxWait")
+
+	close(unblock)
+	for range br.OutputChan() {
+	}
+}
@@ -1,7 +1,11 @@
 package gocurrent
 
 import (
+	"errors"
 	"fmt"
+	"slices"
+	"sort"
+	"strings"
 	"sync"
 )
 
@@ -15,6 +19,15 @@ type Component interface {
 	IsRunning() bool
 }
 
+// Starter is implemented by components that can be constructed without
+// running and started later (see WithReaderDeferredStart,
+// WithWriterDeferredStart and WithMapperDeferredStart).
+type Starter interface {
+	// Start begins processing. It returns ErrAlreadyRunning if the
+	// component was already started.
+	Start() error
+}
+
 // InputComponent represents a component with an input channel
 type InputComponent[T any] interface {
 	Component
@@ -36,12 +49,59 @@ type OutputComponent[T any] interface {
 
 // Block represents a composite component made up of multiple connected primitives.
 // A Block itself acts as a component and can be nested within other Blocks.
+//
+// A block is started once Start() has been called, or as soon as a component
+// that is already running is added (primitives run from construction unless
+// created with a deferred-start option). Stop() does nothing until the block
+// is started. IsRunning() is independent of that flag: it reports whether
+// any member is running, so a block assembled from deferred components
+// reports false until Start().
 type Block struct {
 	name       string
 	components []Component
 	mu         sync.RWMutex
 	started    bool
 	wg         sync.WaitGroup
+	errsChan   chan error    // created by the first ErrorsChan call
+	watchStop  chan struct{} // closed by Stop to release error watchers
+	edges      []pipeEdge    // Connect/ConnectWith pipes added to the block
+}
+
+// ErrComponentNotFound is returned by Block.Remove and Block.Replace when the
+// component is not a member of the block.
+var ErrComponentNotFound = errors.New("component not found in block")
+
+// ErrNilChannel is returned by Connect and ConnectWith when a component
+// exposes a nil channel, e.g. a Mapper, which does not own its channels.
+var ErrNilChannel = errors.New("component has no channel to connect")
+
+// errorSource is implemented by components that report how they finished
+// on a ClosedChan (Reader, Writer, Mapper, FanIn, FanOut, ...).
+type errorSource interface {
+	ClosedChan() <-chan error
+}
+
+// BlockError is delivered on Block.ErrorsChan when a member component
+// finishes with an error.
+type BlockError struct {
+	// Block is the name of the block reporting the error.
+	Block string
+	// Index is the position of the component in the block when it started
+	// being watched. Removing earlier components shifts later positions.
+	Index int
+	// Component is the component that failed.
+	Component Component
+	// Err is the error the component reported.
+	Err error
+}
+
+func (e *BlockError) Error() string {
+	return fmt.Sprintf("block %q component %d (%T): %v", e.Block, e.Index, e.Component, e.Err)
+}
+
+// Unwrap returns the component's error.
+func (e *BlockError) Unwrap() error {
+	return e.Err
 }
 
 // NewBlock creates a new block with the given name
@@ -52,27 +112,235 @@ func NewBlock(name string) *Block {
 	}
 }
 
-// Add adds a component to this block
+// Add adds a component to this block. Adding a running component marks the
+// block as started. A deferred component added to a started block is started
+// immediately.
 func (b *Block) Add(component Component) {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
 	b.components = append(b.components, component)
+	b.addEdge(component)
+	if b.errsChan != nil {
+		b.watchErrors(len(b.components)-1, component)
+	}
+	if b.started {
+		startComponent(component)
+	} else if component.IsRunning() {
+		b.started = true
+	}
+}
+
+// Remove stops component and removes it from the block, leaving the other
+// members running. It returns ErrComponentNotFound if component is not a
+// member, or the error from stopping it (the component is removed either
+// way).
+func (b *Block) Remove(component Component) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	i := b.indexOf(component)
+	if i < 0 {
+		return ErrComponentNotFound
+	}
+	b.components = append(b.components[:i], b.components[i+1:]...)
+	b.removeEdge(component)
+	if err := component.Stop(); err != nil {
+		return fmt.Errorf("failed to stop removed component: %w", err)
+	}
+	return nil
+}
+
+// Replace swaps old for replacement at the same position: old is stopped
+// first, then replacement takes its place and, if the block is started, is
+// started (see Starter). It returns ErrComponentNotFound if old is not a
+// member, in which case nothing is changed.
+func (b *Block) Replace(old, replacement Component) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	i := b.indexOf(old)
+	if i < 0 {
+		return ErrComponentNotFound
+	}
+	stopErr := old.Stop()
+	b.components[i] = replacement
+	b.removeEdge(old)
+	b.addEdge(replacement)
+	if b.errsChan != nil {
+		b.watchErrors(i, replacement)
+	}
+	var startErr error
+	if b.started {
+		startErr = startComponent(replacement)
+	}
+	if stopErr != nil {
+		stopErr = fmt.Errorf("failed to stop replaced component: %w", stopErr)
+	}
+	if startErr != nil {
+		startErr = fmt.Errorf("failed to start replacement component: %w", startErr)
+	}
+	return errors.Join(stopErr, startErr)
+}
+
+// addEdge records the edge of comp if it is a Connect/ConnectWith pipe.
+// Must hold mu.
+func (b *Block) addEdge(comp Component) {
+	if p, ok := comp.(connectedPipe); ok && p.connection() != nil {
+		b.edges = append(b.edges, pipeEdge{pipe: comp, connection: *p.connection()})
+	}
+}
+
+// removeEdge forgets the edge of pipe, if any. Must hold mu.
+func (b *Block) removeEdge(pipe Component) {
+	b.edges = slices.DeleteFunc(b.edges, func(e pipeEdge) bool { return e.pipe == pipe })
+}
+
+// indexOf returns the position of component, or -1. Must hold mu.
+func (b *Block) indexOf(component Component) int {
+	for i, comp := range b.components {
+		if comp == component {
+			return i
+		}
+	}
+	return -1
+}
+
+// ErrorsChan returns a channel reporting, as *BlockError, every non-nil
+// error a member delivers on its ClosedChan(). Members without a ClosedChan
+// are not watched. Components added later are watched too. The channel is
+// created on first call and never closed. Errors that have not been read
+// when the block is stopped are discarded, so a block whose errors nobody
+// reads does not leak its watchers.
+//
+// Watching consumes each member's ClosedChan value, so do not also read the
+// ClosedChan of a member directly once ErrorsChan has been called.
+func (b *Block) ErrorsChan() <-chan error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.errsChan == nil {
+		b.errsChan = make(chan error, len(b.components)+1)
+		b.watchStop = make(chan struct{})
+		for i, comp := range b.components {
+			b.watchErrors(i, comp)
+		}
+	}
+	return b.errsChan
+}
+
+// watchErrors forwards the first value on comp's ClosedChan to errsChan if
+// it is an error, giving up when the block is stopped. Must hold mu.
+func (b *Block) watchErrors(index int, comp Component) {
+	src, ok := comp.(errorSource)
+	if !ok {
+		return
+	}
+	closed, errs, stop := src.ClosedChan(), b.errsChan, b.watchStop
+	go func() {
+		var err error
+		select {
+		case err = <-closed:
+		case <-stop:
+			return
+		}
+		if err == nil {
+			return
+		}
+		select {
+		case errs <- &BlockError{Block: b.name, Index: index, Component: comp, Err: err}:
+		case <-stop:
+		}
+	}()
+}
+
+// Start starts every member that implements Starter and has not been started
+// yet, in the order they were added, and marks the block as started. Use it
+// with deferred-start components to assemble and wire a whole block before
+// any of it begins processing. Calling Start on a started block is a no-op.
+func (b *Block) Start() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var errs []error
+	for i, comp := range b.components {
+		if err := startComponent(comp); err != nil {
+			errs = append(errs, fmt.Errorf("failed to start component %d: %w", i, err))
+		}
+	}
+	b.started = true
+	return errors.Join(errs...)
+}
+
+// startComponent starts comp if it is a Starter that has not been started.
+func startComponent(comp Component) error {
+	s, ok := comp.(Starter)
+	if !ok || comp.IsRunning() {
+		return nil
+	}
+	if err := s.Start(); err != nil && !errors.Is(err, ErrAlreadyRunning) {
+		return err
+	}
+	return nil
+}
+
+// connection is the edge a Connect/ConnectWith pipe forms between two
+// components.
+type connection struct {
+	from, to Component
+	label    string
+}
+
+// connectedPipe is implemented by pipes that know the edge they form.
+type connectedPipe interface {
+	connection() *connection
+}
+
+// connection returns the edge formed by a Connect/ConnectWith pipe, or nil
+// for other mappers.
+func (m *Mapper[I, O]) connection() *connection {
+	return m.conn
+}
+
+// pipeEdge is a connection recorded on a Block when its pipe is added.
+type pipeEdge struct {
+	pipe Component
+	connection
 }
 
 // Connect connects the output of one component to the input of another
-// using a Pipe. Returns the pipe so it can be managed if needed.
-func Connect[T any](from OutputComponent[T], to InputComponent[T]) *Mapper[T, T] {
-	return NewPipe(from.OutputChan(), to.InputChan())
+// using a Pipe. Returns the pipe so it can be managed if needed; adding it
+// to the block holding both components records the edge for Graph. It returns
+// an error wrapping ErrNilChannel, and starts nothing, if from has no output
+// channel or to has no input channel.
+func Connect[T any](from OutputComponent[T], to InputComponent[T]) (*Mapper[T, T], error) {
+	return connect(from, to, "pipe", idMapperFunc[T])
 }
 
-// ConnectWith connects two components using a custom mapper function
+// ConnectWith connects two components using a custom mapper function. Like
+// Connect, it fails with ErrNilChannel if either side has no channel.
 func ConnectWith[I, O any](from OutputComponent[I], to InputComponent[O],
-	mapper func(I) (O, bool, bool)) *Mapper[I, O] {
-	return NewMapper(from.OutputChan(), to.InputChan(), mapper)
+	mapper func(I) (O, bool, bool)) (*Mapper[I, O], error) {
+	return connect(from, to, "map", mapper)
 }
 
-// Stop stops all components in this block in reverse order
+// connect starts a mapper between from and to that remembers the edge.
+func connect[I, O any](from OutputComponent[I], to InputComponent[O], label string,
+	fn func(I) (O, bool, bool)) (*Mapper[I, O], error) {
+	if from.OutputChan() == nil {
+		return nil, fmt.Errorf("%w: output of %s", ErrNilChannel, componentLabel(from))
+	}
+	if to.InputChan() == nil {
+		return nil, fmt.Errorf("%w: input of %s", ErrNilChannel, componentLabel(to))
+	}
+	pipe := NewMapper(from.OutputChan(), to.InputChan(), fn, WithMapperDeferredStart[I, O]())
+	pipe.conn = &connection{from: from, to: to, label: label}
+	pipe.Start()
+	return pipe, nil
+}
+
+// Stop stops all components in this block in reverse order. Every component
+// is stopped even if some fail; their errors are returned joined.
 func (b *Block) Stop() error {
 	b.mu.Lock()
 	defer b.mu.Unlock()
@@ -81,16 +349,23 @@ func (b *Block) Stop() error {
 		return nil
 	}
 
-	// Stop in reverse order to allow downstream components to drain
+	// Stop in reverse order to allow downstream components to drain. A
+	// failing Stop does not prevent the remaining components from stopping.
+	var errs []error
 	for i := len(b.components) - 1; i >= 0; i-- {
 		if err := b.components[i].Stop(); err != nil {
-			return fmt.Errorf("failed to stop component %d: %w", i, err)
+			errs = append(errs, fmt.Errorf("failed to stop component %d: %w", i, err))
 		}
 	}
 
+	if b.watchStop != nil {
+		// Release the watchers; components added later get new ones.
+		close(b.watchStop)
+		b.watchStop = make(chan struct{})
+	}
 	b.started = false
 	b.wg.Wait()
-	return nil
+	return errors.Join(errs...)
 }
 
 // IsRunning returns true if any component in the block is running
@@ -106,6 +381,70 @@ func (b *Block) IsRunning() bool {
 	return false
 }
 
+// Graph returns a Graphviz DOT description of the block: one node per member,
+// labelled with its type (and name, for named components such as nested
+// blocks), and one edge per Connect/ConnectWith pipe that was added to the
+// block and joins two of its members. Such pipes are drawn as edges rather
+// than nodes, and remain drawn after they stop until they are removed.
+//
+// Render it with, for example, `dot -Tsvg`.
+func (b *Block) Graph() string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	ids := make(map[Component]int, len(b.components))
+	for i, comp := range b.components {
+		ids[comp] = i
+	}
+	type edge struct {
+		from, to int
+		label    string
+	}
+	var edges []edge
+	pipes := make(map[Component]bool)
+	for _, e := range b.edges {
+		from, okFrom := ids[e.from]
+		to, okTo := ids[e.to]
+		if okFrom && okTo {
+			edges = append(edges, edge{from, to, e.label})
+			pipes[e.pipe] = true
+		}
+	}
+	// Sort by endpoints rather than the order the pipes were added
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].from != edges[j].from {
+			return edges[i].from < edges[j].from
+		}
+		return edges[i].to < edges[j].to
+	})
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "digraph %q {\n", b.name)
+	sb.WriteString("  rankdir=LR;\n")
+	for i, comp := range b.components {
+		if pipes[comp] {
+			continue
+		}
+		fmt.Fprintf(&sb, "  c%d [label=%q];\n", i, componentLabel(comp))
+	}
+	for _, e := range edges {
+		fmt.Fprintf(&sb, "  c%d -> c%d [label=%q];\n", e.from, e.to, e.label)
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// componentLabel describes comp by its type without the package qualifier,
+// followed by its name if it has one.
+func componentLabel(comp Component) string {
+	label := strings.TrimPrefix(fmt.Sprintf("%T", comp), "*")
+	label = strings.ReplaceAll(label, "gocurrent.", "")
+	if named, ok := comp.(interface{ Name() string }); ok {
+		label += ": " + named.Name()
+	}
+	return label
+}
+
 // Name returns the block's name
 func (b *Block) Name() string {
 	return b.name
@@ -120,19 +459,120 @@ func (b *Block) Count() int {
 
 // Example composite components that implement common patterns:
 
-// Pipeline creates a linear sequence of components connected by pipes
+// Pipeline creates a linear sequence of components connected by pipes.
+//
+// Values sent to InputChan() flow through every added stage in the order the
+// stages were added and come out of OutputChan(). Stages can be added while
+// the pipeline is running; a value already past the previous last stage is
+// still delivered, and later values go through the new stage.
 type Pipeline[T any] struct {
 	*Block
-	input  chan T
-	output chan T
+	input    chan T
+	output   chan T
+	wireMu   sync.Mutex
+	stages   []Component     // wired stages (and their pipes) in data-flow order
+	tail     <-chan T        // output of the last stage (input when empty)
+	retarget chan (<-chan T) // tells the forwarder to read from a new tail
+	draining chan struct{}   // forwarder discards instead of forwarding
+	exitFwd  chan struct{}
+	fwdDone  chan struct{}
+	stopOnce sync.Once
 }
 
 // NewPipeline creates a new pipeline block
 func NewPipeline[T any](name string) *Pipeline[T] {
-	return &Pipeline[T]{
-		Block:  NewBlock(name),
-		input:  make(chan T),
-		output: make(chan T),
+	p := &Pipeline[T]{
+		Block:    NewBlock(name),
+		input:    make(chan T),
+		output:   make(chan T),
+		retarget: make(chan (<-chan T)),
+		draining: make(chan struct{}),
+		exitFwd:  make(chan struct{}),
+		fwdDone:  make(chan struct{}),
+	}
+	p.tail = p.input
+	go p.forward()
+	return p
+}
+
+// forward moves values from the current tail to the pipeline output. It
+// holds at most one value at a time and accepts a new tail even while that
+// value is waiting to be read. Once draining, it discards values so that
+// stages blocked on sending can observe their stop signal.
+func (p *Pipeline[T]) forward() {
+	defer close(p.fwdDone)
+	var tail <-chan T = p.input
+	var pending T
+	hasPending := false
+	draining := p.draining
+	for {
+		var in <-chan T
+		var out chan<- T
+		if hasPending {
+			out = p.output
+		} else {
+			in = tail
+		}
+		select {
+		case <-p.exitFwd:
+			return
+		case <-draining:
+			draining = nil
+			hasPending = false
+		case tail = <-p.retarget:
+		case value, ok := <-in:
+			if !ok {
+				tail = nil
+				continue
+			}
+			pending, hasPending = value, draining != nil
+		case out <- pending:
+			hasPending = false
+		}
+	}
+}
+
+// Add adds a component to the pipeline. If it is both an InputComponent[T]
+// and an OutputComponent[T] with non-nil channels, it is appended as a stage:
+// the previous last stage (or the pipeline input) is piped into its
+// InputChan() and its OutputChan() then feeds the pipeline output. Other
+// components are only managed by the block, as with Block.Add.
+func (p *Pipeline[T]) Add(component Component) {
+	in, isIn := component.(InputComponent[T])
+	out, isOut := component.(OutputComponent[T])
+	if !isIn || !isOut || in.InputChan() == nil || out.OutputChan() == nil {
+		p.Block.Add(component)
+		return
+	}
+	p.wireMu.Lock()
+	defer p.wireMu.Unlock()
+	p.setTail(out.OutputChan())
+	pipe := NewPipe(p.tail, in.InputChan())
+	p.Block.Add(component)
+	p.Block.Add(pipe)
+	p.stages = append(p.stages, pipe, component)
+	p.tail = out.OutputChan()
+}
+
+// AddMapper appends a stage applying fn, with the same (output, skip, stop)
+// contract as NewMapper, and returns the stage's mapper.
+func (p *Pipeline[T]) AddMapper(fn func(T) (T, bool, bool)) *Mapper[T, T] {
+	p.wireMu.Lock()
+	defer p.wireMu.Unlock()
+	out := make(chan T)
+	p.setTail(out)
+	mapper := NewMapper(p.tail, out, fn)
+	p.Block.Add(mapper)
+	p.stages = append(p.stages, mapper)
+	p.tail = out
+	return mapper
+}
+
+// setTail points the forwarder at a new last-stage output. Must hold wireMu.
+func (p *Pipeline[T]) setTail(tail <-chan T) {
+	select {
+	case p.retarget <- tail:
+	case <-p.fwdDone:
 	}
 }
 
@@ -151,6 +591,23 @@ func (p *Pipeline[T]) Send(value T) {
 	p.input <- value
 }
 
+// Stop stops the pipeline. Values still in flight are discarded. Stages are
+// stopped first to last, so each stage's downstream keeps reading until the
+// stage has stopped, then any other members are stopped as with Block.Stop.
+func (p *Pipeline[T]) Stop() error {
+	p.stopOnce.Do(func() {
+		p.wireMu.Lock()
+		defer p.wireMu.Unlock()
+		close(p.draining)
+		for _, stage := range p.stages {
+			stage.Stop()
+		}
+		close(p.exitFwd)
+		<-p.fwdDone
+	})
+	return p.Block.Stop()
+}
+
 // Example: Broadcast pattern - one input, multiple outputs.
 // Uses QueuedFanOut for strict FIFO ordering with non-blocking sends.
 type Broadcast[T any] struct {
@@ -158,9 +615,11 @@ type Broadcast[T any] struct {
 	fanout *QueuedFanOut[T]
 }
 
-// NewBroadcast creates a broadcast block using QueuedFanOut
-func NewBroadcast[T any](name string) *Broadcast[T] {
-	fanout := NewQueuedFanOut[T]()
+// NewBroadcast creates a broadcast block using QueuedFanOut. opts are passed
+// to NewQueuedFanOut; in particular WithFanOutDropPolicy(DropNewest) keeps a
+// slow subscriber from stalling the others.
+func NewBroadcast[T any](name string, opts ...any) *Broadcast[T] {
+	fanout := NewQueuedFanOut[T](opts...)
 	block := NewBlock(name)
 	block.Add(fanout)
 
@@ -185,6 +644,25 @@ func (b *Broadcast[T]) AddOutput(filter FilterFunc[T]) chan T {
 	return b.fanout.New(filter)
 }
 
+// AddBufferedOutput adds a new output channel with a buffer of size events,
+// so a subscriber can fall behind by that much before it blocks the
+// broadcast (or, under DropNewest, starts missing events).
+func (b *Broadcast[T]) AddBufferedOutput(filter FilterFunc[T], size int) chan T {
+	return b.fanout.newOutput(filter, size)
+}
+
+// RemoveOutput unsubscribes an output returned by AddOutput or
+// AddBufferedOutput. It returns once the output receives no further events;
+// the channel itself is closed when the broadcast stops.
+func (b *Broadcast[T]) RemoveOutput(output chan T) {
+	<-b.fanout.Remove(output, true)
+}
+
+// Stats returns the delivery counts of the underlying fan-out.
+func (b *Broadcast[T]) Stats() FanOutStats[T] {
+	return b.fanout.Stats()
+}
+
 // Example: Merge pattern - multiple inputs, one output
 type Merge[T any] struct {
 	*Block
@@ -212,3 +690,111 @@ func (m *Merge[T]) OutputChan() <-chan T {
 func (m *Merge[T]) AddInput(input <-chan T) {
 	m.fanin.Add(input)
 }
+
+// Funnel is the Block returned by NewFunnel. Besides the Block methods it
+// reports, on ClosedChan(), when the whole flow has finished.
+type Funnel struct {
+	*Block
+	mu         sync.Mutex
+	err        error // first error reported by a member
+	closedChan chan error
+}
+
+// ClosedChan fires once the reader, mapper and writer have all exited,
+// whether because the source ran out, a read or write failed, or Stop was
+// called. The first error reported by any of them is sent (e.g.
+// ErrReaderClosed once read returns io.EOF) before the channel is closed; a
+// plain Stop just closes it.
+func (f *Funnel) ClosedChan() <-chan error {
+	return f.closedChan
+}
+
+// record keeps the first non-nil error reported by a member.
+func (f *Funnel) record(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err == nil {
+		f.err = err
+	}
+}
+
+// NewFunnel assembles the common "read from a source, transform, write to a
+// sink" flow into a running Block: a Reader calling read, a Mapper applying
+// transform to every value read, and a Writer calling write with each
+// result. transform filters and stops like any MapFunc. Stopping the funnel
+// stops all three. A read error, including io.EOF, lets the mapper finish
+// what it has already read and then winds the funnel down, as does a write
+// error or a stop requested by transform. Errors are reported on the
+// block's ErrorsChan() and the first one on ClosedChan().
+//
+// Example:
+//
+//	funnel := NewFunnel(readLine, parseRecord, store)
+//	defer funnel.Stop()
+//	err := <-funnel.ClosedChan() // ErrReaderClosed at the end of input
+func NewFunnel[I, O any](read ReaderFunc[I], transform func(I) (O, bool, bool), write func(O) error) *Funnel {
+	reader := NewReader(read)
+	writer := NewWriter(WriterFunc[O](write))
+	mapper := NewMapper(reader.OutputChan(), writer.InputChan(), func(msg Message[I]) (O, bool, bool) {
+		if msg.Error != nil {
+			// Reported on the reader's ClosedChan instead. Nothing more
+			// will be read, so stop once everything before it is written.
+			var zero O
+			return zero, true, true
+		}
+		return transform(msg.Value)
+	})
+	// The mapper never drops a value it is sending, so once it has exited
+	// everything it produced has reached the writer and the rest of the
+	// funnel can stop.
+	mapper.RegisterOnStop(func(error) {
+		go reader.Stop()
+		go writer.Stop()
+	})
+	// A failed write stops the writer while the mapper may be blocked
+	// sending to it, so discard what the mapper still sends and stop the
+	// source and mapper too; otherwise stopping the block would hang.
+	input := writer.msgChannel
+	writer.RegisterOnStop(func(err error) {
+		if err == nil {
+			return
+		}
+		go func() {
+			for {
+				select {
+				case <-input:
+				case <-mapper.Done():
+					return
+				}
+			}
+		}()
+		go reader.Stop()
+		go mapper.Stop()
+	})
+
+	// The sink is added first so that Stop, which runs in reverse, stops
+	// the source first and the mapper is never left sending to a stopped
+	// writer.
+	block := NewBlock("funnel")
+	block.Add(writer)
+	block.Add(mapper)
+	block.Add(reader)
+
+	f := &Funnel{Block: block, closedChan: make(chan error, 1)}
+	reader.RegisterOnStop(f.record)
+	mapper.RegisterOnStop(f.record)
+	writer.RegisterOnStop(f.record)
+	go func() {
+		<-reader.Done()
+		<-mapper.Done()
+		<-writer.Done()
+		f.mu.Lock()
+		err := f.err
+		f.mu.Unlock()
+		if err != nil {
+			f.closedChan <- err
+		}
+		close(f.closedChan)
+	}()
+	return f
+}
@@ -0,0 +1,385 @@
+package gocurrent
+
+import (
+	"errors"
+	"io"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPipelineWiresStages(t *testing.T) {
+	p := NewPipeline[int]("doubler")
+	double := func(v int) (int, bool, bool) { return v * 2, false, false }
+	m1 := p.AddMapper(double)
+	m2 := p.AddMapper(double)
+	assert.Equal(t, 2, p.Count())
+
+	for i := 1; i <= 3; i++ {
+		go p.Send(i)
+		assert.Equal(t, i*4, withTimeout(t, p.OutputChan()))
+	}
+
+	assert.NoError(t, p.Stop())
+	assert.False(t, m1.IsRunning())
+	assert.False(t, m2.IsRunning())
+	assert.False(t, p.IsRunning())
+}
+
+func TestPipelineAddsComponentStages(t *testing.T) {
+	p := NewPipeline[int]("passthrough")
+	defer p.Stop()
+
+	// An empty pipeline forwards input to output
+	go p.Send(1)
+	assert.Equal(t, 1, withTimeout(t, p.OutputChan()))
+
+	// A component with its own input and output channels becomes a stage
+	inner := NewPipeline[int]("inner")
+	defer inner.Stop()
+	inner.AddMapper(func(v int) (int, bool, bool) { return v + 100, false, false })
+	p.Add(inner)
+
+	go p.Send(2)
+	assert.Equal(t, 102, withTimeout(t, p.OutputChan()))
+}
+
+func TestBlockDeferredStart(t *testing.T) {
+	var reads atomic.Int32
+	source := make(chan int, 3)
+	source <- 1
+	reader := NewReader(func() (int, error) {
+		reads.Add(1)
+		return <-source, nil
+	}, WithReaderDeferredStart[int]())
+	results := make(chan int, 10)
+	mapper := NewMapper(reader.OutputChan(), results, func(m Message[int]) (int, bool, bool) {
+		return m.Value * 10, false, false
+	}, WithMapperDeferredStart[Message[int], int]())
+
+	block := NewBlock("deferred")
+	block.Add(reader)
+	block.Add(mapper)
+
+	// Nothing runs until the block is started
+	time.Sleep(20 * time.Millisecond)
+	assert.False(t, block.IsRunning())
+	assert.False(t, reader.IsRunning())
+	assert.Equal(t, int32(0), reads.Load())
+	assert.NoError(t, block.Stop(), "Stopping an unstarted block is a no-op")
+
+	assert.NoError(t, block.Start())
+	assert.True(t, block.IsRunning())
+	assert.Equal(t, 10, withTimeout(t, results))
+	assert.ErrorIs(t, reader.Start(), ErrAlreadyRunning)
+	assert.NoError(t, block.Start(), "Starting twice is a no-op")
+
+	// Deferred components added to a started block start immediately
+	more := make(chan int, 1)
+	writer := NewWriter(func(v int) error {
+		more <- v
+		return nil
+	}, WithWriterDeferredStart[int]())
+	block.Add(writer)
+	assert.True(t, writer.Send(7))
+	assert.Equal(t, 7, withTimeout(t, more))
+
+	assert.NoError(t, block.Stop())
+	assert.False(t, block.IsRunning())
+}
+
+func TestBlockErrorsChan(t *testing.T) {
+	block := NewBlock("monitored")
+	writer := NewWriter(func(int) error { return nil })
+	block.Add(writer)
+	errs := block.ErrorsChan()
+
+	// Components added after ErrorsChan() are watched as well
+	readErr := errors.New("connection reset")
+	fail := make(chan struct{})
+	reader := NewReader(func() (int, error) {
+		<-fail
+		return 0, readErr
+	})
+	block.Add(reader)
+	go func() {
+		for range reader.OutputChan() {
+		}
+	}()
+
+	close(fail)
+	err := withTimeout(t, errs)
+	var be *BlockError
+	assert.ErrorAs(t, err, &be)
+	assert.Equal(t, "monitored", be.Block)
+	assert.Equal(t, 1, be.Index)
+	assert.Same(t, reader, be.Component)
+	assert.ErrorIs(t, err, readErr)
+
+	// A clean stop is not reported
+	writer.Stop()
+	select {
+	case err := <-errs:
+		t.Fatalf("Unexpected error: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	reader.Stop()
+}
+
+func TestBlockErrorsChanUnreadDoesNotLeak(t *testing.T) {
+	before := runtime.NumGoroutine()
+	block := NewBlock("unread")
+	block.ErrorsChan()
+
+	// More failures than the channel buffers, and nobody reading them
+	for i := 0; i < 5; i++ {
+		writer := NewWriter(func(int) error { return errors.New("failed") })
+		block.Add(writer)
+		writer.Send(i)
+		<-writer.Done()
+	}
+	assert.NoError(t, block.Stop())
+	// Polled by hand: assert.Eventually runs goroutines of its own.
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	assert.LessOrEqual(t, runtime.NumGoroutine(), before, "Error watchers should exit when the block stops")
+}
+
+func TestBlockRemoveWhileRunning(t *testing.T) {
+	block := NewBlock("remove")
+	w1 := NewWriter(func(int) error { return nil })
+	w2 := NewWriter(func(int) error { return nil })
+	w3 := NewWriter(func(int) error { return nil })
+	block.Add(w1)
+	block.Add(w2)
+	block.Add(w3)
+
+	assert.NoError(t, block.Remove(w2))
+	assert.Equal(t, 2, block.Count())
+	assert.False(t, w2.IsRunning())
+	assert.True(t, w1.IsRunning())
+	assert.True(t, w3.IsRunning())
+	assert.ErrorIs(t, block.Remove(w2), ErrComponentNotFound)
+
+	assert.NoError(t, block.Stop())
+	assert.False(t, w1.IsRunning())
+	assert.False(t, w3.IsRunning())
+}
+
+func TestBlockReplaceWhileRunning(t *testing.T) {
+	input := make(chan int)
+	output := make(chan int, 10)
+	block := NewBlock("replace")
+	oldMapper := NewMapper(input, output, func(v int) (int, bool, bool) { return v + 1, false, false })
+	block.Add(oldMapper)
+
+	input <- 1
+	assert.Equal(t, 2, withTimeout(t, output))
+
+	// The replacement reads the same input; deferred so Replace starts it
+	newMapper := NewMapper(input, output, func(v int) (int, bool, bool) { return v * 100, false, false },
+		WithMapperDeferredStart[int, int]())
+	assert.NoError(t, block.Replace(oldMapper, newMapper))
+	assert.False(t, oldMapper.IsRunning())
+	assert.True(t, newMapper.IsRunning())
+	assert.Equal(t, 1, block.Count())
+
+	input <- 2
+	assert.Equal(t, 200, withTimeout(t, output))
+
+	stray := NewWriter(func(int) error { return nil })
+	defer stray.Stop()
+	assert.ErrorIs(t, block.Replace(oldMapper, stray), ErrComponentNotFound)
+	assert.True(t, stray.IsRunning(), "Failed Replace must not touch the replacement")
+	assert.NoError(t, block.Stop())
+}
+
+func TestBlockGraph(t *testing.T) {
+	block := NewBlock("ingest")
+	parse := NewPipeline[string]("parse")
+	store := NewPipeline[string]("store")
+	audit := NewPipeline[int]("audit")
+	block.Add(parse)
+	block.Add(store)
+	block.Add(audit)
+	pipe, err := Connect[string](parse, store)
+	assert.NoError(t, err)
+	block.Add(pipe)
+	mapped, err := ConnectWith[string, int](store, audit, func(s string) (int, bool, bool) {
+		return len(s), false, false
+	})
+	assert.NoError(t, err)
+	block.Add(mapped)
+	defer block.Stop()
+
+	// Edges are recorded on the block holding the pipe only
+	other := NewBlock("other")
+	other.Add(parse)
+	other.Add(store)
+	assert.NotContains(t, other.Graph(), "->")
+
+	expected := `digraph "ingest" {
+  rankdir=LR;
+  c0 [label="Pipeline[string]: parse"];
+  c1 [label="Pipeline[string]: store"];
+  c2 [label="Pipeline[int]: audit"];
+  c0 -> c1 [label="pipe"];
+  c1 -> c2 [label="map"];
+}
+`
+	assert.Equal(t, expected, block.Graph())
+
+	// A stopped pipe is still drawn until it is removed
+	mapped.Stop()
+	assert.Equal(t, expected, block.Graph())
+	assert.NoError(t, block.Remove(mapped))
+	assert.NotContains(t, block.Graph(), "c1 -> c2")
+	assert.Contains(t, block.Graph(), "c0 -> c1")
+}
+
+func TestConnectNilChannel(t *testing.T) {
+	mapper := NewMapper(make(chan string), make(chan string), idMapperFunc[string])
+	defer mapper.Stop()
+	store := NewPipeline[string]("store")
+	defer store.Stop()
+
+	// Mapper's adapters return nil channels as it does not own them.
+	pipe, err := Connect[string](mapper, store)
+	assert.ErrorIs(t, err, ErrNilChannel)
+	assert.Nil(t, pipe)
+
+	_, err = ConnectWith[string, string](mapper, store, idMapperFunc[string])
+	assert.ErrorIs(t, err, ErrNilChannel)
+	assert.Contains(t, err.Error(), "output of")
+}
+
+func TestBroadcastSlowSubscriber(t *testing.T) {
+	b := NewBroadcast[int]("events", WithFanOutDropPolicy[int](DropNewest))
+	defer b.Stop()
+
+	slow := b.AddOutput(nil) // never read
+	fast1 := b.AddBufferedOutput(nil, 10)
+	fast2 := b.AddBufferedOutput(nil, 10)
+
+	for i := 0; i < 50; i++ {
+		b.Send(i)
+		assert.Equal(t, i, withTimeout(t, fast1))
+		assert.Equal(t, i, withTimeout(t, fast2))
+	}
+	assert.Positive(t, b.Stats().Outputs[slow].Dropped)
+	assert.Zero(t, b.Stats().Outputs[fast1].Dropped)
+
+	b.RemoveOutput(fast2)
+	b.Send(50)
+	assert.Equal(t, 50, withTimeout(t, fast1))
+	select {
+	case v := <-fast2:
+		t.Fatalf("Removed output received %d", v)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+// failingStopper is a component whose Stop fails.
+type failingStopper struct {
+	stopped atomic.Bool
+}
+
+func (f *failingStopper) Stop() error {
+	f.stopped.Store(true)
+	return errors.New("stop failed")
+}
+
+func (f *failingStopper) IsRunning() bool { return !f.stopped.Load() }
+
+func TestBlockStopContinuesPastErrors(t *testing.T) {
+	first := NewWriter(func(int) error { return nil })
+	middle := &failingStopper{}
+	last := NewFanIn[int]()
+	block := NewBlock("shutdown")
+	block.Add(first)
+	block.Add(middle)
+	block.Add(last)
+
+	err := block.Stop()
+	assert.ErrorContains(t, err, "stop failed")
+	assert.ErrorContains(t, err, "component 1")
+	assert.False(t, first.IsRunning(), "Components before the failing one should still stop")
+	assert.False(t, last.IsRunning())
+	assert.True(t, middle.stopped.Load())
+}
+
+func TestFunnel(t *testing.T) {
+	next := 0
+	read := func() (int, error) {
+		if next == 5 {
+			return 0, io.EOF
+		}
+		next++
+		return next, nil
+	}
+	double := func(v int) (int, bool, bool) { return v * 2, false, false }
+	written := make(chan int, 5)
+	funnel := NewFunnel(read, double, func(v int) error {
+		written <- v
+		return nil
+	})
+	defer funnel.Stop()
+
+	assert.Equal(t, 3, funnel.Count())
+	for _, want := range []int{2, 4, 6, 8, 10} {
+		assert.Equal(t, want, withTimeout(t, written))
+	}
+	select {
+	case err := <-funnel.ErrorsChan():
+		assert.ErrorIs(t, err, io.EOF)
+		assert.ErrorIs(t, err, ErrReaderClosed)
+	case <-time.After(time.Second):
+		t.Fatal("Timeout waiting for the end of input to be reported")
+	}
+	// The funnel winds down on its own once the input is exhausted.
+	err := withTimeout(t, funnel.ClosedChan())
+	assert.ErrorIs(t, err, ErrReaderClosed)
+	_, ok := <-funnel.ClosedChan()
+	assert.False(t, ok, "ClosedChan should be closed once the funnel has finished")
+	assert.False(t, funnel.IsRunning())
+	assert.NoError(t, funnel.Stop())
+}
+
+// TestFunnelWriteError verifies that a failed write is reported and that the
+// funnel still stops, even with the mapper blocked sending to the writer.
+func TestFunnelWriteError(t *testing.T) {
+	failed := errors.New("write failed")
+	var reads atomic.Int32
+	read := func() (int, error) {
+		return int(reads.Add(1)), nil
+	}
+	keep := func(v int) (int, bool, bool) { return v, false, false }
+	funnel := NewFunnel(read, keep, func(int) error { return failed })
+
+	select {
+	case err := <-funnel.ErrorsChan():
+		assert.ErrorIs(t, err, failed)
+	case <-time.After(time.Second):
+		t.Fatal("Timeout waiting for the write error to be reported")
+	}
+	// Let the mapper take another value and block sending it.
+	for deadline := time.Now().Add(time.Second); reads.Load() < 3 && time.Now().Before(deadline); {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(20 * time.Millisecond)
+	stopped := make(chan error, 1)
+	go func() { stopped <- funnel.Stop() }()
+	select {
+	case err := <-stopped:
+		assert.NoError(t, err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("Stop hung after a write error")
+	}
+	assert.False(t, funnel.IsRunning())
+	assert.ErrorIs(t, withTimeout(t, funnel.ClosedChan()), failed)
+}
@@ -0,0 +1,223 @@
+package gocurrent
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// Buffer is an elastic queue between a producer and a consumer. It reads
+// from input as fast as values arrive, holds them in an internal growable
+// queue and drains them to its output in order as the consumer reads, so a
+// bursty or slow consumer rarely blocks the producer.
+//
+// The buffer is unbounded by default. With WithCapacity(n) it stops reading
+// input while n values are queued, applying back-pressure to the producer,
+// unless WithOverflow is given a callback to spill the excess to.
+//
+// When the input is closed the remaining queued values are still delivered
+// before the output is closed; with WithOverflow the buffer also waits for
+// CloseReinject, so spilled values can still be fed back. Stop closes the
+// output immediately and discards anything still queued.
+type Buffer[T any] struct {
+	RunnerBase[string]
+	input      <-chan T
+	output     chan T
+	capacity   int
+	queue      []T
+	length     atomic.Int64
+	closedChan chan error
+
+	// Overflow handling (see WithOverflow, Reinject and CloseReinject)
+	overflow          func(T) error
+	reinject          chan T
+	reinjectDone      chan struct{}
+	closeReinjectOnce sync.Once
+}
+
+// BufferOption is a functional option for configuring a Buffer.
+type BufferOption[T any] func(*Buffer[T])
+
+// WithCapacity bounds the number of queued values. A capacity <= 0 means
+// unbounded.
+func WithCapacity[T any](n int) BufferOption[T] {
+	return func(b *Buffer[T]) {
+		b.capacity = n
+	}
+}
+
+// WithOverflow makes a buffer with a capacity hand values that arrive while
+// it is full to fn instead of applying back-pressure, e.g. to spill them to
+// disk. Spilled values can be fed back later with Reinject. If fn returns an
+// error the buffer stops and reports it on ClosedChan().
+//
+// A buffer with an overflow callback keeps running after its input is
+// closed until CloseReinject is called, so call it once every spilled value
+// has been re-injected (or given up on) for the output to be closed.
+func WithOverflow[T any](fn func(T) error) BufferOption[T] {
+	return func(b *Buffer[T]) {
+		b.overflow = fn
+	}
+}
+
+// WithBufferContext ties the buffer's lifetime to ctx. When ctx is done the
+// buffer stops itself and ClosedChan() receives ctx.Err().
+func WithBufferContext[T any](ctx context.Context) BufferOption[T] {
+	return func(b *Buffer[T]) {
+		b.ctx = ctx
+	}
+}
+
+// NewBuffer creates a buffer reading from input.
+//
+// Example:
+//
+//	buf := NewBuffer(events, WithCapacity[Event](10000))
+//	defer buf.Stop()
+//	for e := range buf.OutputChan() {
+//	    slowProcess(e)
+//	}
+func NewBuffer[T any](input <-chan T, opts ...BufferOption[T]) *Buffer[T] {
+	out := &Buffer[T]{
+		RunnerBase:   NewRunnerBase("stop"),
+		input:        input,
+		output:       make(chan T),
+		closedChan:   make(chan error, 1),
+		reinject:     make(chan T),
+		reinjectDone: make(chan struct{}),
+	}
+	out.component = "Buffer"
+	for _, opt := range opts {
+		opt(out)
+	}
+	out.start()
+	return out
+}
+
+// OutputChan returns the channel on which buffered values are delivered.
+func (b *Buffer[T]) OutputChan() <-chan T {
+	return b.output
+}
+
+// Len returns the number of values currently queued.
+func (b *Buffer[T]) Len() int {
+	return int(b.length.Load())
+}
+
+// Reinject queues a value again, typically one previously handed to the
+// WithOverflow callback. It waits while the buffer is full and returns false
+// if the buffer has stopped or CloseReinject has been called.
+func (b *Buffer[T]) Reinject(value T) bool {
+	select {
+	case <-b.reinjectDone:
+		return false
+	default:
+	}
+	select {
+	case b.reinject <- value:
+		return true
+	case <-b.reinjectDone:
+		return false
+	case <-b.Done():
+		return false
+	}
+}
+
+// CloseReinject signals that no more values will be re-injected. Once the
+// input is closed as well, the buffer delivers what is queued and closes its
+// output. It is only needed with WithOverflow, and is safe to call more than
+// once.
+func (b *Buffer[T]) CloseReinject() {
+	b.closeReinjectOnce.Do(func() { close(b.reinjectDone) })
+}
+
+// ClosedChan returns the channel used to signal when the buffer is done.
+func (b *Buffer[T]) ClosedChan() <-chan error {
+	return b.closedChan
+}
+
+// DebugInfo returns diagnostic information including the queue length.
+func (b *Buffer[T]) DebugInfo() any {
+	return map[string]any{
+		"base":     b.RunnerBase.DebugInfo(),
+		"len":      b.Len(),
+		"capacity": b.capacity,
+	}
+}
+
+func (b *Buffer[T]) cleanup() {
+	close(b.output)
+	close(b.closedChan)
+	b.RunnerBase.cleanup()
+}
+
+func (b *Buffer[T]) push(value T) {
+	b.queue = append(b.queue, value)
+	b.length.Store(int64(len(b.queue)))
+}
+
+func (b *Buffer[T]) pop() {
+	var zero T
+	b.queue[0] = zero
+	b.queue = b.queue[1:]
+	b.length.Store(int64(len(b.queue)))
+}
+
+func (b *Buffer[T]) start() {
+	b.RunnerBase.start()
+	go func() {
+		defer b.cleanup()
+		input := b.input
+		// Without an overflow callback nothing is ever spilled, so there is
+		// nothing to wait for.
+		reinjectDone := b.reinjectDone
+		if b.overflow == nil {
+			reinjectDone = nil
+		}
+		for input != nil || len(b.queue) > 0 || reinjectDone != nil {
+			in, reinject := input, b.reinject
+			full := b.capacity > 0 && len(b.queue) >= b.capacity
+			if full {
+				reinject = nil
+				if b.overflow == nil {
+					in = nil
+				}
+			}
+			var out chan<- T
+			var head T
+			if len(b.queue) > 0 {
+				out = b.output
+				head = b.queue[0]
+			}
+			select {
+			case <-b.controlChan:
+				return
+			case <-b.ctxDone():
+				b.setErr(b.contextErr())
+				b.closedChan <- b.contextErr()
+				return
+			case value, ok := <-in:
+				if !ok {
+					input = nil
+					continue
+				}
+				if full {
+					if err := b.overflow(value); err != nil {
+						err = b.wrapErr(err)
+						b.setErr(err)
+						b.closedChan <- err
+						return
+					}
+					continue
+				}
+				b.push(value)
+			case value := <-reinject:
+				b.push(value)
+			case <-reinjectDone:
+				reinjectDone = nil
+			case out <- head:
+				b.pop()
+			}
+		}
+	}()
+}
@@ -0,0 +1,146 @@
+package gocurrent
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBufferPreservesOrder(t *testing.T) {
+	input := make(chan int)
+	buf := NewBuffer(input)
+
+	// The producer never blocks on an unread, unbounded buffer
+	for i := 0; i < 1000; i++ {
+		input <- i
+	}
+	assert.Eventually(t, func() bool { return buf.Len() == 1000 }, time.Second, time.Millisecond)
+	close(input)
+
+	var got []int
+	for v := range buf.OutputChan() {
+		got = append(got, v)
+	}
+	assert.Len(t, got, 1000)
+	for i, v := range got {
+		assert.Equal(t, i, v)
+	}
+	assert.Equal(t, 0, buf.Len())
+}
+
+func TestBufferBackpressureAtCapacity(t *testing.T) {
+	input := make(chan int)
+	buf := NewBuffer(input, WithCapacity[int](3))
+	defer buf.Stop()
+
+	for i := 0; i < 3; i++ {
+		input <- i
+	}
+	select {
+	case input <- 3:
+		t.Fatal("Send should block while the buffer is at capacity")
+	case <-time.After(50 * time.Millisecond):
+	}
+	assert.Equal(t, 3, buf.Len())
+
+	// Reading one value frees a slot for the producer
+	assert.Equal(t, 0, withTimeout(t, buf.OutputChan()))
+	select {
+	case input <- 3:
+	case <-time.After(testTimeout):
+		t.Fatal("Send should succeed once there is room")
+	}
+	for i := 1; i <= 3; i++ {
+		assert.Equal(t, i, withTimeout(t, buf.OutputChan()))
+	}
+}
+
+func TestBufferStopClosesOutput(t *testing.T) {
+	input := make(chan int)
+	buf := NewBuffer(input)
+	input <- 1
+	buf.Stop()
+	_, ok := <-buf.OutputChan()
+	assert.False(t, ok, "Output should be closed and queued values discarded on Stop")
+}
+
+func TestBufferOverflowAndReinject(t *testing.T) {
+	input := make(chan int)
+	spilled := make(chan int, 10)
+	buf := NewBuffer(input, WithCapacity[int](2), WithOverflow(func(v int) error {
+		spilled <- v
+		return nil
+	}))
+	defer buf.Stop()
+
+	// Nothing is read, so everything past the capacity is spilled and the
+	// producer never blocks.
+	for i := 1; i <= 5; i++ {
+		select {
+		case input <- i:
+		case <-time.After(time.Second):
+			t.Fatalf("Send %d blocked despite the overflow hook", i)
+		}
+	}
+	for _, want := range []int{3, 4, 5} {
+		assert.Equal(t, want, withTimeout(t, spilled))
+	}
+	assert.Equal(t, 2, buf.Len())
+
+	assert.Equal(t, 1, withTimeout(t, buf.OutputChan()))
+	assert.Equal(t, 2, withTimeout(t, buf.OutputChan()))
+	go func() {
+		for _, v := range []int{3, 4, 5} {
+			buf.Reinject(v)
+		}
+	}()
+	for _, want := range []int{3, 4, 5} {
+		assert.Equal(t, want, withTimeout(t, buf.OutputChan()))
+	}
+}
+
+// TestBufferReinjectAfterInputClosed verifies that a buffer with an
+// overflow callback stays open after its input is closed, so spilled values
+// can still be re-injected, and closes its output after CloseReinject.
+func TestBufferReinjectAfterInputClosed(t *testing.T) {
+	input := make(chan int)
+	var spilled []int
+	buf := NewBuffer(input, WithCapacity[int](1), WithOverflow(func(v int) error {
+		spilled = append(spilled, v)
+		return nil
+	}))
+	defer buf.Stop()
+
+	input <- 1
+	input <- 2
+	input <- 3
+	close(input)
+
+	assert.Equal(t, 1, withTimeout(t, buf.OutputChan()))
+	// The input is closed and the queue drained, yet the buffer still
+	// accepts the spilled values.
+	go func() {
+		for _, v := range spilled {
+			assert.True(t, buf.Reinject(v))
+		}
+		buf.CloseReinject()
+	}()
+	var got []int
+	for v := range buf.OutputChan() {
+		got = append(got, v)
+	}
+	assert.Equal(t, []int{2, 3}, got)
+	assert.False(t, buf.Reinject(4), "Reinject after CloseReinject should fail")
+}
+
+func TestBufferOverflowError(t *testing.T) {
+	input := make(chan int)
+	diskFull := errors.New("disk full")
+	buf := NewBuffer(input, WithCapacity[int](1), WithOverflow(func(int) error { return diskFull }))
+	input <- 1
+	input <- 2
+	assert.ErrorIs(t, withTimeout(t, buf.ClosedChan()), diskFull)
+	assert.False(t, buf.Reinject(3))
+}
@@ -0,0 +1,230 @@
+package gocurrent
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock is the source of time for the time-dependent primitives (Reducer
+// flushes, Debouncer, Throttler and Map expiry). The default, RealClock, is
+// the wall clock; tests can substitute a FakeClock to control time exactly.
+type Clock interface {
+	Now() time.Time
+	NewTicker(d time.Duration) Ticker
+	NewTimer(d time.Duration) Timer
+	After(d time.Duration) <-chan time.Time
+}
+
+// Ticker is the Clock counterpart of time.Ticker.
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+	Reset(d time.Duration)
+}
+
+// Timer is the Clock counterpart of time.Timer. As with time.Timer since Go
+// 1.23, no stale value is received after Stop or Reset returns.
+type Timer interface {
+	C() <-chan time.Time
+	Stop() bool
+	Reset(d time.Duration) bool
+}
+
+// RealClock is the Clock backed by the time package.
+type RealClock struct{}
+
+// Now returns time.Now().
+func (RealClock) Now() time.Time { return time.Now() }
+
+// NewTicker returns a ticker backed by time.NewTicker.
+func (RealClock) NewTicker(d time.Duration) Ticker { return realTicker{time.NewTicker(d)} }
+
+// NewTimer returns a timer backed by time.NewTimer.
+func (RealClock) NewTimer(d time.Duration) Timer { return realTimer{time.NewTimer(d)} }
+
+// After returns time.After(d).
+func (RealClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+type realTicker struct{ *time.Ticker }
+
+func (t realTicker) C() <-chan time.Time { return t.Ticker.C }
+
+type realTimer struct{ *time.Timer }
+
+func (t realTimer) C() <-chan time.Time { return t.Timer.C }
+
+// clockOr returns c, or RealClock if c is nil.
+func clockOr(c Clock) Clock {
+	if c == nil {
+		return RealClock{}
+	}
+	return c
+}
+
+// FakeClock is a Clock for tests whose time only moves when Advance is
+// called. Timers and tickers fire synchronously within Advance, in deadline
+// order, each seeing Now() equal to its deadline. Like their time package
+// counterparts, their channels hold a single value, and ticks that find it
+// full are dropped.
+//
+// Primitives create their timers on their own goroutine, so a test should
+// call BlockUntil before advancing past a deadline the primitive is expected
+// to have armed.
+type FakeClock struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	now     time.Time
+	waiters []*fakeWaiter
+}
+
+// fakeWaiter is a timer or ticker of a FakeClock.
+type fakeWaiter struct {
+	clock  *FakeClock
+	c      chan time.Time
+	when   time.Time
+	period time.Duration // zero for timers
+}
+
+// NewFakeClock creates a FakeClock set to start.
+func NewFakeClock(start time.Time) *FakeClock {
+	c := &FakeClock{now: start}
+	c.cond = sync.NewCond(&c.mu)
+	return c
+}
+
+// Now returns the fake current time.
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// NewTicker returns a ticker that fires every d of fake time. It panics if
+// d is not positive, like time.NewTicker.
+func (c *FakeClock) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("non-positive interval for FakeClock.NewTicker")
+	}
+	return fakeTicker{c.add(d, d)}
+}
+
+// NewTimer returns a timer that fires once d of fake time has passed.
+func (c *FakeClock) NewTimer(d time.Duration) Timer {
+	return fakeTimer{c.add(d, 0)}
+}
+
+// After returns the channel of a new timer for d.
+func (c *FakeClock) After(d time.Duration) <-chan time.Time {
+	return c.NewTimer(d).C()
+}
+
+// Advance moves the fake time forward by d, firing every timer and ticker
+// that falls due on the way.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	target := c.now.Add(d)
+	for {
+		var next *fakeWaiter
+		for _, w := range c.waiters {
+			if !w.when.After(target) && (next == nil || w.when.Before(next.when)) {
+				next = w
+			}
+		}
+		if next == nil {
+			break
+		}
+		c.now = next.when
+		select {
+		case next.c <- next.when:
+		default:
+		}
+		if next.period > 0 {
+			next.when = next.when.Add(next.period)
+		} else {
+			c.remove(next)
+		}
+	}
+	c.now = target
+}
+
+// BlockUntil waits until at least n timers and tickers are active, i.e.
+// created or reset and not yet fired (timers) or stopped.
+func (c *FakeClock) BlockUntil(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for len(c.waiters) < n {
+		c.cond.Wait()
+	}
+}
+
+func (c *FakeClock) add(d, period time.Duration) *fakeWaiter {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	w := &fakeWaiter{clock: c, c: make(chan time.Time, 1), when: c.now.Add(d), period: period}
+	c.activate(w)
+	return w
+}
+
+// activate and remove must be called with mu held.
+func (c *FakeClock) activate(w *fakeWaiter) {
+	c.waiters = append(c.waiters, w)
+	c.cond.Broadcast()
+}
+
+func (c *FakeClock) remove(w *fakeWaiter) bool {
+	for i, other := range c.waiters {
+		if other == w {
+			c.waiters = append(c.waiters[:i], c.waiters[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// stop deactivates w and discards a pending value, reporting whether w was
+// active.
+func (w *fakeWaiter) stop() bool {
+	w.clock.mu.Lock()
+	defer w.clock.mu.Unlock()
+	select {
+	case <-w.c:
+	default:
+	}
+	return w.clock.remove(w)
+}
+
+// reset rearms w to fire d from now, then every period if that is positive,
+// reporting whether it was active.
+func (w *fakeWaiter) reset(d, period time.Duration) bool {
+	c := w.clock
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	select {
+	case <-w.c:
+	default:
+	}
+	active := c.remove(w)
+	w.when = c.now.Add(d)
+	w.period = period
+	c.activate(w)
+	return active
+}
+
+type fakeTimer struct{ w *fakeWaiter }
+
+func (t fakeTimer) C() <-chan time.Time        { return t.w.c }
+func (t fakeTimer) Stop() bool                 { return t.w.stop() }
+func (t fakeTimer) Reset(d time.Duration) bool { return t.w.reset(d, 0) }
+
+type fakeTicker struct{ w *fakeWaiter }
+
+func (t fakeTicker) C() <-chan time.Time { return t.w.c }
+func (t fakeTicker) Stop()               { t.w.stop() }
+
+func (t fakeTicker) Reset(d time.Duration) {
+	if d <= 0 {
+		panic("non-positive interval for Ticker.Reset")
+	}
+	t.w.reset(d, d)
+}
@@ -0,0 +1,50 @@
+package gocurrent
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFakeClock(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+
+	timer := clock.NewTimer(5 * time.Second)
+	ticker := clock.NewTicker(2 * time.Second)
+	clock.BlockUntil(2)
+
+	clock.Advance(3 * time.Second)
+	assert.Equal(t, start.Add(3*time.Second), clock.Now())
+	assert.Equal(t, start.Add(2*time.Second), <-ticker.C())
+	assert.Len(t, timer.C(), 0)
+
+	// The ticker's channel was drained, so its 4s tick is kept; the timer
+	// fires at 5s.
+	clock.Advance(2 * time.Second)
+	assert.Equal(t, start.Add(5*time.Second), <-timer.C())
+	assert.Equal(t, start.Add(4*time.Second), <-ticker.C())
+	assert.False(t, timer.Stop(), "Stop after firing reports false")
+
+	// Reset rearms relative to the current fake time.
+	assert.False(t, timer.Reset(time.Second))
+	ticker.Stop()
+	clock.Advance(10 * time.Second)
+	assert.Equal(t, start.Add(6*time.Second), <-timer.C())
+	assert.Len(t, ticker.C(), 0)
+
+	after := clock.After(time.Second)
+	assert.True(t, clock.NewTimer(time.Hour).Stop())
+	clock.Advance(time.Second)
+	assert.Equal(t, start.Add(16*time.Second), <-after)
+}
+
+func TestFakeClockDropsTicks(t *testing.T) {
+	clock := NewFakeClock(time.Time{})
+	ticker := clock.NewTicker(time.Second)
+	clock.Advance(5 * time.Second)
+	// Like time.Ticker, the channel holds one tick; later ones are dropped.
+	assert.Equal(t, time.Time{}.Add(time.Second), <-ticker.C())
+	assert.Len(t, ticker.C(), 0)
+}
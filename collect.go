@@ -0,0 +1,112 @@
+package gocurrent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// ErrCollectTimeout is returned by Collect when the timeout elapses before
+// n values have been read.
+var ErrCollectTimeout = errors.New("timed out collecting values")
+
+// ErrAllClosed is returned by FirstOf when every channel is closed before
+// any of them produces a value.
+var ErrAllClosed = errors.New("all channels closed")
+
+// Collect reads values from ch until it has n of them, ch is closed, or
+// timeout elapses, and returns the values read so far. Only the timeout is
+// reported as an error (wrapping ErrCollectTimeout); a channel that closes
+// early simply yields fewer values. n <= 0 reads until ch is closed and
+// timeout <= 0 waits indefinitely.
+func Collect[T any](ch <-chan T, n int, timeout time.Duration) ([]T, error) {
+	var out []T
+	if n > 0 {
+		out = make([]T, 0, n)
+	}
+	var deadline <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+	for n <= 0 || len(out) < n {
+		select {
+		case v, ok := <-ch:
+			if !ok {
+				return out, nil
+			}
+			out = append(out, v)
+		case <-deadline:
+			return out, fmt.Errorf("%w: got %d values after %v", ErrCollectTimeout, len(out), timeout)
+		}
+	}
+	return out, nil
+}
+
+// ForEach calls fn for every value received from ch until ch is closed,
+// returning nil. It stops early and returns the error if fn fails, or
+// ctx.Err() once ctx is done. Values still in ch after an early return are
+// left there, not drained.
+func ForEach[T any](ctx context.Context, ch <-chan T, fn func(T) error) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case v, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			if err := fn(v); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// FirstOf waits for the first value from any of chans and returns it along
+// with the index of the channel it came from. Closed channels are ignored;
+// if all of them are closed (or none are given) it returns ErrAllClosed, and
+// if ctx is done first it returns ctx.Err(). Only one value is consumed.
+func FirstOf[T any](ctx context.Context, chans ...<-chan T) (T, int, error) {
+	var zero T
+	cases := make([]reflect.SelectCase, len(chans)+1)
+	cases[0] = reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ctx.Done())}
+	for i, ch := range chans {
+		cases[i+1] = reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ch)}
+	}
+	for open := len(chans); open > 0; {
+		chosen, v, ok := reflect.Select(cases)
+		if chosen == 0 {
+			return zero, -1, ctx.Err()
+		}
+		if ok {
+			return v.Interface().(T), chosen - 1, nil
+		}
+		// A closed channel: stop selecting on it.
+		cases[chosen].Chan = reflect.Value{}
+		open--
+	}
+	return zero, -1, ErrAllClosed
+}
+
+// DrainAndClose discards the values buffered in ch, also releasing senders
+// currently blocked on it, and then closes ch. It returns the number of
+// values discarded. Closing a channel with blocked senders would make them
+// panic, so owners should prefer this to a bare close. Only the channel's
+// owner may call it, and a sender arriving after it returns still panics, so
+// late senders must guard their sends (e.g. by selecting on a done channel).
+func DrainAndClose[T any](ch chan T) int {
+	n := 0
+	for {
+		select {
+		case <-ch:
+			n++
+		default:
+			close(ch)
+			return n
+		}
+	}
+}
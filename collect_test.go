@@ -0,0 +1,158 @@
+package gocurrent
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCollectFullCount(t *testing.T) {
+	ch := make(chan int, 10)
+	for i := 0; i < 10; i++ {
+		ch <- i
+	}
+	vals, err := Collect(ch, 3, time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{0, 1, 2}, vals)
+	assert.Len(t, ch, 7, "Collect should not read past n")
+}
+
+func TestCollectClosedEarly(t *testing.T) {
+	ch := make(chan int, 2)
+	ch <- 1
+	ch <- 2
+	close(ch)
+	vals, err := Collect(ch, 5, time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, vals)
+}
+
+func TestCollectTimeout(t *testing.T) {
+	ch := make(chan int, 1)
+	ch <- 1
+	start := time.Now()
+	vals, err := Collect(ch, 5, 20*time.Millisecond)
+	assert.ErrorIs(t, err, ErrCollectTimeout)
+	assert.Equal(t, []int{1}, vals)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestForEachCompletes(t *testing.T) {
+	ch := make(chan int, 3)
+	ch <- 1
+	ch <- 2
+	ch <- 3
+	close(ch)
+	sum := 0
+	err := ForEach(context.Background(), ch, func(v int) error {
+		sum += v
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 6, sum)
+}
+
+func TestForEachStopsOnError(t *testing.T) {
+	ch := make(chan int, 5)
+	for i := 0; i < 5; i++ {
+		ch <- i
+	}
+	boom := errors.New("boom")
+	var seen []int
+	err := ForEach(context.Background(), ch, func(v int) error {
+		seen = append(seen, v)
+		if v == 1 {
+			return boom
+		}
+		return nil
+	})
+	assert.ErrorIs(t, err, boom)
+	assert.Equal(t, []int{0, 1}, seen)
+	assert.Len(t, ch, 3, "Remaining values should not be drained")
+}
+
+func TestForEachContextCancel(t *testing.T) {
+	ch := make(chan int) // never closed
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- ForEach(ctx, ch, func(int) error { return nil })
+	}()
+	ch <- 1
+	cancel()
+	select {
+	case err := <-done:
+		assert.ErrorIs(t, err, context.Canceled)
+	case <-time.After(time.Second):
+		t.Fatal("ForEach did not return after cancel")
+	}
+}
+
+func TestFirstOfFirstWins(t *testing.T) {
+	a, b, c := make(chan string), make(chan string, 1), make(chan string)
+	b <- "b"
+	v, idx, err := FirstOf(context.Background(), a, b, c)
+	assert.NoError(t, err)
+	assert.Equal(t, "b", v)
+	assert.Equal(t, 1, idx)
+}
+
+func TestFirstOfSkipsClosed(t *testing.T) {
+	closed, slow := make(chan int), make(chan int)
+	close(closed)
+	go func() {
+		time.Sleep(5 * time.Millisecond)
+		slow <- 7
+	}()
+	v, idx, err := FirstOf(context.Background(), closed, slow)
+	assert.NoError(t, err)
+	assert.Equal(t, 7, v)
+	assert.Equal(t, 1, idx)
+}
+
+func TestFirstOfContextCancel(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, idx, err := FirstOf(ctx, make(chan int), make(chan int))
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, -1, idx)
+}
+
+func TestFirstOfAllClosed(t *testing.T) {
+	a, b := make(chan int), make(chan int)
+	close(a)
+	close(b)
+	_, _, err := FirstOf(context.Background(), a, b)
+	assert.ErrorIs(t, err, ErrAllClosed)
+
+	_, _, err = FirstOf[int](context.Background())
+	assert.ErrorIs(t, err, ErrAllClosed)
+}
+
+func TestDrainAndCloseReleasesPendingSender(t *testing.T) {
+	ch := make(chan int, 2)
+	ch <- 1
+	ch <- 2
+	sent := make(chan struct{})
+	go func() {
+		ch <- 3 // blocks: the buffer is full
+		close(sent)
+	}()
+	// Give the sender time to block on the full channel.
+	time.Sleep(20 * time.Millisecond)
+
+	assert.Equal(t, 3, DrainAndClose(ch))
+	withTimeout(t, sent)
+	_, ok := <-ch
+	assert.False(t, ok, "channel should be closed")
+}
+
+func TestDrainAndCloseEmpty(t *testing.T) {
+	ch := make(chan int)
+	assert.Equal(t, 0, DrainAndClose(ch))
+	_, ok := <-ch
+	assert.False(t, ok)
+}
@@ -13,3 +13,23 @@ type Message[T any] struct {
 	Error  error // Any error that occurred during processing
 	Source any   // Optional source information for debugging
 }
+
+// Ok returns a Message carrying v and no error.
+func Ok[T any](v T) Message[T] {
+	return Message[T]{Value: v}
+}
+
+// Err returns a Message carrying err and the zero value.
+func Err[T any](err error) Message[T] {
+	return Message[T]{Error: err}
+}
+
+// IsError reports whether the message carries an error.
+func (m Message[T]) IsError() bool {
+	return m.Error != nil
+}
+
+// Unwrap returns the message's value and error.
+func (m Message[T]) Unwrap() (T, error) {
+	return m.Value, m.Error
+}
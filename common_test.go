@@ -0,0 +1,32 @@
+package gocurrent
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMessageOk(t *testing.T) {
+	m := Ok(42)
+	assert.False(t, m.IsError())
+	v, err := m.Unwrap()
+	assert.Equal(t, 42, v)
+	assert.NoError(t, err)
+}
+
+func TestMessageErr(t *testing.T) {
+	boom := errors.New("boom")
+	m := Err[string](boom)
+	assert.True(t, m.IsError())
+	v, err := m.Unwrap()
+	assert.Equal(t, "", v)
+	assert.ErrorIs(t, err, boom)
+}
+
+func TestMessageUnwrapKeepsBoth(t *testing.T) {
+	boom := errors.New("partial")
+	v, err := Message[int]{Value: 7, Error: boom}.Unwrap()
+	assert.Equal(t, 7, v)
+	assert.ErrorIs(t, err, boom)
+}
@@ -25,8 +25,13 @@ func (m *Mapper[I, O]) OutputChan() <-chan O {
 
 // IsRunning returns true if the reducer is still running
 func (r *Reducer[T, C, U]) IsRunning() bool {
-	// Reducer doesn't have an isRunning flag, check if channels are not nil
-	return r.inputChan != nil
+	// Reducer doesn't use RunnerBase; its goroutine closes done on exit
+	select {
+	case <-r.done:
+		return false
+	default:
+		return true
+	}
 }
 
 // Pipe adapters (Pipe is just a Mapper, so inherits its adapters)
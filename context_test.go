@@ -0,0 +1,171 @@
+package gocurrent
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+)
+
+// waitClosed waits for a ClosedChan to deliver, failing the test on timeout.
+func waitClosed(t *testing.T, name string, ch <-chan error) error {
+	t.Helper()
+	select {
+	case err := <-ch:
+		return err
+	case <-time.After(time.Second):
+		t.Fatalf("Timeout waiting for %s to close", name)
+		return nil
+	}
+}
+
+// TestContextCancelStopsPrimitives verifies that every RunnerBase primitive
+// stops itself when its context is cancelled and reports ctx.Err().
+func TestContextCancelStopsPrimitives(t *testing.T) {
+	t.Run("Reader", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		reader := NewReader(func() (int, error) {
+			time.Sleep(time.Millisecond)
+			return 1, nil
+		}, WithReaderContext[int](ctx), WithOutputBuffer[int](100))
+		cancel()
+		if err := waitClosed(t, "Reader", reader.ClosedChan()); !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected context.Canceled, got: %v", err)
+		}
+		<-reader.Done()
+		if reader.IsRunning() {
+			t.Error("Reader should not be running after context cancel")
+		}
+	})
+
+	t.Run("Writer", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		writer := NewWriter(func(int) error { return nil }, WithWriterContext[int](ctx))
+		cancel()
+		if err := waitClosed(t, "Writer", writer.ClosedChan()); !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected context.Canceled, got: %v", err)
+		}
+		if writer.Send(1) {
+			t.Error("Send should fail after context cancel")
+		}
+	})
+
+	t.Run("Mapper", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		mapper := NewMapper(make(chan int), make(chan int), idMapperFunc[int],
+			WithMapperContext[int, int](ctx))
+		cancel()
+		if err := waitClosed(t, "Mapper", mapper.ClosedChan()); !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected context.Canceled, got: %v", err)
+		}
+	})
+
+	t.Run("FanIn", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		fanin := NewFanIn(WithFanInContext[int](ctx))
+		fanin.Add(make(chan int))
+		if err := waitClosed(t, "FanIn", fanin.ClosedChan()); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected context.DeadlineExceeded, got: %v", err)
+		}
+		// Owned output channel is closed as part of shutdown.
+		if _, ok := <-fanin.OutputChan(); ok {
+			t.Error("FanIn output should be closed")
+		}
+	})
+
+	fanouts := map[string]func(ctx context.Context) FanOuter[int]{
+		"SyncFanOut":   func(ctx context.Context) FanOuter[int] { return NewSyncFanOut(WithFanOutContext[int](ctx)) },
+		"AsyncFanOut":  func(ctx context.Context) FanOuter[int] { return NewAsyncFanOut(WithFanOutContext[int](ctx)) },
+		"QueuedFanOut": func(ctx context.Context) FanOuter[int] { return NewQueuedFanOut[int](WithFanOutContext[int](ctx)) },
+	}
+	for name, makeFanOut := range fanouts {
+		t.Run(name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			fo := makeFanOut(ctx)
+			out := fo.New(nil)
+			cancel()
+			if err := waitClosed(t, name, fo.ClosedChan()); !errors.Is(err, context.Canceled) {
+				t.Errorf("Expected context.Canceled, got: %v", err)
+			}
+			if _, ok := <-out; ok {
+				t.Error("Owned output should be closed")
+			}
+		})
+	}
+}
+
+// TestContextCancelConcurrentStop races context cancellation against Stop()
+// to make sure neither path panics or double-closes anything.
+// Run with: go test -race -run TestContextCancelConcurrentStop
+func TestContextCancelConcurrentStop(t *testing.T) {
+	for i := 0; i < 200; i++ {
+		ctx, cancel := context.WithCancel(context.Background())
+		writer := NewWriter(func(int) error { return nil }, WithWriterContext[int](ctx))
+		fanin := NewFanIn(WithFanInContext[int](ctx))
+
+		var wg sync.WaitGroup
+		wg.Add(3)
+		go func() { defer wg.Done(); cancel() }()
+		go func() { defer wg.Done(); writer.Stop() }()
+		go func() { defer wg.Done(); fanin.Stop() }()
+		wg.Wait()
+
+		writer.Stop()
+		fanin.Stop()
+	}
+}
+
+// TestComponentErrors verifies that completion errors identify the component
+// and the reason, while still matching the underlying error.
+func TestComponentErrors(t *testing.T) {
+	t.Run("ContextCanceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		fo := NewQueuedFanOut[int](WithFanOutContext[int](ctx))
+		cancel()
+		err := waitClosed(t, "QueuedFanOut", fo.ClosedChan())
+		var ce *ComponentError
+		if !errors.As(err, &ce) || ce.Component != "QueuedFanOut" {
+			t.Fatalf("Expected a QueuedFanOut ComponentError, got: %#v", err)
+		}
+		if !errors.Is(err, ErrContextCanceled) || !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected ErrContextCanceled and context.Canceled, got: %v", err)
+		}
+	})
+
+	t.Run("ReaderClosed", func(t *testing.T) {
+		reader := NewReader(func() (int, error) { return 0, io.EOF },
+			WithOnError[int](func(error) bool { return false }))
+		<-reader.OutputChan()
+		err := waitClosed(t, "Reader", reader.ClosedChan())
+		var ce *ComponentError
+		if !errors.As(err, &ce) || ce.Component != "Reader" {
+			t.Fatalf("Expected a Reader ComponentError, got: %#v", err)
+		}
+		if !errors.Is(err, ErrReaderClosed) || !errors.Is(err, io.EOF) {
+			t.Errorf("Expected ErrReaderClosed and io.EOF, got: %v", err)
+		}
+		if errors.Is(err, ErrContextCanceled) {
+			t.Errorf("EOF should not match ErrContextCanceled")
+		}
+	})
+
+	t.Run("Fatal", func(t *testing.T) {
+		fatal := errors.New("fatal")
+		writer := NewWriter(func(int) error { return fatal })
+		writer.Send(1)
+		err := waitClosed(t, "Writer", writer.ClosedChan())
+		var ce *ComponentError
+		if !errors.As(err, &ce) || ce.Component != "Writer" || ce.Cause != fatal {
+			t.Fatalf("Expected a Writer ComponentError wrapping fatal, got: %#v", err)
+		}
+		if err.Error() != "fatal" {
+			t.Errorf("Wrapping should keep the message, got %q", err.Error())
+		}
+		if errors.Is(err, ErrReaderClosed) || errors.Is(err, ErrContextCanceled) {
+			t.Errorf("Fatal error should not match a completion reason: %v", err)
+		}
+	})
+}
@@ -0,0 +1,122 @@
+package gocurrent
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrUnsupportedCommand is returned by a ControlCommand whose target
+// component does not support it.
+var ErrUnsupportedCommand = errors.New("command not supported by component")
+
+// Optional capabilities a component may implement to be driven by the
+// corresponding ControlCommand.
+
+// Flusher is implemented by components that can flush buffered state on
+// demand (e.g. Reducer).
+type Flusher interface {
+	Flush()
+}
+
+// Pauser is implemented by components that can suspend and resume
+// processing without stopping (e.g. RateLimitedPipe).
+type Pauser interface {
+	Pause()
+	Resume()
+}
+
+// RateSetter is implemented by components whose throughput can be adjusted
+// at runtime (e.g. RateLimitedPipe).
+type RateSetter interface {
+	SetRate(perSecond float64)
+}
+
+// ControlCommand is a typed command that can be applied to a Component. The
+// command set is open: any type implementing Apply can be sent to
+// DriveFromCommands. Apply should return ErrUnsupportedCommand (optionally
+// wrapped) when the component lacks the required capability.
+type ControlCommand interface {
+	Apply(c Component) error
+}
+
+// StopCommand stops the component. DriveFromCommands returns after applying it.
+type StopCommand struct{}
+
+// Apply implements ControlCommand.
+func (StopCommand) Apply(c Component) error { return c.Stop() }
+
+// FlushCommand flushes a [Flusher].
+type FlushCommand struct{}
+
+// Apply implements ControlCommand.
+func (FlushCommand) Apply(c Component) error {
+	f, ok := c.(Flusher)
+	if !ok {
+		return fmt.Errorf("flush: %w", ErrUnsupportedCommand)
+	}
+	f.Flush()
+	return nil
+}
+
+// PauseCommand pauses a [Pauser].
+type PauseCommand struct{}
+
+// Apply implements ControlCommand.
+func (PauseCommand) Apply(c Component) error {
+	p, ok := c.(Pauser)
+	if !ok {
+		return fmt.Errorf("pause: %w", ErrUnsupportedCommand)
+	}
+	p.Pause()
+	return nil
+}
+
+// ResumeCommand resumes a [Pauser].
+type ResumeCommand struct{}
+
+// Apply implements ControlCommand.
+func (ResumeCommand) Apply(c Component) error {
+	p, ok := c.(Pauser)
+	if !ok {
+		return fmt.Errorf("resume: %w", ErrUnsupportedCommand)
+	}
+	p.Resume()
+	return nil
+}
+
+// SetRateCommand sets the rate of a [RateSetter].
+type SetRateCommand struct {
+	PerSecond float64
+}
+
+// Apply implements ControlCommand.
+func (cmd SetRateCommand) Apply(c Component) error {
+	r, ok := c.(RateSetter)
+	if !ok {
+		return fmt.Errorf("set rate: %w", ErrUnsupportedCommand)
+	}
+	r.SetRate(cmd.PerSecond)
+	return nil
+}
+
+// DriveFromCommands applies each command received on cmds to c, in order.
+// It blocks until cmds is closed or a StopCommand has been applied, so it is
+// usually run in its own goroutine:
+//
+//	cmds := make(chan ControlCommand)
+//	go DriveFromCommands(reducer, cmds)
+//	cmds <- FlushCommand{}
+//	cmds <- StopCommand{}
+//
+// Commands that fail, including ones the component does not support, are
+// logged and otherwise ignored.
+func DriveFromCommands(c Component, cmds <-chan ControlCommand) {
+	for cmd := range cmds {
+		if err := cmd.Apply(c); err != nil {
+			logf("DriveFromCommands: %T on %T: %v", cmd, c, err)
+		}
+		if _, ok := cmd.(StopCommand); ok {
+			return
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package gocurrent
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDriveReducerFromCommands(t *testing.T) {
+	reducer := NewIDReducer(WithFlushPeriod[int, []int, []int](10 * time.Second))
+	cmds := make(chan ControlCommand)
+	driven := make(chan struct{})
+	go func() {
+		DriveFromCommands(reducer, cmds)
+		close(driven)
+	}()
+
+	reducer.Send(1)
+	reducer.Send(2)
+	cmds <- FlushCommand{}
+	assert.Equal(t, []int{1, 2}, withTimeout(t, reducer.OutputChan()), "Flush command should flush the reducer")
+
+	// Unsupported commands are a no-op and do not stop the driver.
+	cmds <- PauseCommand{}
+	cmds <- SetRateCommand{PerSecond: 10}
+
+	cmds <- StopCommand{}
+	withTimeout(t, driven)
+	assert.False(t, reducer.IsRunning(), "Stop command should stop the reducer")
+	assert.NoError(t, reducer.Stop(), "Stop on a stopped reducer should be a no-op")
+}
+
+func TestControlCommandUnsupported(t *testing.T) {
+	writer := NewWriter(func(int) error { return nil })
+	defer writer.Stop()
+
+	for _, cmd := range []ControlCommand{FlushCommand{}, PauseCommand{}, ResumeCommand{}, SetRateCommand{PerSecond: 1}} {
+		err := cmd.Apply(writer)
+		assert.True(t, errors.Is(err, ErrUnsupportedCommand), "%T should be unsupported on Writer, got %v", cmd, err)
+	}
+	assert.True(t, writer.IsRunning())
+}
+
+func TestDriveFromCommandsReturnsOnClose(t *testing.T) {
+	writer := NewWriter(func(int) error { return nil })
+	defer writer.Stop()
+
+	cmds := make(chan ControlCommand)
+	driven := make(chan struct{})
+	go func() {
+		DriveFromCommands(writer, cmds)
+		close(driven)
+	}()
+	close(cmds)
+	withTimeout(t, driven)
+	assert.True(t, writer.IsRunning(), "Closing the command channel should not stop the component")
+}
+
+func TestDriveRateLimitedPipeFromCommands(t *testing.T) {
+	input := make(chan int, 1)
+	output := make(chan int, 1)
+	pipe := NewRateLimitedPipe(input, output, 1000, 5)
+	cmds := make(chan ControlCommand)
+	driven := make(chan struct{})
+	go func() {
+		DriveFromCommands(pipe, cmds)
+		close(driven)
+	}()
+
+	cmds <- PauseCommand{}
+	input <- 1
+	select {
+	case v := <-output:
+		t.Fatalf("Paused pipe forwarded %d", v)
+	case <-time.After(50 * time.Millisecond):
+	}
+	assert.Equal(t, true, pipe.DebugInfo().(map[string]any)["paused"])
+
+	cmds <- ResumeCommand{}
+	assert.Equal(t, 1, withTimeout(t, output), "Resume command should let the held value pass")
+
+	cmds <- StopCommand{}
+	withTimeout(t, driven)
+	assert.False(t, pipe.IsRunning())
+}
@@ -0,0 +1,41 @@
+package gocurrent
+
+import (
+	"log"
+	"os"
+	"runtime"
+	"time"
+)
+
+// deadlockLogger receives the warnings of components with deadlock detection
+// enabled but no logger of their own, while the package logger is silent.
+// Asking for detection is enough to see its warnings. Tests replace it.
+var deadlockLogger Logger = log.New(os.Stderr, "", log.LstdFlags)
+
+// watchBlocking arms a watchdog for a channel operation that may block. If
+// the operation has not completed within timeout, a diagnostic naming the
+// component, the operation and how long it has been blocked is written to
+// logger, followed by a dump of all goroutine stacks. The caller must invoke
+// the returned function once the operation completes.
+//
+// This backs the deadlock detection options of Mapper, FanIn, FanOut,
+// Debouncer, Throttler and Reducer (see "Deadlock detection" in the README).
+// The watchdog only warns; it never interrupts or otherwise alters the
+// operation being watched. A timeout <= 0 disables it. A nil logger or
+// NopLogger (the package default) falls back to standard error.
+func watchBlocking(logger Logger, timeout time.Duration, component, op string) (done func()) {
+	if timeout <= 0 {
+		return func() {}
+	}
+	if logger == nil || logger == NopLogger {
+		logger = deadlockLogger
+	}
+	start := time.Now()
+	timer := time.AfterFunc(timeout, func() {
+		buf := make([]byte, 64*1024)
+		buf = buf[:runtime.Stack(buf, true)]
+		logger.Printf("gocurrent: possible deadlock: %s blocked on %s for %v\n%s",
+			component, op, time.Since(start), buf)
+	})
+	return func() { timer.Stop() }
+}
@@ -0,0 +1,195 @@
+package gocurrent
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// captureLogger is a Logger that records every formatted line so tests can
+// assert on the diagnostics emitted by primitives.
+type captureLogger struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+func (l *captureLogger) Printf(format string, v ...any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.lines = append(l.lines, fmt.Sprintf(format, v...))
+}
+
+func (l *captureLogger) Lines() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string(nil), l.lines...)
+}
+
+// contains reports whether any captured line contains substr.
+func (l *captureLogger) contains(substr string) bool {
+	for _, line := range l.Lines() {
+		if strings.Contains(line, substr) {
+			return true
+		}
+	}
+	return false
+}
+
+// TestReducerDeadlockDetection verifies that a reducer whose consumer never
+// reads the output channel emits a deadlock warning once the configured
+// timeout elapses, and that the flush still completes once the consumer
+// finally reads.
+func TestReducerDeadlockDetection(t *testing.T) {
+	logger := &captureLogger{}
+	reducer := NewIDReducer(
+		WithFlushPeriod[int, []int, []int](10*time.Second),
+		WithReducerLogger[int, []int, []int](logger),
+		WithDeadlockDetection[int, []int, []int](50*time.Millisecond))
+
+	// Nobody reads the output, so this flush stalls on the send.
+	reducer.Send(1)
+	reducer.Flush()
+
+	deadline := time.Now().Add(testTimeout)
+	for !logger.contains("possible deadlock") {
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for deadlock warning")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if !logger.contains("send to outputChan") {
+		t.Errorf("Warning should name the blocked channel, got: %v", logger.Lines())
+	}
+
+	// Detection must not alter behavior: the stalled flush is still delivered.
+	batch := withTimeout(t, reducer.OutputChan())
+	if len(batch) != 1 || batch[0] != 1 {
+		t.Errorf("Expected [1], got %v", batch)
+	}
+	reducer.Stop()
+}
+
+// TestReducerDeadlockDetectionQuiet verifies that no warning is emitted when
+// the consumer keeps up.
+func TestReducerDeadlockDetectionQuiet(t *testing.T) {
+	logger := &captureLogger{}
+	reducer := NewIDReducer(
+		WithFlushPeriod[int, []int, []int](10*time.Second),
+		WithReducerLogger[int, []int, []int](logger),
+		WithDeadlockDetection[int, []int, []int](200*time.Millisecond))
+	defer reducer.Stop()
+
+	reducer.Send(1)
+	go reducer.Flush()
+	withTimeout(t, reducer.OutputChan())
+	time.Sleep(250 * time.Millisecond)
+	if logger.contains("possible deadlock") {
+		t.Errorf("Unexpected deadlock warning: %v", logger.Lines())
+	}
+}
+
+// waitForWarning waits until logger has captured a deadlock warning
+// mentioning op.
+func waitForWarning(t *testing.T, logger *captureLogger, op string) {
+	t.Helper()
+	deadline := time.Now().Add(testTimeout)
+	for !logger.contains("possible deadlock") || !logger.contains(op) {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for deadlock warning on %q, got: %v", op, logger.Lines())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// TestReducerManyDeadlockDetection verifies that ReduceManyFunc flushes are
+// watched too.
+func TestReducerManyDeadlockDetection(t *testing.T) {
+	logger := &captureLogger{}
+	reducer := NewReducer(
+		WithFlushPeriod[int, []int, int](10*time.Second),
+		WithCollectFunc[int, []int, int](func(c []int, in ...int) ([]int, bool) {
+			return append(c, in...), false
+		}),
+		WithReduceManyFunc[int, []int, int](func(c []int) []int { return c }),
+		WithReducerLogger[int, []int, int](logger),
+		WithDeadlockDetection[int, []int, int](50*time.Millisecond))
+	defer reducer.Stop()
+
+	reducer.Send(1)
+	reducer.Flush()
+	waitForWarning(t, logger, "Reducer blocked on send to outputChan")
+	assert.Equal(t, 1, withTimeout(t, reducer.OutputChan()))
+}
+
+// TestMapperDeadlockDetection verifies that a mapper whose output is never
+// read warns via the package logger and still delivers once read.
+func TestMapperDeadlockDetection(t *testing.T) {
+	logger := &captureLogger{}
+	SetLogger(logger)
+	defer SetLogger(nil)
+
+	in, out := make(chan int, 1), make(chan int)
+	m := NewMapper(in, out, idMapperFunc[int],
+		WithMapperDeadlockDetection[int, int](50*time.Millisecond))
+	defer m.Stop()
+
+	in <- 1
+	waitForWarning(t, logger, "Mapper blocked on send to output")
+	assert.Equal(t, 1, withTimeout(t, out))
+}
+
+// TestFanInDeadlockDetection verifies that the FanIn's input pipes warn via
+// the FanIn's logger when OutputChan() is not read.
+func TestFanInDeadlockDetection(t *testing.T) {
+	logger := &captureLogger{}
+	fanin := NewFanIn(
+		WithFanInLogger[int](logger),
+		WithFanInDeadlockDetection[int](50*time.Millisecond))
+	defer fanin.Stop()
+
+	in := make(chan int, 1)
+	fanin.Add(in)
+	in <- 1
+	waitForWarning(t, logger, "blocked on send to output")
+	assert.Equal(t, 1, withTimeout(t, fanin.OutputChan()))
+}
+
+// TestDeadlockDetectionPackageLogger verifies that WithDeadlockDetection
+// combined with SetLogger, rather than WithReducerLogger, reports to the
+// package logger.
+func TestDeadlockDetectionPackageLogger(t *testing.T) {
+	logger := &captureLogger{}
+	SetLogger(logger)
+	defer SetLogger(nil)
+	reducer := NewIDReducer(
+		WithFlushPeriod[int, []int, []int](10*time.Second),
+		WithDeadlockDetection[int, []int, []int](50*time.Millisecond))
+	defer reducer.Stop()
+
+	reducer.Send(1)
+	reducer.Flush()
+	waitForWarning(t, logger, "Reducer blocked on send to outputChan")
+	assert.Equal(t, []int{1}, withTimeout(t, reducer.OutputChan()))
+}
+
+// TestDeadlockDetectionWithoutLogger verifies that with no logger set,
+// warnings fall back to standard error instead of being discarded.
+func TestDeadlockDetectionWithoutLogger(t *testing.T) {
+	logger := &captureLogger{}
+	saved := deadlockLogger
+	deadlockLogger = logger
+	defer func() { deadlockLogger = saved }()
+
+	in, out := make(chan int, 1), make(chan int)
+	m := NewMapper(in, out, idMapperFunc[int],
+		WithMapperDeadlockDetection[int, int](50*time.Millisecond))
+	defer m.Stop()
+
+	in <- 1
+	waitForWarning(t, logger, "Mapper blocked on send to output")
+	assert.Equal(t, 1, withTimeout(t, out))
+}
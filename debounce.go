@@ -0,0 +1,142 @@
+package gocurrent
+
+import (
+	"context"
+	"time"
+)
+
+// Debouncer forwards a value from input to output only once quiet has
+// elapsed without a newer value arriving. Values superseded within the quiet
+// window are dropped, so a burst results in a single output: its last value.
+//
+// If the input channel is closed while a value is pending it is emitted
+// immediately. As with Mapper, the channels are owned by the caller.
+type Debouncer[T any] struct {
+	RunnerBase[string]
+	input      <-chan T
+	output     chan<- T
+	quiet      time.Duration
+	emitOnStop bool
+	closedChan chan error
+	clock      Clock
+
+	deadlockTimeout time.Duration // see WithDebouncerDeadlockDetection
+}
+
+// DebouncerOption is a functional option for configuring a Debouncer.
+type DebouncerOption[T any] func(*Debouncer[T])
+
+// WithEmitOnStop makes Stop emit the pending value, if any, instead of
+// dropping it. The final send blocks until output is read.
+func WithEmitOnStop[T any](emit bool) DebouncerOption[T] {
+	return func(d *Debouncer[T]) {
+		d.emitOnStop = emit
+	}
+}
+
+// WithDebouncerContext ties the debouncer's lifetime to ctx. When ctx is done
+// the debouncer stops itself and ClosedChan() receives ctx.Err().
+func WithDebouncerContext[T any](ctx context.Context) DebouncerOption[T] {
+	return func(d *Debouncer[T]) {
+		d.ctx = ctx
+	}
+}
+
+// WithDebouncerClock sets the clock timing the quiet window, e.g. a
+// FakeClock in tests. The default is RealClock.
+func WithDebouncerClock[T any](clock Clock) DebouncerOption[T] {
+	return func(d *Debouncer[T]) {
+		d.clock = clock
+	}
+}
+
+// WithDebouncerDeadlockDetection warns when an output send stays blocked for
+// longer than timeout. See "Deadlock detection" in the README.
+func WithDebouncerDeadlockDetection[T any](timeout time.Duration) DebouncerOption[T] {
+	return func(d *Debouncer[T]) {
+		d.deadlockTimeout = timeout
+	}
+}
+
+// NewDebouncer creates a debouncer between input and output.
+//
+// Example:
+//
+//	// Rebuild once file changes have settled for 200ms
+//	rebuild := make(chan string)
+//	deb := NewDebouncer(fsEvents, rebuild, 200*time.Millisecond)
+//	defer deb.Stop()
+func NewDebouncer[T any](input <-chan T, output chan<- T, quiet time.Duration, opts ...DebouncerOption[T]) *Debouncer[T] {
+	out := &Debouncer[T]{
+		RunnerBase: NewRunnerBase("stop"),
+		input:      input,
+		output:     output,
+		quiet:      quiet,
+		closedChan: make(chan error, 1),
+	}
+	out.component = "Debouncer"
+	for _, opt := range opts {
+		opt(out)
+	}
+	out.start()
+	return out
+}
+
+// ClosedChan returns the channel used to signal when the debouncer is done.
+func (d *Debouncer[T]) ClosedChan() <-chan error {
+	return d.closedChan
+}
+
+func (d *Debouncer[T]) cleanup() {
+	close(d.closedChan)
+	d.RunnerBase.cleanup()
+}
+
+func (d *Debouncer[T]) start() {
+	d.RunnerBase.start()
+	go func() {
+		defer d.cleanup()
+		var pending T
+		hasPending := false
+		timer := clockOr(d.clock).NewTimer(d.quiet)
+		timer.Stop()
+		defer timer.Stop()
+		var fire <-chan time.Time
+		for {
+			select {
+			case <-d.controlChan:
+				if d.emitOnStop && hasPending {
+					d.send(pending)
+				}
+				return
+			case <-d.ctxDone():
+				d.setErr(d.contextErr())
+				d.closedChan <- d.contextErr()
+				return
+			case value, ok := <-d.input:
+				if !ok {
+					if hasPending {
+						d.send(pending)
+					}
+					return
+				}
+				pending, hasPending = value, true
+				timer.Reset(d.quiet)
+				fire = timer.C()
+			case <-fire:
+				fire = nil
+				d.send(pending)
+				var zero T
+				pending, hasPending = zero, false
+			}
+		}
+	}()
+}
+
+// send delivers a value to the output channel, watched for deadlocks if
+// enabled.
+func (d *Debouncer[T]) send(value T) {
+	done := watchBlocking(defaultLogger(), d.deadlockTimeout, "Debouncer", "send to output")
+	d.output <- value
+	done()
+}
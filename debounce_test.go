@@ -0,0 +1,64 @@
+package gocurrent
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDebouncerEmitsLastOfBurst(t *testing.T) {
+	input := make(chan int)
+	output := make(chan int, 10)
+	deb := NewDebouncer(input, output, 50*time.Millisecond)
+	defer deb.Stop()
+
+	for i := 1; i <= 10; i++ {
+		input <- i
+	}
+	assert.Equal(t, 10, withTimeout(t, output))
+	select {
+	case v := <-output:
+		t.Fatalf("Superseded value %d should have been dropped", v)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// A second burst after the quiet window produces its own output
+	input <- 20
+	input <- 21
+	assert.Equal(t, 21, withTimeout(t, output))
+}
+
+func TestDebouncerStopDropsPending(t *testing.T) {
+	input := make(chan int)
+	output := make(chan int, 1)
+	deb := NewDebouncer(input, output, time.Hour)
+
+	input <- 1
+	deb.Stop()
+	<-deb.ClosedChan()
+	assert.Len(t, output, 0, "Pending value should be dropped by default")
+}
+
+func TestDebouncerEmitOnStop(t *testing.T) {
+	input := make(chan int)
+	output := make(chan int, 1)
+	deb := NewDebouncer(input, output, time.Hour, WithEmitOnStop[int](true))
+
+	input <- 1
+	input <- 2
+	deb.Stop()
+	assert.Equal(t, 2, withTimeout(t, output), "Pending value should be emitted on Stop")
+}
+
+func TestDebouncerFlushesOnInputClose(t *testing.T) {
+	input := make(chan int)
+	output := make(chan int, 1)
+	deb := NewDebouncer(input, output, time.Hour)
+
+	input <- 7
+	close(input)
+	assert.Equal(t, 7, withTimeout(t, output))
+	<-deb.ClosedChan()
+	assert.False(t, deb.IsRunning())
+}
@@ -0,0 +1,98 @@
+package gocurrent
+
+import (
+	"sync"
+	"time"
+)
+
+// Dedup forwards a value from input to output only if its key has not been
+// forwarded within the TTL window. Duplicates arriving inside the window are
+// dropped and do not extend it.
+//
+// Expired keys are pruned in a sweep at most once per TTL, performed inline
+// as values arrive, so the key set stays proportional to the number of
+// distinct keys seen in roughly the last two TTLs. Without a TTL every key is
+// remembered forever; use that only for bounded key spaces, or see
+// Idempotent for a size-bounded alternative.
+type Dedup[T any, K comparable] struct {
+	*Mapper[T, T]
+	keyFn     func(T) K
+	ttl       time.Duration
+	mu        sync.Mutex
+	seen      map[K]time.Time // key -> time it was forwarded
+	lastPrune time.Time
+	clock     Clock
+}
+
+// DedupOption is a functional option for configuring a Dedup.
+type DedupOption[T any, K comparable] func(*Dedup[T, K])
+
+// WithDedupTTL sets how long a forwarded key suppresses duplicates.
+func WithDedupTTL[T any, K comparable](ttl time.Duration) DedupOption[T, K] {
+	return func(d *Dedup[T, K]) {
+		d.ttl = ttl
+	}
+}
+
+// WithDedupClock sets the clock timing the TTL window, e.g. a FakeClock in
+// tests. The default is RealClock.
+func WithDedupClock[T any, K comparable](clock Clock) DedupOption[T, K] {
+	return func(d *Dedup[T, K]) {
+		d.clock = clock
+	}
+}
+
+// NewDedup creates a deduplicating stage between input and output keyed by
+// keyFn. As with NewMapper, the channels are owned by the caller.
+//
+// Example:
+//
+//	dd := NewDedup(events, unique, func(e Event) string { return e.ID },
+//	    WithDedupTTL[Event, string](time.Minute))
+//	defer dd.Stop()
+func NewDedup[T any, K comparable](input <-chan T, output chan<- T, keyFn func(T) K, opts ...DedupOption[T, K]) *Dedup[T, K] {
+	out := &Dedup[T, K]{
+		keyFn: keyFn,
+		seen:  make(map[K]time.Time),
+	}
+	for _, opt := range opts {
+		opt(out)
+	}
+	out.clock = clockOr(out.clock)
+	out.lastPrune = out.clock.Now()
+	out.Mapper = NewMapper(input, output, out.apply)
+	return out
+}
+
+// Len returns the number of keys currently remembered.
+func (d *Dedup[T, K]) Len() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return len(d.seen)
+}
+
+func (d *Dedup[T, K]) apply(value T) (T, bool, bool) {
+	now := d.clock.Now()
+	key := d.keyFn(value)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.ttl > 0 && now.Sub(d.lastPrune) >= d.ttl {
+		d.prune(now)
+	}
+	if at, ok := d.seen[key]; ok && (d.ttl <= 0 || now.Sub(at) < d.ttl) {
+		return value, true, false
+	}
+	d.seen[key] = now
+	return value, false, false
+}
+
+// prune removes expired keys. Must hold mu.
+func (d *Dedup[T, K]) prune(now time.Time) {
+	for k, at := range d.seen {
+		if now.Sub(at) >= d.ttl {
+			delete(d.seen, k)
+		}
+	}
+	d.lastPrune = now
+}
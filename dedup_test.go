@@ -0,0 +1,76 @@
+package gocurrent
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDedupSuppressesDuplicates(t *testing.T) {
+	input := make(chan string)
+	output := make(chan string, 10)
+	dd := NewDedup(input, output, func(s string) string { return s })
+	defer dd.Stop()
+
+	for _, s := range []string{"a", "b", "a", "c", "b", "a"} {
+		input <- s
+	}
+	close(input)
+	<-dd.ClosedChan()
+	close(output)
+
+	var got []string
+	for s := range output {
+		got = append(got, s)
+	}
+	assert.Equal(t, []string{"a", "b", "c"}, got)
+}
+
+func TestDedupTTLExpiryAllowsRepeat(t *testing.T) {
+	const ttl = time.Minute
+	clock := NewFakeClock(time.Now())
+	input := make(chan int)
+	output := make(chan int, 10)
+	// Key on value/10 so 11 is a duplicate of 10
+	dd := NewDedup(input, output, func(v int) int { return v / 10 },
+		WithDedupTTL[int, int](ttl), WithDedupClock[int, int](clock))
+	defer dd.Stop()
+
+	input <- 10
+	input <- 11
+	input <- 20
+	assert.Equal(t, 10, withTimeout(t, output))
+	assert.Equal(t, 20, withTimeout(t, output), "Duplicate within the TTL should be dropped")
+
+	clock.Advance(ttl - time.Second)
+	input <- 13
+	input <- 30
+	assert.Equal(t, 30, withTimeout(t, output), "Key should still be suppressed just before the TTL")
+
+	clock.Advance(time.Second)
+	input <- 12
+	assert.Equal(t, 12, withTimeout(t, output), "Key should be forwarded again after the TTL")
+	assert.Len(t, output, 0)
+}
+
+func TestDedupPrunesExpiredKeys(t *testing.T) {
+	const ttl = time.Minute
+	clock := NewFakeClock(time.Now())
+	input := make(chan int)
+	output := make(chan int, 200)
+	dd := NewDedup(input, output, func(v int) int { return v },
+		WithDedupTTL[int, int](ttl), WithDedupClock[int, int](clock))
+	defer dd.Stop()
+
+	for i := 0; i < 100; i++ {
+		input <- i
+		withTimeout(t, output)
+	}
+	assert.Equal(t, 100, dd.Len())
+
+	clock.Advance(2 * ttl)
+	input <- 1000
+	withTimeout(t, output)
+	assert.Equal(t, 1, dd.Len(), "Expired keys should be pruned")
+}
@@ -16,6 +16,8 @@
 //     [QueuedFanOut] (recommended), each with different ordering/blocking trade-offs.
 //     See the [FanOuter] interface for the common API.
 //   - SyncMap: A type-safe generic wrapper around sync.Map
+//   - Map: A thread-safe generic map with read/write locking and whole-map
+//     operations such as snapshots
 //
 // All concurrency primitives are designed to be composable and provide
 // fine-grained control over goroutine lifecycles, resource management, and
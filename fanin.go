@@ -1,11 +1,20 @@
 package gocurrent
 
-import "log"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
 
 type fanInCmd[T any] struct {
 	Name           string
 	AddedChannel   <-chan T
 	RemovedChannel <-chan T
+	InfoChan       chan any      // receives the DebugInfo snapshot for "debug"
+	Removed        chan struct{} // closed when an added channel is removed
 }
 
 // FanIn merges multiple input channels into a single output channel.
@@ -22,6 +31,29 @@ type FanIn[T any] struct {
 	outChan    chan T
 	closedChan chan error
 	stopping   chan struct{} // closed at start of cleanup to unblock pipeClosed
+	logger     Logger
+
+	deadlockTimeout time.Duration // see WithFanInDeadlockDetection
+
+	controlBuffer int
+	clock         Clock // times AddReconnecting delays
+
+	// Closed when the corresponding input is removed (see AddReconnecting).
+	// Only accessed by the FanIn goroutine.
+	removedNotify map[<-chan T]chan struct{}
+
+	// Error aggregation (see WithFanInErrors)
+	errFunc   func(T) error
+	errMu     sync.Mutex
+	inputErrs []error
+
+	// Fair scheduling mode (see WithFairScheduling)
+	fair       bool
+	fairInputs []<-chan T
+
+	// inputCount mirrors the length of inputs (or fairInputs), which only
+	// the FanIn goroutine may touch, for Count.
+	inputCount atomic.Int64
 }
 
 // FanInOption is a functional option for configuring a FanIn
@@ -50,6 +82,75 @@ func WithFanInOnChannelRemoved[T any](fn func(*FanIn[T], <-chan T)) FanInOption[
 	}
 }
 
+// WithFanInLogger sets the logger used for the FanIn's internal diagnostics.
+// By default the package logger (see SetLogger) is used.
+func WithFanInLogger[T any](logger Logger) FanInOption[T] {
+	return func(fi *FanIn[T]) {
+		fi.logger = logger
+	}
+}
+
+// WithFanInDeadlockDetection warns, via the FanIn's logger, when a send to
+// OutputChan() stays blocked for longer than timeout. See "Deadlock
+// detection" in the README.
+func WithFanInDeadlockDetection[T any](timeout time.Duration) FanInOption[T] {
+	return func(fi *FanIn[T]) {
+		fi.deadlockTimeout = timeout
+	}
+}
+
+// WithFanInClock sets the clock timing the reconnect delays of
+// AddReconnecting, e.g. a FakeClock in tests. The default is RealClock.
+func WithFanInClock[T any](clock Clock) FanInOption[T] {
+	return func(fi *FanIn[T]) {
+		fi.clock = clock
+	}
+}
+
+// WithFanInControlBuffer lets up to n Add/Remove commands queue up without
+// blocking the caller while the FanIn is busy. The default is 1.
+func WithFanInControlBuffer[T any](n int) FanInOption[T] {
+	return func(fi *FanIn[T]) {
+		fi.controlBuffer = n
+	}
+}
+
+// WithFanInErrors makes the FanIn collect the errors carried by the values
+// it merges, as extracted by errFn (e.g. returning Message.Error), so a
+// failing upstream can be identified after the fact. Values are forwarded
+// unchanged. When the FanIn stops, the collected errors, each an
+// *InputError naming its input, are joined (see errors.Join) and reported on
+// ClosedChan().
+func WithFanInErrors[T any](errFn func(T) error) FanInOption[T] {
+	return func(fi *FanIn[T]) {
+		fi.errFunc = errFn
+	}
+}
+
+// InputError is an error carried by a value received from one of a FanIn's
+// inputs (see WithFanInErrors).
+type InputError[T any] struct {
+	Input <-chan T
+	Err   error
+}
+
+func (e *InputError[T]) Error() string {
+	return fmt.Sprintf("fan-in input %v: %v", e.Input, e.Err)
+}
+
+// Unwrap returns the input's error.
+func (e *InputError[T]) Unwrap() error {
+	return e.Err
+}
+
+// WithFanInContext ties the FanIn's lifetime to ctx. When ctx is done the
+// FanIn stops itself and ClosedChan() receives ctx.Err().
+func WithFanInContext[T any](ctx context.Context) FanInOption[T] {
+	return func(fi *FanIn[T]) {
+		fi.ctx = ctx
+	}
+}
+
 // NewFanIn creates a new FanIn that merges multiple input channels with functional options.
 // By default, creates and owns an unbuffered output channel. Use options to customize.
 // The FanIn starts running immediately upon creation.
@@ -82,6 +183,13 @@ func NewFanIn[T any](opts ...FanInOption[T]) *FanIn[T] {
 	if out.outChan == nil {
 		out.outChan = make(chan T)
 	}
+	if out.controlBuffer > 1 {
+		// Keep the context WithFanInContext may have set.
+		ctx := out.ctx
+		out.RunnerBase = NewRunnerBaseN(fanInCmd[T]{Name: "stop"}, out.controlBuffer)
+		out.ctx = ctx
+	}
+	out.component = "FanIn"
 
 	out.start()
 	return out
@@ -97,16 +205,56 @@ func (fi *FanIn[T]) OutputChan() <-chan T {
 	return fi.outChan
 }
 
+// Subscription is a handle to inputs added to a FanIn. Cancel removes them
+// without the caller having to keep the original channels around.
+type Subscription struct {
+	once   sync.Once
+	cancel func()
+}
+
+// Cancel removes the inputs this subscription was created for. It is safe to
+// call more than once and after the FanIn has stopped.
+func (s *Subscription) Cancel() {
+	s.once.Do(s.cancel)
+}
+
 // Add adds one or more input channels to the FanIn.
 // Messages from these channels will be merged into the output channel.
+// The returned Subscription removes exactly these inputs when cancelled.
 // Panics if any input channel is nil.
-func (fi *FanIn[T]) Add(inputs ...<-chan T) {
+func (fi *FanIn[T]) Add(inputs ...<-chan T) *Subscription {
 	for _, input := range inputs {
 		if input == nil {
 			panic("Cannot add nil channels")
 		}
 		fi.controlChan <- fanInCmd[T]{Name: "add", AddedChannel: input}
 	}
+	added := append([]<-chan T(nil), inputs...)
+	return &Subscription{cancel: func() {
+		for _, input := range added {
+			select {
+			case fi.controlChan <- fanInCmd[T]{Name: "remove", RemovedChannel: input}:
+			case <-fi.Done():
+				return
+			}
+		}
+	}}
+}
+
+// AddContext adds an input channel that is removed again (firing
+// OnChannelRemoved) once ctx is done. Cancelling the returned Subscription
+// removes it early. If the input closes first it is removed as usual, and
+// the later cancellation is a no-op.
+func (fi *FanIn[T]) AddContext(ctx context.Context, input <-chan T) *Subscription {
+	sub := fi.Add(input)
+	go func() {
+		select {
+		case <-ctx.Done():
+			sub.Cancel()
+		case <-fi.Done():
+		}
+	}()
+	return sub
 }
 
 // Remove removes an input channel from the FanIn's monitor list.
@@ -115,9 +263,58 @@ func (fi *FanIn[T]) Remove(target <-chan T) {
 	fi.controlChan <- fanInCmd[T]{Name: "remove", RemovedChannel: target}
 }
 
-// Count returns the number of input channels currently being monitored.
+// Count returns the number of input channels currently being monitored. It
+// is safe to call from any goroutine, including an OnChannelRemoved
+// callback, where it already excludes the removed channel.
 func (fi *FanIn[T]) Count() int {
-	return len(fi.inputs)
+	return int(fi.inputCount.Load())
+}
+
+// DebugInfo returns diagnostic information including the number of inputs,
+// whether the output channel is owned, and for each input whether its pipe
+// is still running ("running") or, with fair scheduling, whether it is still
+// open ("open"). The snapshot is taken by the FanIn goroutine, so it is consistent
+// with concurrent Add/Remove calls.
+func (fi *FanIn[T]) DebugInfo() any {
+	reply := make(chan any, 1)
+	select {
+	case fi.controlChan <- fanInCmd[T]{Name: "debug", InfoChan: reply}:
+		// The buffered control channel may accept the command after the
+		// goroutine has exited, so keep watching Done().
+		select {
+		case info := <-reply:
+			return info
+		case <-fi.Done():
+		}
+	case <-fi.Done():
+	}
+	// The goroutine has exited, so its state can be read directly.
+	return fi.debugInfo()
+}
+
+// debugInfo builds the DebugInfo snapshot. It must be called from the FanIn
+// goroutine or after it has exited.
+func (fi *FanIn[T]) debugInfo() map[string]any {
+	inputs := []map[string]any{}
+	if fi.fair {
+		// Fair mode reads its inputs directly and drops one as soon as a
+		// read finds it closed, so every listed input is still open as far
+		// as the FanIn has seen.
+		for _, input := range fi.fairInputs {
+			inputs = append(inputs, map[string]any{"input": input, "open": true})
+		}
+	} else {
+		for _, input := range fi.inputs {
+			inputs = append(inputs, map[string]any{"input": input.input, "running": input.IsRunning()})
+		}
+	}
+	return map[string]any{
+		"base":       fi.RunnerBase.DebugInfo(),
+		"inputCount": len(inputs),
+		"inputs":     inputs,
+		"outputChan": fi.outChan,
+		"ownsOutput": fi.selfOwnOut,
+	}
 }
 
 func (fi *FanIn[T]) cleanup() {
@@ -131,28 +328,53 @@ func (fi *FanIn[T]) cleanup() {
 	if fi.selfOwnOut {
 		close(fi.outChan)
 	}
+	fi.reportInputErrs()
 	close(fi.closedChan)
 	fi.RunnerBase.cleanup()
 }
 
 func (fi *FanIn[T]) start() {
 	fi.RunnerBase.start()
+	if fi.fair {
+		go fi.runFair()
+		return
+	}
 	go func() {
 		defer fi.cleanup()
 		for {
-			cmd := <-fi.controlChan
+			var cmd fanInCmd[T]
+			select {
+			case cmd = <-fi.controlChan:
+			case <-fi.ctxDone():
+				fi.stopForContext()
+				return
+			}
 			if cmd.Name == "stop" {
 				return
 			} else if cmd.Name == "add" {
+				mapFunc := idMapperFunc[T]
+				if fi.errFunc != nil {
+					added := cmd.AddedChannel
+					mapFunc = func(v T) (T, bool, bool) {
+						fi.recordErr(added, v)
+						return v, false, false
+					}
+				}
 				// Set OnDone at construction time via option to avoid racing
 				// with the Mapper goroutine (which starts immediately).
-				input := NewMapper(cmd.AddedChannel, fi.outChan, idMapperFunc[T],
-					WithMapperOnDone[T, T](func(m *Mapper[T, T]) { fi.pipeClosed(m) }))
+				fi.watchRemoval(cmd)
+				input := NewMapper(cmd.AddedChannel, fi.outChan, mapFunc,
+					WithMapperOnDone[T, T](func(m *Mapper[T, T]) { fi.pipeClosed(m) }),
+					WithMapperDeadlockDetection[T, T](fi.deadlockTimeout),
+					func(m *Mapper[T, T]) { m.logger = fi.log() })
 				fi.inputs = append(fi.inputs, input)
+				fi.inputCount.Store(int64(len(fi.inputs)))
 			} else if cmd.Name == "remove" {
 				// Remove an existing reader from our list
-				log.Println("Removing channel: ", cmd.RemovedChannel)
+				fi.log().Printf("Removing channel: %v", cmd.RemovedChannel)
 				fi.remove(cmd.RemovedChannel)
+			} else if cmd.Name == "debug" {
+				cmd.InfoChan <- fi.debugInfo()
 			} else if cmd.Name == "pipe_closed" {
 				// A pipe self-terminated (its input channel was closed).
 				// Remove it from our inputs list.
@@ -167,6 +389,8 @@ func (fi *FanIn[T]) removeAt(index int) {
 	fi.inputs[index].Stop()
 	fi.inputs[index] = fi.inputs[len(fi.inputs)-1]
 	fi.inputs = fi.inputs[:len(fi.inputs)-1]
+	fi.inputCount.Store(int64(len(fi.inputs)))
+	fi.notifyRemoved(inchan)
 	if fi.OnChannelRemoved != nil {
 		fi.OnChannelRemoved(fi, inchan)
 	}
@@ -194,3 +418,43 @@ func (fi *FanIn[T]) remove(inchan <-chan T) {
 		}
 	}
 }
+
+// recordErr keeps the error carried by v, if any, when error aggregation is
+// enabled. It is called concurrently by the input pipes.
+func (fi *FanIn[T]) recordErr(input <-chan T, v T) {
+	if fi.errFunc == nil {
+		return
+	}
+	if err := fi.errFunc(v); err != nil {
+		fi.errMu.Lock()
+		fi.inputErrs = append(fi.inputErrs, &InputError[T]{Input: input, Err: err})
+		fi.errMu.Unlock()
+	}
+}
+
+// reportInputErrs sends the collected input errors on closedChan, joined
+// with any error already queued there (e.g. from a done context).
+func (fi *FanIn[T]) reportInputErrs() {
+	fi.errMu.Lock()
+	err := errors.Join(fi.inputErrs...)
+	fi.errMu.Unlock()
+	if err == nil {
+		return
+	}
+	err = fi.wrapErr(err)
+	select {
+	case prev := <-fi.closedChan:
+		err = errors.Join(prev, err)
+	default:
+	}
+	fi.setErr(err)
+	fi.closedChan <- err
+}
+
+// log returns the FanIn's logger, falling back to the package logger.
+func (fi *FanIn[T]) log() Logger {
+	if fi.logger != nil {
+		return fi.logger
+	}
+	return defaultLogger()
+}
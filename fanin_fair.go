@@ -0,0 +1,149 @@
+package gocurrent
+
+import "reflect"
+
+// WithFairScheduling makes the FanIn read its inputs itself, round-robin,
+// instead of running one pipe per input that races to the output. After an
+// input delivers a value, every other ready input gets a turn before it is
+// read again, so a flooding input cannot starve a low-rate one. Removed and
+// closed inputs are reported via OnChannelRemoved as usual.
+func WithFairScheduling[T any]() FanInOption[T] {
+	return func(fi *FanIn[T]) {
+		fi.fair = true
+	}
+}
+
+// runFair is the worker loop of a FanIn with fair scheduling.
+func (fi *FanIn[T]) runFair() {
+	defer fi.cleanup()
+	next := 0 // the input that gets the first turn in the next pass
+	for {
+		// Commands take priority so Add/Remove/Stop are never starved by
+		// busy inputs.
+		select {
+		case cmd := <-fi.controlChan:
+			if fi.handleFairCmd(cmd) {
+				return
+			}
+			continue
+		case <-fi.ctxDone():
+			fi.stopForContext()
+			return
+		default:
+		}
+
+		// One round-robin pass over the inputs, starting at next.
+		polled := false
+		for k, n := 0, len(fi.fairInputs); k < n && !polled; k++ {
+			i := (next + k) % n
+			select {
+			case v, ok := <-fi.fairInputs[i]:
+				polled = true
+				if !ok {
+					fi.removeFairAt(i)
+					next = i
+					break
+				}
+				next = i + 1
+				fi.recordErr(fi.fairInputs[i], v)
+				if !fi.emitFair(v) {
+					return
+				}
+			default:
+			}
+		}
+		if polled {
+			continue
+		}
+
+		// Nothing ready: wait for any input or command.
+		cases := make([]reflect.SelectCase, 0, len(fi.fairInputs)+2)
+		cases = append(cases,
+			reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(fi.controlChan)},
+			reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(fi.ctxDone())})
+		for _, input := range fi.fairInputs {
+			cases = append(cases, reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(input)})
+		}
+		chosen, v, ok := reflect.Select(cases)
+		switch {
+		case chosen == 0:
+			if fi.handleFairCmd(v.Interface().(fanInCmd[T])) {
+				return
+			}
+		case chosen == 1:
+			fi.stopForContext()
+			return
+		case !ok:
+			fi.removeFairAt(chosen - 2)
+		default:
+			next = chosen - 1
+			fi.recordErr(fi.fairInputs[chosen-2], v.Interface().(T))
+			if !fi.emitFair(v.Interface().(T)) {
+				return
+			}
+		}
+	}
+}
+
+// emitFair sends v to the output, still serving commands while the consumer
+// is slow. It returns false if the FanIn must stop.
+func (fi *FanIn[T]) emitFair(v T) bool {
+	done := watchBlocking(fi.log(), fi.deadlockTimeout, "FanIn", "send to outputChan")
+	defer done()
+	for {
+		select {
+		case fi.outChan <- v:
+			return true
+		case cmd := <-fi.controlChan:
+			if fi.handleFairCmd(cmd) {
+				return false
+			}
+		case <-fi.ctxDone():
+			fi.stopForContext()
+			return false
+		}
+	}
+}
+
+// handleFairCmd applies a control command in fair mode and reports whether
+// the FanIn must stop.
+func (fi *FanIn[T]) handleFairCmd(cmd fanInCmd[T]) bool {
+	switch cmd.Name {
+	case "stop":
+		return true
+	case "add":
+		fi.watchRemoval(cmd)
+		fi.fairInputs = append(fi.fairInputs, cmd.AddedChannel)
+		fi.inputCount.Store(int64(len(fi.fairInputs)))
+	case "remove":
+		fi.log().Printf("Removing channel: %v", cmd.RemovedChannel)
+		for i, input := range fi.fairInputs {
+			if input == cmd.RemovedChannel {
+				fi.removeFairAt(i)
+				break
+			}
+		}
+	case "debug":
+		cmd.InfoChan <- fi.debugInfo()
+	}
+	return false
+}
+
+// removeFairAt drops the input at index, preserving the order of the others
+// so the round-robin rotation is unaffected.
+func (fi *FanIn[T]) removeFairAt(index int) {
+	inchan := fi.fairInputs[index]
+	fi.fairInputs = append(fi.fairInputs[:index], fi.fairInputs[index+1:]...)
+	fi.inputCount.Store(int64(len(fi.fairInputs)))
+	fi.notifyRemoved(inchan)
+	if fi.OnChannelRemoved != nil {
+		fi.OnChannelRemoved(fi, inchan)
+	}
+}
+
+// stopForContext reports ctx.Err() before the worker exits because the
+// context is done.
+func (fi *FanIn[T]) stopForContext() {
+	fi.setErr(fi.contextErr())
+	fi.closedChan <- fi.contextErr()
+}
@@ -0,0 +1,103 @@
+package gocurrent
+
+import (
+	"errors"
+	"fmt"
+)
+
+// AddReconnecting keeps a logical input alive across physical reconnects.
+// It adds the channel returned by factory and, whenever that channel closes
+// (or is removed), calls factory again for a fresh one after waiting
+// policy.Delay(1). A factory error is retried with increasing delays
+// (policy.Delay(2), Delay(3), ...); after policy.MaxAttempts consecutive
+// errors the FanIn gives up on this input and reports the last error on
+// ClosedChan() when it stops. A successful connection resets the backoff.
+//
+// Cancelling the returned Subscription stops reconnecting and removes the
+// current channel, if any, shortly afterwards. Reconnection also ends when
+// the FanIn stops.
+func (fi *FanIn[T]) AddReconnecting(factory func() (<-chan T, error), policy RetryPolicy) *Subscription {
+	quit := make(chan struct{})
+	go fi.reconnect(factory, policy, quit)
+	return &Subscription{cancel: func() { close(quit) }}
+}
+
+// reconnect is the supervisor loop of an AddReconnecting input.
+func (fi *FanIn[T]) reconnect(factory func() (<-chan T, error), policy RetryPolicy, quit <-chan struct{}) {
+	attempt, failures := 0, 0
+	for {
+		if attempt > 0 {
+			timer := clockOr(fi.clock).NewTimer(policy.Delay(attempt))
+			select {
+			case <-timer.C():
+			case <-quit:
+				timer.Stop()
+				return
+			case <-fi.Done():
+				timer.Stop()
+				return
+			}
+		}
+
+		input, err := factory()
+		if err == nil && input == nil {
+			err = errors.New("fan-in reconnect: factory returned a nil channel")
+		}
+		if err != nil {
+			failures++
+			attempt++
+			if policy.exhausted(failures) {
+				fi.log().Printf("Giving up reconnecting after %d attempts: %v", failures, err)
+				fi.errMu.Lock()
+				fi.inputErrs = append(fi.inputErrs,
+					fmt.Errorf("fan-in reconnect: giving up after %d attempts: %w", failures, err))
+				fi.errMu.Unlock()
+				return
+			}
+			continue
+		}
+		failures = 0
+
+		removed := make(chan struct{})
+		select {
+		case fi.controlChan <- fanInCmd[T]{Name: "add", AddedChannel: input, Removed: removed}:
+		case <-quit:
+			return
+		case <-fi.Done():
+			return
+		}
+		select {
+		case <-removed:
+			attempt = 1
+		case <-quit:
+			select {
+			case fi.controlChan <- fanInCmd[T]{Name: "remove", RemovedChannel: input}:
+			case <-fi.Done():
+			}
+			return
+		case <-fi.Done():
+			return
+		}
+	}
+}
+
+// watchRemoval remembers the Removed channel of an add command so that
+// notifyRemoved can close it. Called from the FanIn goroutine.
+func (fi *FanIn[T]) watchRemoval(cmd fanInCmd[T]) {
+	if cmd.Removed == nil {
+		return
+	}
+	if fi.removedNotify == nil {
+		fi.removedNotify = map[<-chan T]chan struct{}{}
+	}
+	fi.removedNotify[cmd.AddedChannel] = cmd.Removed
+}
+
+// notifyRemoved tells an AddReconnecting supervisor that its input is gone.
+// Called from the FanIn goroutine.
+func (fi *FanIn[T]) notifyRemoved(input <-chan T) {
+	if removed, ok := fi.removedNotify[input]; ok {
+		close(removed)
+		delete(fi.removedNotify, input)
+	}
+}
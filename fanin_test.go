@@ -1,11 +1,15 @@
 package gocurrent
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"sort"
 	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -225,3 +229,303 @@ func TestMultiReadFanInFromReaders(t *testing.T) {
 	fanin.Stop()
 	writer.Stop()
 }
+
+func TestFanInSubscriptionCancel(t *testing.T) {
+	removed := make(chan (<-chan int), 1)
+	fanin := NewFanIn(WithFanInOnChannelRemoved(func(_ *FanIn[int], ch <-chan int) { removed <- ch }))
+	defer fanin.Stop()
+
+	a, b, c := make(chan int), make(chan int), make(chan int)
+	fanin.Add(a)
+	sub := fanin.Add(b)
+	fanin.Add(c)
+
+	sub.Cancel()
+	sub.Cancel() // idempotent
+	assert.Equal(t, (<-chan int)(b), withTimeout(t, removed))
+
+	go func() { a <- 1 }()
+	assert.Equal(t, 1, withTimeout(t, fanin.OutputChan()))
+	go func() { c <- 3 }()
+	assert.Equal(t, 3, withTimeout(t, fanin.OutputChan()))
+
+	select {
+	case b <- 2:
+		t.Fatal("Cancelled input should no longer be read")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+// TestFanInAddContext verifies that cancelling an input's context removes
+// it, and that an input which closed first is not removed twice.
+func TestFanInAddContext(t *testing.T) {
+	type removal struct {
+		ch    <-chan int
+		count int
+	}
+	removed := make(chan removal, 4)
+	fanin := NewFanIn(WithFanInOnChannelRemoved(func(fi *FanIn[int], ch <-chan int) {
+		removed <- removal{ch, fi.Count()}
+	}))
+	defer fanin.Stop()
+
+	ctxA, cancelA := context.WithCancel(context.Background())
+	ctxB, cancelB := context.WithCancel(context.Background())
+	defer cancelB()
+	a, b, c := make(chan int), make(chan int), make(chan int)
+	fanin.AddContext(ctxA, a)
+	fanin.AddContext(ctxB, b)
+	fanin.Add(c)
+
+	cancelA()
+	r := withTimeout(t, removed)
+	assert.Equal(t, (<-chan int)(a), r.ch)
+	assert.Equal(t, 2, r.count)
+
+	// b closes and is removed; cancelling its context afterwards must not
+	// remove anything else.
+	close(b)
+	r = withTimeout(t, removed)
+	assert.Equal(t, (<-chan int)(b), r.ch)
+	assert.Equal(t, 1, r.count)
+	cancelB()
+
+	go func() { c <- 3 }()
+	assert.Equal(t, 3, withTimeout(t, fanin.OutputChan()))
+	select {
+	case r := <-removed:
+		t.Fatalf("Unexpected removal of %v", r.ch)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+// TestFanInFairScheduling mixes a flooding input with a trickle input and
+// checks that the trickle is served within a bounded number of outputs.
+func TestFanInFairScheduling(t *testing.T) {
+	fanin := NewFanIn(WithFairScheduling[int]())
+	defer fanin.Stop()
+
+	flood := make(chan int, 100)
+	stopFlood := make(chan struct{})
+	defer close(stopFlood)
+	go func() {
+		for {
+			select {
+			case flood <- 0:
+			case <-stopFlood:
+				return
+			}
+		}
+	}()
+	trickle := make(chan int, 10)
+	for i := 1; i <= 10; i++ {
+		trickle <- i
+	}
+	fanin.Add(flood, trickle)
+
+	// Both inputs are always ready, so they must alternate: all 10 trickle
+	// values arrive within the first few dozen outputs.
+	seen := 0
+	for i := 0; i < 30 && seen < 10; i++ {
+		if withTimeout(t, fanin.OutputChan()) != 0 {
+			seen++
+		}
+	}
+	assert.Equal(t, 10, seen, "trickle input was starved by the flood")
+}
+
+// TestFanInFairSchedulingRemove verifies closed and removed inputs are
+// dropped in fair mode.
+func TestFanInFairSchedulingRemove(t *testing.T) {
+	removed := make(chan (<-chan int), 2)
+	fanin := NewFanIn(WithFairScheduling[int](),
+		WithFanInOnChannelRemoved(func(_ *FanIn[int], ch <-chan int) { removed <- ch }))
+	defer fanin.Stop()
+
+	a, b := make(chan int), make(chan int)
+	fanin.Add(a)
+	sub := fanin.Add(b)
+
+	go func() { a <- 1 }()
+	assert.Equal(t, 1, withTimeout(t, fanin.OutputChan()))
+	close(a)
+	assert.Equal(t, (<-chan int)(a), withTimeout(t, removed))
+
+	// Count and DebugInfo are read off the FanIn goroutine without racing it.
+	assert.Equal(t, 1, fanin.Count())
+	inputs := fanin.DebugInfo().(map[string]any)["inputs"].([]map[string]any)
+	assert.Len(t, inputs, 1)
+	assert.Equal(t, (<-chan int)(b), inputs[0]["input"])
+	assert.Equal(t, true, inputs[0]["open"])
+
+	sub.Cancel()
+	assert.Equal(t, (<-chan int)(b), withTimeout(t, removed))
+	assert.Equal(t, 0, fanin.Count())
+}
+
+// TestFanInControlBufferBurst verifies a burst of Add commands does not block
+// the caller while the FanIn goroutine is busy.
+func TestFanInControlBufferBurst(t *testing.T) {
+	gate := make(chan struct{})
+	fanin := NewFanIn(WithFanInControlBuffer[int](16),
+		WithFanInOnChannelRemoved(func(*FanIn[int], <-chan int) { <-gate }))
+	defer fanin.Stop()
+
+	// Park the FanIn goroutine inside the removal callback.
+	first := make(chan int)
+	fanin.Add(first)
+	fanin.Remove(first)
+
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 10; i++ {
+			fanin.Add(make(chan int))
+		}
+		close(done)
+	}()
+	withTimeout(t, done)
+	close(gate)
+}
+
+// TestFanInErrors verifies that errors carried by merged messages are
+// collected per input and reported on ClosedChan when the FanIn stops.
+func TestFanInErrors(t *testing.T) {
+	boom := errors.New("upstream failed")
+	block := make(chan struct{})
+	defer close(block)
+	var reads atomic.Int32
+	failing := NewReader(func() (int, error) {
+		switch reads.Add(1) {
+		case 1:
+			return 1, nil
+		case 2:
+			return 0, boom
+		}
+		<-block
+		return 0, nil
+	})
+	defer failing.Stop()
+	healthy := make(chan Message[int])
+
+	fanin := NewFanIn(WithFanInErrors(func(m Message[int]) error { return m.Error }))
+	fanin.Add(failing.OutputChan(), healthy)
+	go func() { healthy <- Ok(2) }()
+
+	var errs int
+	for i := 0; i < 3; i++ {
+		if withTimeout(t, fanin.OutputChan()).Error != nil {
+			errs++
+		}
+	}
+	assert.Equal(t, 1, errs, "messages are forwarded unchanged")
+
+	go func() {
+		for range fanin.OutputChan() {
+		}
+	}()
+	fanin.Stop()
+	err := withTimeout(t, fanin.ClosedChan())
+	assert.ErrorIs(t, err, boom)
+	var inputErr *InputError[Message[int]]
+	if assert.ErrorAs(t, err, &inputErr) {
+		assert.Equal(t, failing.OutputChan(), inputErr.Input)
+	}
+}
+
+func TestFanInDebugInfo(t *testing.T) {
+	fanin := NewFanIn[int]()
+	in1, in2 := make(chan int), make(chan int)
+	fanin.Add(in1, in2)
+
+	info := fanin.DebugInfo().(map[string]any)
+	assert.Equal(t, 2, info["inputCount"])
+	assert.Equal(t, true, info["ownsOutput"])
+	inputs := info["inputs"].([]map[string]any)
+	assert.Len(t, inputs, 2)
+	for _, input := range inputs {
+		assert.Equal(t, true, input["running"])
+	}
+	assert.ElementsMatch(t, []any{(<-chan int)(in1), (<-chan int)(in2)},
+		[]any{inputs[0]["input"], inputs[1]["input"]})
+
+	fanin.Stop()
+	info = fanin.DebugInfo().(map[string]any)
+	for _, input := range info["inputs"].([]map[string]any) {
+		assert.Equal(t, false, input["running"])
+	}
+}
+
+func TestFanInAddReconnecting(t *testing.T) {
+	fanin := NewFanIn[int]()
+	defer fanin.Stop()
+
+	conns := make(chan chan int, 3)
+	var calls atomic.Int32
+	sub := fanin.AddReconnecting(func() (<-chan int, error) {
+		if calls.Add(1) == 2 {
+			return nil, errors.New("transient")
+		}
+		ch := make(chan int)
+		conns <- ch
+		return ch, nil
+	}, RetryPolicy{InitialDelay: 5 * time.Millisecond, Multiplier: 2})
+
+	first := withTimeout(t, conns)
+	first <- 1
+	assert.Equal(t, 1, withTimeout(t, fanin.OutputChan()))
+	close(first)
+
+	// The first reconnect fails; the next one yields a fresh channel.
+	second := withTimeout(t, conns)
+	second <- 2
+	assert.Equal(t, 2, withTimeout(t, fanin.OutputChan()))
+	assert.Equal(t, int32(3), calls.Load())
+
+	sub.Cancel()
+	assert.Eventually(t, func() bool { return fanin.DebugInfo().(map[string]any)["inputCount"] == 0 },
+		time.Second, 5*time.Millisecond)
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+// TestFanInAddReconnectingClock verifies that reconnect delays are timed
+// by the FanIn's clock.
+func TestFanInAddReconnectingClock(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	fanin := NewFanIn(WithFanInClock[int](clock))
+	defer fanin.Stop()
+
+	conns := make(chan chan int, 2)
+	sub := fanin.AddReconnecting(func() (<-chan int, error) {
+		ch := make(chan int)
+		conns <- ch
+		return ch, nil
+	}, RetryPolicy{InitialDelay: time.Minute})
+	defer sub.Cancel()
+
+	close(withTimeout(t, conns))
+	clock.BlockUntil(1)
+	select {
+	case <-conns:
+		t.Fatal("Reconnected before the delay elapsed")
+	case <-time.After(20 * time.Millisecond):
+	}
+	clock.Advance(time.Minute)
+	second := withTimeout(t, conns)
+	second <- 2
+	assert.Equal(t, 2, withTimeout(t, fanin.OutputChan()))
+}
+
+func TestFanInAddReconnectingGivesUp(t *testing.T) {
+	fanin := NewFanIn[int]()
+	transient := errors.New("transient")
+	var calls atomic.Int32
+	fanin.AddReconnecting(func() (<-chan int, error) {
+		calls.Add(1)
+		return nil, transient
+	}, RetryPolicy{InitialDelay: time.Millisecond, MaxAttempts: 3})
+
+	assert.Eventually(t, func() bool { return calls.Load() == 3 }, time.Second, time.Millisecond)
+	fanin.Stop()
+	assert.ErrorIs(t, fanin.Wait(), transient)
+	assert.Equal(t, int32(3), calls.Load())
+}
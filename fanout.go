@@ -1,7 +1,11 @@
 package gocurrent
 
 import (
-	"log"
+	"context"
+	"hash/maphash"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // FilterFunc is an optional per-output transformation/filtering function.
@@ -29,6 +33,13 @@ type FilterFunc[T any] func(*T) *T
 //	| SyncFanOut     | Yes (all outputs)     | Strict         | 0 extra           |
 //	| AsyncFanOut    | No                    | None           | N per event       |
 //	| QueuedFanOut   | No (until queue full) | Strict         | 2 total (bounded) |
+//
+// Stop shuts every strategy down the same way: the broadcast in progress is
+// delivered to all its (still registered) outputs, further input is ignored,
+// the channels created by New are closed, and then ClosedChan() is closed.
+// Consumers ranging over New() outputs therefore see their loops end, but
+// must keep reading until then, or Stop blocks on the pending delivery.
+// QueuedFanOut discards events still waiting in its dispatch queue.
 type FanOuter[T any] interface {
 	Component
 
@@ -89,11 +100,66 @@ type fanOutCore[T any] struct {
 	outputSelfOwned []bool
 	outputFilters   []FilterFunc[T]
 	closedChan      chan error
+
+	// router, when set (see Keyed), picks the single output index an event
+	// is delivered to. live reports whether an index may be chosen.
+	router func(event T, chans []chan<- T, live func(int) bool) int
+
+	controlBuffer int
+
+	dropPolicy DropPolicy
+	deliveries sync.WaitGroup // in-flight AsyncFanOut delivery goroutines
+	inputCount atomic.Int64
+	counters   sync.Map // chan<- T → *outputCounters, for registered outputs
+
+	deadlockTimeout time.Duration // see WithFanOutDeadlockDetection
+}
+
+// DropPolicy controls what a fan-out does when an output cannot accept an
+// event immediately.
+type DropPolicy int
+
+const (
+	// BlockWhenFull waits for the output to accept the event. This is the
+	// default and the historical behavior.
+	BlockWhenFull DropPolicy = iota
+
+	// DropNewest skips the event for an output that is not ready (an
+	// unbuffered channel without a waiting reader, or a full buffer) and
+	// counts it as dropped. Slow outputs then never hold up the others.
+	DropNewest
+)
+
+// outputCounters tracks deliveries to one output. All methods are nil-safe
+// so that deliveries racing with Remove need no special casing.
+type outputCounters struct {
+	delivered atomic.Int64
+	dropped   atomic.Int64
 }
 
-// initCore sets up the shared state. Called by each concrete constructor.
-func (c *fanOutCore[T]) initCore() {
-	c.RunnerBase = NewRunnerBase(fanOutCmd[T]{Name: "stop"})
+// OutputStats are the delivery counts of a single output.
+type OutputStats struct {
+	Delivered int64
+	Dropped   int64
+}
+
+// FanOutStats is a point-in-time view of a fan-out's traffic. Outputs holds
+// an entry for every currently registered output; counts for removed
+// outputs are discarded.
+type FanOutStats[T any] struct {
+	Input   int64
+	Outputs map[chan<- T]OutputStats
+}
+
+// initCore sets up the shared state. Called by each concrete constructor
+// with the name of its type.
+func (c *fanOutCore[T]) initCore(component string) {
+	// Options run before the base is created, so carry over the context
+	// that WithFanOutContext may have set.
+	ctx := c.ctx
+	c.RunnerBase = NewRunnerBaseN(fanOutCmd[T]{Name: "stop"}, c.controlBuffer)
+	c.ctx = ctx
+	c.component = component
 	c.closedChan = make(chan error, 1)
 	if c.inputChan == nil {
 		c.inputChan = make(chan T)
@@ -112,6 +178,71 @@ func (c *fanOutCore[T]) DebugInfo() any {
 		"inputChan":    c.inputChan,
 		"outputChan":   c.outputChans,
 		"outputChanSO": c.outputSelfOwned,
+		"stats":        c.Stats(),
+	}
+}
+
+// Stats returns the number of events received and, per registered output,
+// the number delivered and dropped (see DropNewest). It is safe to call
+// from any goroutine.
+func (c *fanOutCore[T]) Stats() FanOutStats[T] {
+	stats := FanOutStats[T]{
+		Input:   c.inputCount.Load(),
+		Outputs: map[chan<- T]OutputStats{},
+	}
+	c.counters.Range(func(key, value any) bool {
+		oc := value.(*outputCounters)
+		stats.Outputs[key.(chan<- T)] = OutputStats{
+			Delivered: oc.delivered.Load(),
+			Dropped:   oc.dropped.Load(),
+		}
+		return true
+	})
+	return stats
+}
+
+// countersFor returns the counters of a registered output, or nil.
+func (c *fanOutCore[T]) countersFor(ch chan<- T) *outputCounters {
+	if v, ok := c.counters.Load(ch); ok {
+		return v.(*outputCounters)
+	}
+	return nil
+}
+
+// deliver sends v to ch according to the drop policy and updates the
+// output's counters. Under BlockWhenFull it waits until ch accepts v or stop
+// is closed, returning false in the latter case.
+func (c *fanOutCore[T]) deliver(ch chan<- T, v T, stop <-chan struct{}) bool {
+	counters := c.countersFor(ch)
+	if c.dropPolicy == DropNewest {
+		select {
+		case ch <- v:
+			counters.addDelivered()
+		default:
+			counters.addDropped()
+		}
+		return true
+	}
+	done := watchBlocking(defaultLogger(), c.deadlockTimeout, "FanOut", "send to output")
+	defer done()
+	select {
+	case ch <- v:
+		counters.addDelivered()
+		return true
+	case <-stop:
+		return false
+	}
+}
+
+func (oc *outputCounters) addDelivered() {
+	if oc != nil {
+		oc.delivered.Add(1)
+	}
+}
+
+func (oc *outputCounters) addDropped() {
+	if oc != nil {
+		oc.dropped.Add(1)
 	}
 }
 
@@ -125,9 +256,15 @@ func (c *fanOutCore[T]) InputChan() chan<- T {
 	return c.inputChan
 }
 
-// Send writes a value to the input channel for fan-out distribution.
+// Send writes a value to the input channel for fan-out distribution. Values
+// sent after the fan-out has stopped are discarded.
 func (c *fanOutCore[T]) Send(value T) {
-	c.inputChan <- value
+	// A self-owned input channel is closed when the fan-out stops.
+	defer func() { recover() }()
+	select {
+	case c.inputChan <- value:
+	case <-c.Done():
+	}
 }
 
 // Add registers an output channel with an optional filter.
@@ -143,7 +280,13 @@ func (c *fanOutCore[T]) Add(output chan<- T, filter FilterFunc[T], wait bool) (c
 // New creates a new owned output channel with an optional filter.
 // The fan-out will close this channel on Remove or Stop.
 func (c *fanOutCore[T]) New(filter FilterFunc[T]) chan T {
-	output := make(chan T, 1)
+	return c.newOutput(filter, 1)
+}
+
+// newOutput creates and registers an owned output channel with the given
+// buffer size, blocking until registration is complete.
+func (c *fanOutCore[T]) newOutput(filter FilterFunc[T], size int) chan T {
+	output := make(chan T, size)
 	callbackChan := make(chan error, 1)
 	c.controlChan <- fanOutCmd[T]{
 		Name:         "add",
@@ -166,10 +309,12 @@ func (c *fanOutCore[T]) Remove(output chan<- T, wait bool) (callbackChan chan er
 	return
 }
 
-// cleanup releases resources common to all fan-out types.
+// cleanup releases resources common to all fan-out types. Pending deliveries
+// complete before owned outputs are closed.
 func (c *fanOutCore[T]) cleanup() {
+	c.deliveries.Wait()
 	if c.selfOwnIn {
-		close(c.inputChan)
+		DrainAndClose(c.inputChan)
 	}
 	for index, ch := range c.outputChans {
 		if c.outputSelfOwned[index] && ch != nil {
@@ -192,11 +337,12 @@ func (c *fanOutCore[T]) handleCmd(cmd fanOutCmd[T]) (shouldStop bool) {
 		for _, oc := range c.outputChans {
 			if oc == cmd.AddedChannel {
 				found = true
-				log.Println("Output Channel already exists. Will skip. Remove it first if you want to add again or change filter funcs", cmd.AddedChannel)
+				logf("Output Channel already exists. Will skip. Remove it first if you want to add again or change filter funcs: %v", cmd.AddedChannel)
 				break
 			}
 		}
 		if !found {
+			c.counters.Store(cmd.AddedChannel, &outputCounters{})
 			c.outputChans = append(c.outputChans, cmd.AddedChannel)
 			c.outputSelfOwned = append(c.outputSelfOwned, cmd.SelfOwned)
 			c.outputFilters = append(c.outputFilters, cmd.Filter)
@@ -207,6 +353,7 @@ func (c *fanOutCore[T]) handleCmd(cmd fanOutCmd[T]) (shouldStop bool) {
 	} else if cmd.Name == "remove" {
 		for index, ch := range c.outputChans {
 			if ch == cmd.RemovedChannel {
+				c.counters.Delete(ch)
 				if c.outputSelfOwned[index] {
 					close(ch)
 				}
@@ -252,6 +399,95 @@ func WithFanOutInputBuffer[T any](size int) FanOutOption[T] {
 	}
 }
 
+// WithFanOutContext ties the fan-out's lifetime to ctx. When ctx is done the
+// fan-out stops itself and ClosedChan() receives ctx.Err().
+func WithFanOutContext[T any](ctx context.Context) FanOutOption[T] {
+	return func(c *fanOutCore[T]) {
+		c.ctx = ctx
+	}
+}
+
+// Keyed switches the fan-out from broadcasting to sticky routing: each event
+// is delivered to exactly one output, chosen by hashing keyFn(event), so all
+// events with the same key reach the same output (and stay in order there).
+// Filters still apply to the chosen output.
+//
+// Outputs are chosen by rendezvous (highest random weight) hashing over the
+// output channels themselves rather than by key modulo the output count.
+// Adding an output therefore only moves the keys that the new output wins,
+// and removing one only moves the keys it owned; all other keys keep their
+// output. Events sent while no output is registered are dropped.
+func Keyed[T any, K comparable](keyFn func(T) K) FanOutOption[T] {
+	seed := maphash.MakeSeed()
+	return func(c *fanOutCore[T]) {
+		c.router = func(event T, chans []chan<- T, live func(int) bool) int {
+			kh := maphash.Comparable(seed, keyFn(event))
+			best, bestScore := -1, uint64(0)
+			for index, ch := range chans {
+				if ch == nil || (live != nil && !live(index)) {
+					continue
+				}
+				score := mix64(kh ^ maphash.Comparable(seed, ch))
+				if best < 0 || score > bestScore {
+					best, bestScore = index, score
+				}
+			}
+			return best
+		}
+	}
+}
+
+// mix64 is the splitmix64 finalizer, used to decorrelate combined hashes.
+func mix64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
+// route returns the output index event is routed to and whether the fan-out
+// is keyed at all. Unkeyed fan-outs deliver to every output.
+func (c *fanOutCore[T]) route(event T, chans []chan<- T, live func(int) bool) (target int, keyed bool) {
+	if c.router == nil {
+		return -1, false
+	}
+	return c.router(event, chans, live), true
+}
+
+// WithFanOutControlBuffer lets up to n Add/Remove commands queue up without
+// blocking the caller while the fan-out is busy. The default is 1.
+func WithFanOutControlBuffer[T any](n int) FanOutOption[T] {
+	return func(c *fanOutCore[T]) {
+		c.controlBuffer = n
+	}
+}
+
+// WithFanOutDropPolicy sets what happens when an output cannot accept an
+// event immediately. See [DropPolicy]; the default is BlockWhenFull.
+func WithFanOutDropPolicy[T any](policy DropPolicy) FanOutOption[T] {
+	return func(c *fanOutCore[T]) {
+		c.dropPolicy = policy
+	}
+}
+
+// WithFanOutDeadlockDetection warns when a BlockWhenFull delivery to an
+// output stays blocked for longer than timeout. See "Deadlock detection" in
+// the README.
+func WithFanOutDeadlockDetection[T any](timeout time.Duration) FanOutOption[T] {
+	return func(c *fanOutCore[T]) {
+		c.deadlockTimeout = timeout
+	}
+}
+
+// stopForContext reports ctx.Err() on the closed channel. Called by the
+// fan-out loops just before returning because the context is done.
+func (c *fanOutCore[T]) stopForContext() {
+	c.setErr(c.contextErr())
+	c.closedChan <- c.contextErr()
+}
+
 // applyOpts applies common functional options to the core.
 func applyOpts[T any](c *fanOutCore[T], opts []FanOutOption[T]) {
 	for _, opt := range opts {
@@ -1,5 +1,7 @@
 package gocurrent
 
+import "sync"
+
 // AsyncFanOut distributes events to all registered output channels by
 // spawning a separate goroutine for each output on every event.
 //
@@ -32,6 +34,10 @@ package gocurrent
 // [QueuedFanOut] is a better choice.
 type AsyncFanOut[T any] struct {
 	fanOutCore[T]
+
+	// pending tracks the in-flight deliveries to each output. Only the
+	// fan-out goroutine touches the map.
+	pending map[chan<- T]*sync.WaitGroup
 }
 
 // NewAsyncFanOut creates an AsyncFanOut that spawns a goroutine per output
@@ -40,6 +46,7 @@ type AsyncFanOut[T any] struct {
 // Options:
 //   - [WithFanOutInputChan]: use an existing input channel (caller-owned)
 //   - [WithFanOutInputBuffer]: create a buffered input channel
+//   - [WithFanOutContext]: stop automatically when a context is done
 //
 // Example:
 //
@@ -49,9 +56,9 @@ type AsyncFanOut[T any] struct {
 //	fo.Send(42)
 //	val := <-out // 42
 func NewAsyncFanOut[T any](opts ...FanOutOption[T]) *AsyncFanOut[T] {
-	fo := &AsyncFanOut[T]{}
+	fo := &AsyncFanOut[T]{pending: map[chan<- T]*sync.WaitGroup{}}
 	applyOpts(&fo.fanOutCore, opts)
-	fo.initCore()
+	fo.initCore("AsyncFanOut")
 	fo.start()
 	return fo
 }
@@ -65,23 +72,70 @@ func (fo *AsyncFanOut[T]) start() {
 		for {
 			select {
 			case event := <-fo.inputChan:
+				fo.inputCount.Add(1)
+				target, keyed := fo.route(event, fo.outputChans, nil)
 				for index, outputChan := range fo.outputChans {
-					if outputChan == nil {
+					if outputChan == nil || (keyed && index != target) {
 						continue
 					}
 					if fo.outputFilters[index] != nil {
 						if newevent := fo.outputFilters[index](&event); newevent != nil {
-							go func(ch chan<- T, evt T) { ch <- evt }(outputChan, *newevent)
+							fo.deliverAsync(outputChan, *newevent)
 						}
 					} else {
-						go func(ch chan<- T, evt T) { ch <- evt }(outputChan, event)
+						fo.deliverAsync(outputChan, event)
 					}
 				}
 			case cmd := <-fo.controlChan:
 				if fo.handleCmd(cmd) {
 					return
 				}
+			case <-fo.ctxDone():
+				fo.stopForContext()
+				return
 			}
 		}
 	}()
 }
+
+// deliverAsync delivers v to ch on a new goroutine tracked by deliveries, so
+// Stop waits for it before closing owned outputs, and by the output's
+// pending group, so Remove does too.
+func (fo *AsyncFanOut[T]) deliverAsync(ch chan<- T, v T) {
+	pending := fo.pending[ch]
+	if pending == nil {
+		pending = &sync.WaitGroup{}
+		fo.pending[ch] = pending
+	}
+	fo.deliveries.Add(1)
+	pending.Add(1)
+	go func() {
+		defer fo.deliveries.Done()
+		defer pending.Done()
+		fo.deliver(ch, v, nil)
+	}()
+}
+
+// handleCmd wraps the core handleCmd. Removing a self-owned output closes it
+// only once the deliveries already in flight to it have completed, instead
+// of immediately, so they never send on a closed channel.
+func (fo *AsyncFanOut[T]) handleCmd(cmd fanOutCmd[T]) (shouldStop bool) {
+	if cmd.Name == "remove" {
+		pending := fo.pending[cmd.RemovedChannel]
+		delete(fo.pending, cmd.RemovedChannel)
+		for index, ch := range fo.outputChans {
+			if ch == cmd.RemovedChannel && fo.outputSelfOwned[index] && pending != nil {
+				// Close it here rather than in the core handleCmd.
+				fo.outputSelfOwned[index] = false
+				fo.deliveries.Add(1)
+				go func() {
+					defer fo.deliveries.Done()
+					pending.Wait()
+					close(ch)
+				}()
+				break
+			}
+		}
+	}
+	return fo.fanOutCore.handleCmd(cmd)
+}
@@ -54,8 +54,8 @@ func TestAsyncFanOut_Basic(t *testing.T) {
 }
 
 // TestAsyncFanOut_AddRemoveFilter verifies Add, Remove, New, and per-output
-// filters work correctly with AsyncFanOut. Self-owned channels are closed
-// immediately on Remove (same as SyncFanOut).
+// filters work correctly with AsyncFanOut. Self-owned channels are closed on
+// Remove once their in-flight deliveries complete.
 func TestAsyncFanOut_AddRemoveFilter(t *testing.T) {
 	fanout := NewAsyncFanOut[int]()
 	defer fanout.Stop()
@@ -79,7 +79,28 @@ func TestAsyncFanOut_AddRemoveFilter(t *testing.T) {
 	fanout.Send(7)
 	assert.Equal(t, 7, <-out1)
 
-	// AsyncFanOut closes self-owned channels immediately on Remove
+	// AsyncFanOut closes self-owned channels on Remove once no delivery to
+	// them is in flight
 	_, ok := <-out2
 	assert.False(t, ok, "out2 should be closed after Remove")
 }
+
+// TestAsyncFanOut_RemoveWithPendingDeliveries verifies that removing a
+// self-owned output with deliveries still in flight closes it only after
+// they complete, instead of panicking with a send on a closed channel.
+func TestAsyncFanOut_RemoveWithPendingDeliveries(t *testing.T) {
+	fanout := NewAsyncFanOut[int]()
+	defer fanout.Stop()
+
+	out := fanout.New(nil)
+	for i := 0; i < 5; i++ {
+		fanout.Send(i)
+	}
+	<-fanout.Remove(out, true)
+
+	var got []int
+	for v := range out {
+		got = append(got, v)
+	}
+	assert.ElementsMatch(t, []int{0, 1, 2, 3, 4}, got)
+}
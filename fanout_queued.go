@@ -1,9 +1,6 @@
 package gocurrent
 
-import (
-	"log"
-	"sync"
-)
+import "sync"
 
 // DefaultQueueSize is the default capacity of the dispatch queue used by
 // [QueuedFanOut]. The queue acts as a buffer between the runner goroutine
@@ -74,6 +71,7 @@ type QueuedFanOut[T any] struct {
 	stopDispatch     chan struct{} // closed by runner to unblock dispatch sends
 	snapshot         outputSnapshot[T]
 	queueSize        int
+	parallelism      int         // see WithParallelDelivery
 	removed          sync.Map    // chan<- T → struct{}: channels removed but maybe in old snapshots
 	removedSelfOwned []chan<- T  // self-owned removed channels, closed during cleanup
 }
@@ -90,6 +88,19 @@ func WithQueueSize[T any](size int) QueuedFanOutOption[T] {
 	}
 }
 
+// WithParallelDelivery delivers each broadcast to up to n outputs at once
+// instead of one after another, so an output that is slow to accept an event
+// does not delay the outputs after it. The broadcast still completes before
+// the next event is dispatched, which keeps every output in FIFO order, and
+// at most n delivery goroutines run at any time. n <= 1 (the default) keeps
+// sequential delivery. Keyed fan-outs, which deliver each event to a single
+// output, are unaffected.
+func WithParallelDelivery[T any](n int) QueuedFanOutOption[T] {
+	return func(fo *QueuedFanOut[T]) {
+		fo.parallelism = n
+	}
+}
+
 // NewQueuedFanOut creates a QueuedFanOut that delivers events via a
 // persistent dispatch goroutine with strict FIFO ordering. The fan-out
 // starts running immediately.
@@ -97,7 +108,9 @@ func WithQueueSize[T any](size int) QueuedFanOutOption[T] {
 // Accepts both common [FanOutOption] and [QueuedFanOutOption] options:
 //   - [WithFanOutInputChan]: use an existing input channel (caller-owned)
 //   - [WithFanOutInputBuffer]: create a buffered input channel
+//   - [WithFanOutContext]: stop automatically when a context is done
 //   - [WithQueueSize]: set the dispatch queue capacity (default 64)
+//   - [WithParallelDelivery]: deliver each event to several outputs at once
 //
 // Example:
 //
@@ -120,7 +133,7 @@ func NewQueuedFanOut[T any](opts ...any) *QueuedFanOut[T] {
 		}
 	}
 
-	fo.initCore()
+	fo.initCore("QueuedFanOut")
 	fo.dispatchChan = make(chan dispatchItem[T], fo.queueSize)
 	fo.dispatchDone = make(chan struct{})
 	fo.stopDispatch = make(chan struct{})
@@ -138,6 +151,7 @@ func (fo *QueuedFanOut[T]) DebugInfo() any {
 		"queueLen":      len(fo.dispatchChan),
 		"queueCap":      cap(fo.dispatchChan),
 		"snapshotChans": len(fo.snapshot.chans),
+		"stats":         fo.Stats(),
 	}
 }
 
@@ -164,11 +178,12 @@ func (fo *QueuedFanOut[T]) handleCmd(cmd fanOutCmd[T]) (shouldStop bool) {
 		for _, oc := range fo.outputChans {
 			if oc == cmd.AddedChannel {
 				found = true
-				log.Println("Output Channel already exists. Will skip.", cmd.AddedChannel)
+				logf("Output Channel already exists. Will skip. %v", cmd.AddedChannel)
 				break
 			}
 		}
 		if !found {
+			fo.counters.Store(cmd.AddedChannel, &outputCounters{})
 			fo.outputChans = append(fo.outputChans, cmd.AddedChannel)
 			fo.outputSelfOwned = append(fo.outputSelfOwned, cmd.SelfOwned)
 			fo.outputFilters = append(fo.outputFilters, cmd.Filter)
@@ -182,6 +197,7 @@ func (fo *QueuedFanOut[T]) handleCmd(cmd fanOutCmd[T]) (shouldStop bool) {
 			if ch == cmd.RemovedChannel {
 				// Mark as removed so dispatch goroutine skips it in old snapshots
 				fo.removed.Store(ch, struct{}{})
+				fo.counters.Delete(ch)
 				if fo.outputSelfOwned[index] {
 					fo.removedSelfOwned = append(fo.removedSelfOwned, ch)
 				}
@@ -217,6 +233,20 @@ func (fo *QueuedFanOut[T]) enqueue(item dispatchItem[T]) bool {
 				return false
 			}
 			item.snapshot = fo.snapshot
+		case <-fo.ctxDone():
+			fo.stopForContext()
+			return false
+		}
+	}
+}
+
+// deliverBroadcast delivers val to one output of a broadcast. If the
+// fan-out is stopped meanwhile, the delivery is still completed unless the
+// output was removed since the snapshot, as its reader may be gone.
+func (fo *QueuedFanOut[T]) deliverBroadcast(outputChan chan<- T, val T, stop <-chan struct{}) {
+	if !fo.deliver(outputChan, val, stop) {
+		if _, removed := fo.removed.Load(outputChan); !removed {
+			fo.deliver(outputChan, val, nil)
 		}
 	}
 }
@@ -226,15 +256,35 @@ func (fo *QueuedFanOut[T]) start() {
 
 	// Dispatch goroutine — reads from dispatchChan and delivers to outputs.
 	// Checks the removed set before each send to skip outputs removed after
-	// the snapshot was taken. Every send uses a select on Done() so that
-	// Stop() can interrupt a blocked delivery (e.g., when a removed output's
-	// reader has stopped but the dispatch goroutine was already mid-send).
+	// the snapshot was taken. On Stop() the broadcast in progress is finished
+	// (a blocked send to a removed output is abandoned, since its reader may
+	// be gone) and events still queued are discarded.
 	go func() {
 		defer close(fo.dispatchDone)
 		stop := fo.stopDispatch
+		var slots chan struct{} // bounds parallel deliveries, if enabled
+		if fo.parallelism > 1 {
+			slots = make(chan struct{}, fo.parallelism)
+		}
+		var inFlight sync.WaitGroup
 		for item := range fo.dispatchChan {
-			for index, outputChan := range item.snapshot.chans {
-				if outputChan == nil {
+			// Events still queued when Stop is called are discarded.
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			chans := item.snapshot.chans
+			target, keyed := -1, fo.router != nil
+			if keyed {
+				// Never route to an output removed after the snapshot.
+				target = fo.router(item.event, chans, func(index int) bool {
+					_, removed := fo.removed.Load(chans[index])
+					return !removed
+				})
+			}
+			for index, outputChan := range chans {
+				if outputChan == nil || (keyed && index != target) {
 					continue
 				}
 				if _, removed := fo.removed.Load(outputChan); removed {
@@ -250,12 +300,21 @@ func (fo *QueuedFanOut[T]) start() {
 				} else {
 					val = item.event
 				}
-				select {
-				case outputChan <- val:
-				case <-stop:
-					return
+				if slots == nil || keyed {
+					fo.deliverBroadcast(outputChan, val, stop)
+					continue
 				}
+				slots <- struct{}{}
+				inFlight.Add(1)
+				go func() {
+					defer func() {
+						<-slots
+						inFlight.Done()
+					}()
+					fo.deliverBroadcast(outputChan, val, stop)
+				}()
 			}
+			inFlight.Wait()
 		}
 	}()
 
@@ -276,6 +335,7 @@ func (fo *QueuedFanOut[T]) start() {
 		for {
 			select {
 			case event := <-fo.inputChan:
+				fo.inputCount.Add(1)
 				item := dispatchItem[T]{
 					snapshot: fo.snapshot,
 					event:    event,
@@ -287,6 +347,9 @@ func (fo *QueuedFanOut[T]) start() {
 				if fo.handleCmd(cmd) {
 					return
 				}
+			case <-fo.ctxDone():
+				fo.stopForContext()
+				return
 			}
 		}
 	}()
@@ -377,3 +377,27 @@ func TestQueuedFanOut_Filter(t *testing.T) {
 	case <-time.After(50 * time.Millisecond):
 	}
 }
+
+func TestQueuedFanOut_ParallelDelivery(t *testing.T) {
+	b := NewBroadcast[int]("events", WithParallelDelivery[int](4))
+	defer b.Stop()
+
+	slow := b.AddOutput(nil) // added first, and not read for a while
+	fast1 := b.AddOutput(nil)
+	fast2 := b.AddOutput(nil)
+
+	// Event 0 fills the slow output's buffer; event 1 then blocks on it.
+	for i := 0; i < 2; i++ {
+		b.Send(i)
+		assert.Equal(t, i, withTimeout(t, fast1))
+		assert.Equal(t, i, withTimeout(t, fast2))
+	}
+
+	// The slow output still gets every event, in order.
+	assert.Equal(t, 0, withTimeout(t, slow))
+	assert.Equal(t, 1, withTimeout(t, slow))
+	b.Send(2)
+	for _, out := range []chan int{fast1, fast2, slow} {
+		assert.Equal(t, 2, withTimeout(t, out))
+	}
+}
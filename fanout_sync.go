@@ -32,6 +32,7 @@ type SyncFanOut[T any] struct {
 // Options:
 //   - [WithFanOutInputChan]: use an existing input channel (caller-owned)
 //   - [WithFanOutInputBuffer]: create a buffered input channel
+//   - [WithFanOutContext]: stop automatically when a context is done
 //
 // Example:
 //
@@ -43,7 +44,7 @@ type SyncFanOut[T any] struct {
 func NewSyncFanOut[T any](opts ...FanOutOption[T]) *SyncFanOut[T] {
 	fo := &SyncFanOut[T]{}
 	applyOpts(&fo.fanOutCore, opts)
-	fo.initCore()
+	fo.initCore("SyncFanOut")
 	fo.start()
 	return fo
 }
@@ -57,22 +58,27 @@ func (fo *SyncFanOut[T]) start() {
 		for {
 			select {
 			case event := <-fo.inputChan:
+				fo.inputCount.Add(1)
+				target, keyed := fo.route(event, fo.outputChans, nil)
 				for index, outputChan := range fo.outputChans {
-					if outputChan == nil {
+					if outputChan == nil || (keyed && index != target) {
 						continue
 					}
 					if fo.outputFilters[index] != nil {
 						if newevent := fo.outputFilters[index](&event); newevent != nil {
-							outputChan <- *newevent
+							fo.deliver(outputChan, *newevent, nil)
 						}
 					} else {
-						outputChan <- event
+						fo.deliver(outputChan, event, nil)
 					}
 				}
 			case cmd := <-fo.controlChan:
 				if fo.handleCmd(cmd) {
 					return
 				}
+			case <-fo.ctxDone():
+				fo.stopForContext()
+				return
 			}
 		}
 	}()
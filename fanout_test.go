@@ -2,6 +2,7 @@ package gocurrent
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -52,3 +53,170 @@ func TestFanOut_WithInputChan(t *testing.T) {
 	ch <- 99
 	assert.Equal(t, 99, <-ch)
 }
+
+// keyedOwners sends n events keyed by v%keys through fo and returns, for each
+// key, the set of output indices it was delivered to.
+func keyedOwners(t *testing.T, fo FanOuter[int], outs []chan int, n, keys int) map[int]map[int]bool {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		fo.Send(i)
+	}
+	owners := map[int]map[int]bool{}
+	deadline := time.Now().Add(testTimeout)
+	for received := 0; received < n; {
+		got := false
+		for index, out := range outs {
+			select {
+			case v := <-out:
+				if owners[v%keys] == nil {
+					owners[v%keys] = map[int]bool{}
+				}
+				owners[v%keys][index] = true
+				received++
+				got = true
+			default:
+			}
+		}
+		if !got {
+			if time.Now().After(deadline) {
+				t.Fatalf("Timeout: received %d of %d events", received, n)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+	return owners
+}
+
+// TestFanOut_Keyed verifies that a keyed fan-out delivers every event exactly
+// once and always routes the same key to the same output, for all strategies.
+func TestFanOut_Keyed(t *testing.T) {
+	keyFn := func(v int) int { return v % 10 }
+	for name, fo := range map[string]FanOuter[int]{
+		"Sync":   NewSyncFanOut(Keyed(keyFn)),
+		"Async":  NewAsyncFanOut(Keyed(keyFn)),
+		"Queued": NewQueuedFanOut[int](Keyed(keyFn)),
+	} {
+		t.Run(name, func(t *testing.T) {
+			defer fo.Stop()
+			var outs []chan int
+			for i := 0; i < 4; i++ {
+				out := make(chan int, 500)
+				<-fo.Add(out, nil, true)
+				outs = append(outs, out)
+			}
+			owners := keyedOwners(t, fo, outs, 500, 10)
+			assert.Len(t, owners, 10)
+			for key, idx := range owners {
+				assert.Len(t, idx, 1, "key %d reached more than one output", key)
+			}
+		})
+	}
+}
+
+// TestFanOut_KeyedRebalance verifies that removing an output only moves the
+// keys that output owned.
+func TestFanOut_KeyedRebalance(t *testing.T) {
+	fo := NewSyncFanOut(Keyed(func(v int) int { return v % 50 }))
+	defer fo.Stop()
+	var outs []chan int
+	for i := 0; i < 4; i++ {
+		out := make(chan int, 200)
+		<-fo.Add(out, nil, true)
+		outs = append(outs, out)
+	}
+	before := keyedOwners(t, fo, outs, 200, 50)
+
+	<-fo.Remove(outs[0], true)
+	after := keyedOwners(t, fo, outs, 200, 50)
+	for key, idx := range before {
+		if idx[0] {
+			assert.False(t, after[key][0], "key %d still routed to the removed output", key)
+			continue
+		}
+		assert.Equal(t, idx, after[key], "key %d moved although its output remained", key)
+	}
+}
+
+// TestFanOut_StatsDropNewest verifies per-output counts under DropNewest: a
+// slow output drops events while a fast one receives all of them.
+func TestFanOut_StatsDropNewest(t *testing.T) {
+	fo := NewQueuedFanOut[int](WithFanOutDropPolicy[int](DropNewest))
+	defer fo.Stop()
+
+	slow := make(chan int, 1) // never read
+	fast := make(chan int, 100)
+	<-fo.Add(slow, nil, true)
+	<-fo.Add(fast, nil, true)
+
+	for i := 0; i < 20; i++ {
+		fo.Send(i)
+	}
+	for i := 0; i < 20; i++ {
+		select {
+		case v := <-fast:
+			assert.Equal(t, i, v)
+		case <-time.After(testTimeout):
+			t.Fatal("Timeout waiting for fast output")
+		}
+	}
+
+	assert.Eventually(t, func() bool {
+		s := fo.Stats().Outputs[slow]
+		return s.Delivered+s.Dropped == 20
+	}, testTimeout, time.Millisecond)
+	stats := fo.Stats()
+	assert.Equal(t, int64(20), stats.Input)
+	assert.Equal(t, OutputStats{Delivered: 20}, stats.Outputs[fast])
+	assert.Equal(t, int64(1), stats.Outputs[slow].Delivered)
+	assert.Equal(t, int64(19), stats.Outputs[slow].Dropped)
+
+	<-fo.Remove(slow, true)
+	_, tracked := fo.Stats().Outputs[slow]
+	assert.False(t, tracked, "Removed outputs should not be reported")
+}
+
+// TestFanOut_StopClosesOutputs verifies that Stop delivers the pending
+// broadcast, closes every New() output so range loops over them terminate,
+// and then signals ClosedChan(), for all strategies.
+func TestFanOut_StopClosesOutputs(t *testing.T) {
+	for name, fo := range map[string]FanOuter[int]{
+		"Sync":   NewSyncFanOut[int](),
+		"Async":  NewAsyncFanOut[int](),
+		"Queued": NewQueuedFanOut[int](),
+	} {
+		t.Run(name, func(t *testing.T) {
+			const numOutputs = 3
+			counts := make(chan int, numOutputs)
+			for i := 0; i < numOutputs; i++ {
+				out := fo.New(nil)
+				go func() {
+					n := 0
+					for range out {
+						n++
+					}
+					counts <- n
+				}()
+			}
+			for i := 0; i < 10; i++ {
+				fo.Send(i)
+			}
+			fo.Stop()
+
+			for i := 0; i < numOutputs; i++ {
+				select {
+				case n := <-counts:
+					assert.LessOrEqual(t, n, 10)
+				case <-time.After(5 * time.Second):
+					t.Fatal("range over output did not terminate after Stop")
+				}
+			}
+			select {
+			case <-fo.ClosedChan():
+			case <-time.After(5 * time.Second):
+				t.Fatal("ClosedChan not signalled after Stop")
+			}
+			// Sends after Stop are ignored rather than blocking or panicking.
+			fo.Send(99)
+		})
+	}
+}
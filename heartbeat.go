@@ -0,0 +1,121 @@
+package gocurrent
+
+import (
+	"context"
+	"time"
+)
+
+// Heartbeat emits a value built by a tick function at a fixed interval, e.g.
+// to inject keepalives into a FanIn-merged stream. A tick the consumer is not
+// ready for is delayed, not queued: a slow consumer receives fewer ticks
+// rather than a burst of stale ones.
+type Heartbeat[T any] struct {
+	RunnerBase[string]
+	interval   time.Duration
+	tick       func(time.Time) T
+	output     chan<- T
+	ownedOut   chan T
+	closedChan chan error
+	clock      Clock
+}
+
+// HeartbeatOption is a functional option for configuring a Heartbeat.
+type HeartbeatOption[T any] func(*Heartbeat[T])
+
+// WithHeartbeatContext ties the heartbeat's lifetime to ctx. When ctx is done
+// the heartbeat stops itself and ClosedChan() receives ctx.Err().
+func WithHeartbeatContext[T any](ctx context.Context) HeartbeatOption[T] {
+	return func(h *Heartbeat[T]) {
+		h.ctx = ctx
+	}
+}
+
+// WithHeartbeatClock sets the clock pacing the ticks, e.g. a FakeClock in
+// tests. The default is RealClock.
+func WithHeartbeatClock[T any](clock Clock) HeartbeatOption[T] {
+	return func(h *Heartbeat[T]) {
+		h.clock = clock
+	}
+}
+
+// NewHeartbeat creates a heartbeat sending tick(now) to output every
+// interval. If output is nil the heartbeat creates its own channel, available
+// via OutputChan() and closed on Stop; a caller-provided output is not
+// closed.
+//
+// Example:
+//
+//	hb := NewHeartbeat(30*time.Second, func(t time.Time) Event {
+//	    return Event{Kind: "keepalive", At: t}
+//	}, nil)
+//	defer hb.Stop()
+//	fanin.Add(hb.OutputChan())
+func NewHeartbeat[T any](interval time.Duration, tick func(time.Time) T, output chan<- T, opts ...HeartbeatOption[T]) *Heartbeat[T] {
+	out := &Heartbeat[T]{
+		RunnerBase: NewRunnerBase("stop"),
+		interval:   interval,
+		tick:       tick,
+		output:     output,
+		closedChan: make(chan error, 1),
+	}
+	out.component = "Heartbeat"
+	if output == nil {
+		out.ownedOut = make(chan T)
+		out.output = out.ownedOut
+	}
+	for _, opt := range opts {
+		opt(out)
+	}
+	out.clock = clockOr(out.clock)
+	out.start()
+	return out
+}
+
+// OutputChan returns the channel the heartbeat created when given a nil
+// output, or nil if the caller provided the output.
+func (h *Heartbeat[T]) OutputChan() <-chan T {
+	return h.ownedOut
+}
+
+// ClosedChan returns the channel used to signal when the heartbeat is done.
+func (h *Heartbeat[T]) ClosedChan() <-chan error {
+	return h.closedChan
+}
+
+func (h *Heartbeat[T]) cleanup() {
+	if h.ownedOut != nil {
+		close(h.ownedOut)
+	}
+	close(h.closedChan)
+	h.RunnerBase.cleanup()
+}
+
+func (h *Heartbeat[T]) start() {
+	h.RunnerBase.start()
+	go func() {
+		defer h.cleanup()
+		ticker := h.clock.NewTicker(h.interval)
+		defer ticker.Stop()
+		for {
+			var now time.Time
+			select {
+			case <-h.controlChan:
+				return
+			case <-h.ctxDone():
+				h.setErr(h.contextErr())
+				h.closedChan <- h.contextErr()
+				return
+			case now = <-ticker.C():
+			}
+			select {
+			case h.output <- h.tick(now):
+			case <-h.controlChan:
+				return
+			case <-h.ctxDone():
+				h.setErr(h.contextErr())
+				h.closedChan <- h.contextErr()
+				return
+			}
+		}
+	}()
+}
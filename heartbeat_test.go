@@ -0,0 +1,58 @@
+package gocurrent
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHeartbeatTicks(t *testing.T) {
+	hb := NewHeartbeat(10*time.Millisecond, func(now time.Time) time.Time { return now }, nil)
+
+	count := 0
+	var last time.Time
+	window := time.After(200 * time.Millisecond)
+loop:
+	for {
+		select {
+		case tick := <-hb.OutputChan():
+			assert.True(t, tick.After(last), "ticks should carry increasing times")
+			last = tick
+			count++
+		case <-window:
+			break loop
+		}
+	}
+	// Nominally 20 ticks; allow for a loaded scheduler.
+	assert.GreaterOrEqual(t, count, 5)
+	assert.LessOrEqual(t, count, 21)
+
+	hb.Stop()
+	assert.False(t, hb.IsRunning())
+	for range hb.OutputChan() {
+		// drain until closed
+	}
+	withTimeout(t, hb.ClosedChan())
+}
+
+func TestHeartbeatCallerOutput(t *testing.T) {
+	out := make(chan int, 1)
+	hb := NewHeartbeat(5*time.Millisecond, func(time.Time) int { return 1 }, out)
+	assert.Nil(t, hb.OutputChan())
+	assert.Equal(t, 1, withTimeout(t, (<-chan int)(out)))
+
+	// Stop must not hang while the heartbeat waits on a full output, and
+	// must leave the caller's channel open.
+	time.Sleep(20 * time.Millisecond)
+	hb.Stop()
+	select {
+	case <-out:
+	default:
+	}
+	select {
+	case out <- 2:
+	default:
+		t.Fatal("caller-provided output should stay open and usable")
+	}
+}
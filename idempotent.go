@@ -0,0 +1,141 @@
+package gocurrent
+
+import (
+	"container/list"
+	"sync"
+)
+
+// DefaultIdempotencyCapacity is the number of keys remembered by the default
+// in-memory store used by NewIdempotent.
+const DefaultIdempotencyCapacity = 10000
+
+// IdempotencyStore records which idempotency keys have already been
+// processed. Implementations must be safe for concurrent use; they may be
+// backed by an external system (Redis, a database, ...) so that duplicates
+// are suppressed across restarts or processes.
+type IdempotencyStore[K comparable] interface {
+	// Seen reports whether key has already been processed.
+	Seen(key K) bool
+
+	// Mark records key as processed.
+	Mark(key K)
+}
+
+// LRUIdempotencyStore is a bounded in-memory IdempotencyStore. Once capacity
+// keys are stored, marking a new key evicts the least recently used one, so a
+// duplicate arriving after its key was evicted is treated as new.
+type LRUIdempotencyStore[K comparable] struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List // front = most recently used
+	items    map[K]*list.Element
+}
+
+// NewLRUIdempotencyStore creates an in-memory store remembering at most
+// capacity keys. A capacity <= 0 uses DefaultIdempotencyCapacity.
+func NewLRUIdempotencyStore[K comparable](capacity int) *LRUIdempotencyStore[K] {
+	if capacity <= 0 {
+		capacity = DefaultIdempotencyCapacity
+	}
+	return &LRUIdempotencyStore[K]{
+		capacity: capacity,
+		order:    list.New(),
+		items:    make(map[K]*list.Element),
+	}
+}
+
+// Seen reports whether key is in the store, refreshing its recency if so.
+func (s *LRUIdempotencyStore[K]) Seen(key K) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e, ok := s.items[key]; ok {
+		s.order.MoveToFront(e)
+		return true
+	}
+	return false
+}
+
+// Mark adds key to the store, evicting the least recently used key if the
+// store is full.
+func (s *LRUIdempotencyStore[K]) Mark(key K) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e, ok := s.items[key]; ok {
+		s.order.MoveToFront(e)
+		return
+	}
+	s.items[key] = s.order.PushFront(key)
+	for s.order.Len() > s.capacity {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.items, oldest.Value.(K))
+	}
+}
+
+// Len returns the number of keys currently stored.
+func (s *LRUIdempotencyStore[K]) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.order.Len()
+}
+
+// Idempotent is a pipeline stage that forwards each value from input to
+// output at most once per idempotency key. It is intended for consuming
+// at-least-once sources where redeliveries must not be processed twice.
+//
+// A key is marked in the store just before its value is forwarded. The value
+// is never dropped: if the stage is stopped while blocked sending it, Stop
+// waits until a consumer reads it.
+type Idempotent[T any, K comparable] struct {
+	*Mapper[T, T]
+	keyFunc func(T) K
+	store   IdempotencyStore[K]
+}
+
+// IdempotentOption is a functional option for configuring an Idempotent stage.
+type IdempotentOption[T any, K comparable] func(*Idempotent[T, K])
+
+// WithIdempotencyStore sets the store used to remember processed keys.
+// Defaults to an LRUIdempotencyStore holding DefaultIdempotencyCapacity keys.
+func WithIdempotencyStore[T any, K comparable](store IdempotencyStore[K]) IdempotentOption[T, K] {
+	return func(i *Idempotent[T, K]) {
+		i.store = store
+	}
+}
+
+// NewIdempotent creates a stage that reads from input and forwards to output
+// every value whose keyFunc(value) has not been processed before. As with
+// NewMapper, the channels are owned by the caller.
+//
+// Example:
+//
+//	stage := NewIdempotent(events, deduped, func(e Event) string { return e.ID },
+//	    WithIdempotencyStore[Event, string](redisStore))
+//	defer stage.Stop()
+func NewIdempotent[T any, K comparable](input <-chan T, output chan<- T, keyFunc func(T) K, opts ...IdempotentOption[T, K]) *Idempotent[T, K] {
+	out := &Idempotent[T, K]{
+		keyFunc: keyFunc,
+	}
+	for _, opt := range opts {
+		opt(out)
+	}
+	if out.store == nil {
+		out.store = NewLRUIdempotencyStore[K](DefaultIdempotencyCapacity)
+	}
+	out.Mapper = NewMapper(input, output, out.apply)
+	return out
+}
+
+// Store returns the store used to remember processed keys.
+func (i *Idempotent[T, K]) Store() IdempotencyStore[K] {
+	return i.store
+}
+
+func (i *Idempotent[T, K]) apply(value T) (T, bool, bool) {
+	key := i.keyFunc(value)
+	if i.store.Seen(key) {
+		return value, true, false
+	}
+	i.store.Mark(key)
+	return value, false, false
+}
@@ -0,0 +1,62 @@
+package gocurrent
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type idempotentEvent struct {
+	ID      string
+	Payload int
+}
+
+func TestIdempotentForwardsEachKeyOnce(t *testing.T) {
+	input := make(chan idempotentEvent)
+	output := make(chan idempotentEvent, 100)
+	stage := NewIdempotent(input, output, func(e idempotentEvent) string { return e.ID })
+	defer stage.Stop()
+
+	// Replay every event three times, as an at-least-once source might
+	ids := []string{"a", "b", "c", "d"}
+	for round := 0; round < 3; round++ {
+		for i, id := range ids {
+			input <- idempotentEvent{ID: id, Payload: round*10 + i}
+		}
+	}
+	close(input)
+	<-stage.ClosedChan()
+	close(output)
+
+	var got []idempotentEvent
+	for e := range output {
+		got = append(got, e)
+	}
+	assert.Equal(t, []idempotentEvent{{"a", 0}, {"b", 1}, {"c", 2}, {"d", 3}}, got,
+		"Only the first delivery of each key should be forwarded")
+}
+
+func TestIdempotentBoundedStoreEvicts(t *testing.T) {
+	store := NewLRUIdempotencyStore[int](2)
+	input := make(chan int)
+	output := make(chan int, 100)
+	stage := NewIdempotent(input, output, func(v int) int { return v },
+		WithIdempotencyStore[int, int](store))
+	assert.Same(t, store, stage.Store())
+
+	for _, v := range []int{1, 2, 1, 3, 1, 2} {
+		input <- v
+	}
+	close(input)
+	<-stage.ClosedChan()
+	close(output)
+
+	var got []int
+	for v := range output {
+		got = append(got, v)
+	}
+	// 1 and 2 are marked; the duplicate 1 refreshes it; 3 evicts 2; so the
+	// third 1 is suppressed while the late 2 is forwarded again.
+	assert.Equal(t, []int{1, 2, 3, 2}, got)
+	assert.Equal(t, 2, store.Len(), "Store should never exceed its capacity")
+}
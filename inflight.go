@@ -0,0 +1,108 @@
+package gocurrent
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// InFlightLimiter bounds the total number of items being processed across
+// several components at once. It is a counting semaphore shared between
+// stages: a component acquires a slot when it accepts an item and releases
+// it once the item has been handed off downstream, dropped, or failed, so
+// slots never leak.
+//
+// Unlike per-channel buffers, which bound each hop separately, a shared
+// limiter bounds the work held by the whole pipeline. Items sitting in a
+// buffered channel between two stages are not counted; use unbuffered
+// channels between limited stages for a strict bound.
+//
+// Components opt in with WithReaderInFlightLimiter,
+// WithMapperInFlightLimiter and WithWriterInFlightLimiter. A nil limiter
+// imposes no limit.
+type InFlightLimiter struct {
+	slots chan struct{}
+	peak  atomic.Int64
+}
+
+// NewInFlightLimiter creates a limiter allowing at most limit items in
+// flight. A limit below 1 is treated as 1.
+func NewInFlightLimiter(limit int) *InFlightLimiter {
+	if limit < 1 {
+		limit = 1
+	}
+	return &InFlightLimiter{slots: make(chan struct{}, limit)}
+}
+
+// Acquire blocks until a slot is free or ctx is done, returning ctx.Err() in
+// the latter case. Every successful Acquire must be paired with a Release.
+func (l *InFlightLimiter) Acquire(ctx context.Context) error {
+	select {
+	case l.slots <- struct{}{}:
+		l.observe()
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// TryAcquire takes a slot if one is free without blocking.
+func (l *InFlightLimiter) TryAcquire() bool {
+	select {
+	case l.slots <- struct{}{}:
+		l.observe()
+		return true
+	default:
+		return false
+	}
+}
+
+// Release returns a slot taken by Acquire or TryAcquire.
+func (l *InFlightLimiter) Release() {
+	if l == nil {
+		return
+	}
+	<-l.slots
+}
+
+// InFlight returns the number of slots currently held.
+func (l *InFlightLimiter) InFlight() int {
+	return len(l.slots)
+}
+
+// Peak returns the highest number of slots held at once so far.
+func (l *InFlightLimiter) Peak() int {
+	return int(l.peak.Load())
+}
+
+// Limit returns the maximum number of items allowed in flight.
+func (l *InFlightLimiter) Limit() int {
+	return cap(l.slots)
+}
+
+func (l *InFlightLimiter) observe() {
+	n := int64(len(l.slots))
+	for {
+		p := l.peak.Load()
+		if n <= p || l.peak.CompareAndSwap(p, n) {
+			return
+		}
+	}
+}
+
+// acquireOr blocks until a slot is free, or returns false if cancel yields
+// first. A nil limiter always succeeds immediately. Used by components that
+// must keep honoring their stop signal while waiting.
+func acquireOr[C any](l *InFlightLimiter, cancel <-chan C, ctxDone <-chan struct{}) bool {
+	if l == nil {
+		return true
+	}
+	select {
+	case l.slots <- struct{}{}:
+		l.observe()
+		return true
+	case <-cancel:
+		return false
+	case <-ctxDone:
+		return false
+	}
+}
@@ -0,0 +1,101 @@
+package gocurrent
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInFlightLimiterAcrossReaderAndMappers(t *testing.T) {
+	const limit = 3
+	limiter := NewInFlightLimiter(limit)
+
+	var active, maxActive atomic.Int32
+	enter := func() {
+		n := active.Add(1)
+		for {
+			m := maxActive.Load()
+			if n <= m || maxActive.CompareAndSwap(m, n) {
+				break
+			}
+		}
+	}
+
+	var next atomic.Int32
+	reader := NewReader(func() (int, error) {
+		enter()
+		defer active.Add(-1)
+		return int(next.Add(1)), nil
+	}, WithReaderInFlightLimiter[int](limiter))
+
+	output := make(chan int, 1000)
+	var mappers []*Mapper[Message[int], int]
+	for i := 0; i < 5; i++ {
+		mappers = append(mappers, NewMapper(reader.OutputChan(), output,
+			func(msg Message[int]) (int, bool, bool) {
+				enter()
+				defer active.Add(-1)
+				time.Sleep(2 * time.Millisecond)
+				return msg.Value, false, false
+			}, WithMapperInFlightLimiter[Message[int], int](limiter)))
+	}
+
+	for i := 0; i < 100; i++ {
+		withTimeout(t, output)
+	}
+	reader.Stop()
+	for _, m := range mappers {
+		m.Stop()
+	}
+
+	assert.LessOrEqual(t, maxActive.Load(), int32(limit), "No more than limit items should be in flight")
+	assert.LessOrEqual(t, limiter.Peak(), limit)
+	assert.Eventually(t, func() bool { return limiter.InFlight() == 0 },
+		time.Second, 5*time.Millisecond, "All slots should be released after stop")
+}
+
+func TestInFlightLimiterReleasesSkippedItems(t *testing.T) {
+	limiter := NewInFlightLimiter(1)
+	input := make(chan int)
+	output := make(chan int, 10)
+	mapper := NewMapper(input, output, func(v int) (int, bool, bool) {
+		return v, v%2 == 1, false
+	}, WithMapperInFlightLimiter[int, int](limiter))
+	defer mapper.Stop()
+
+	for i := 0; i < 10; i++ {
+		input <- i
+	}
+	for _, want := range []int{0, 2, 4, 6, 8} {
+		assert.Equal(t, want, withTimeout(t, output))
+	}
+	assert.Eventually(t, func() bool { return limiter.InFlight() == 0 },
+		time.Second, 5*time.Millisecond, "Skipped items must not leak slots")
+}
+
+func TestInFlightLimiterStopWhileWaiting(t *testing.T) {
+	limiter := NewInFlightLimiter(1)
+	assert.True(t, limiter.TryAcquire())
+	assert.False(t, limiter.TryAcquire())
+
+	input := make(chan int, 1)
+	output := make(chan int, 1)
+	mapper := NewMapper(input, output, idMapperFunc[int],
+		WithMapperInFlightLimiter[int, int](limiter))
+	input <- 1
+
+	// The mapper is blocked waiting for the held slot; Stop must still work
+	stopped := make(chan error, 1)
+	go func() { stopped <- mapper.Stop() }()
+	withTimeout(t, stopped)
+	assert.Equal(t, 1, limiter.InFlight())
+
+	limiter.Release()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.NoError(t, limiter.Acquire(ctx))
+	assert.ErrorIs(t, limiter.Acquire(ctx), context.DeadlineExceeded)
+}
@@ -0,0 +1,46 @@
+package gocurrent
+
+import "io"
+
+// NewIOWriter creates a Writer that encodes each value with encode and
+// writes the bytes to w. All writes happen on the Writer's single goroutine,
+// so many producers can share one io.Writer (a file, socket or buffer)
+// without their output interleaving. WithWriterConcurrency is therefore
+// ignored.
+//
+// Short writes are retried until every byte of an encoded value has been
+// written. An encode or write error stops the Writer and is delivered on
+// ClosedChan().
+//
+// Example:
+//
+//	logw := NewIOWriter(file, func(e Event) ([]byte, error) {
+//	    b, err := json.Marshal(e)
+//	    return append(b, '\n'), err
+//	})
+//	defer logw.Stop()
+//	logw.Send(Event{...}) // safe from any goroutine
+func NewIOWriter[W any](w io.Writer, encode func(W) ([]byte, error), opts ...WriterOption[W]) *Writer[W] {
+	return NewWriter(func(value W) error {
+		buf, err := encode(value)
+		if err != nil {
+			return err
+		}
+		return writeFull(w, buf)
+	}, append(opts[:len(opts):len(opts)], WithWriterConcurrency[W](1))...)
+}
+
+// writeFull writes all of buf to w, looping over short writes.
+func writeFull(w io.Writer, buf []byte) error {
+	for len(buf) > 0 {
+		n, err := w.Write(buf)
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return io.ErrShortWrite
+		}
+		buf = buf[n:]
+	}
+	return nil
+}
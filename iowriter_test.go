@@ -0,0 +1,79 @@
+package gocurrent
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// shortWriter accepts at most max bytes per Write call.
+type shortWriter struct {
+	bytes.Buffer
+	max int
+}
+
+func (s *shortWriter) Write(p []byte) (int, error) {
+	if len(p) > s.max {
+		p = p[:s.max]
+	}
+	return s.Buffer.Write(p)
+}
+
+func TestIOWriterNoInterleaving(t *testing.T) {
+	t.Run("default", func(t *testing.T) { testIOWriterNoInterleaving(t) })
+	// Concurrent writes would interleave, so the option is ignored.
+	t.Run("concurrency", func(t *testing.T) {
+		testIOWriterNoInterleaving(t, WithWriterConcurrency[string](4))
+	})
+}
+
+func testIOWriterNoInterleaving(t *testing.T, opts ...WriterOption[string]) {
+	// Tiny writes force every line to be written in several pieces
+	out := &shortWriter{max: 3}
+	w := NewIOWriter(out, func(s string) ([]byte, error) {
+		return []byte(s + "\n"), nil
+	}, opts...)
+
+	const producers, perProducer = 10, 50
+	var wg sync.WaitGroup
+	for p := 0; p < producers; p++ {
+		wg.Add(1)
+		go func(p int) {
+			defer wg.Done()
+			for i := 0; i < perProducer; i++ {
+				assert.True(t, w.Send(fmt.Sprintf("producer-%02d-line-%03d", p, i)))
+			}
+		}(p)
+	}
+	wg.Wait()
+	w.Stop()
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	assert.Len(t, lines, producers*perProducer)
+	for _, line := range lines {
+		var p, i int
+		_, err := fmt.Sscanf(line, "producer-%02d-line-%03d", &p, &i)
+		assert.NoError(t, err, "Corrupted line %q", line)
+	}
+}
+
+func TestIOWriterEncodeError(t *testing.T) {
+	var out bytes.Buffer
+	encodeErr := errors.New("cannot encode")
+	w := NewIOWriter(&out, func(v int) ([]byte, error) {
+		if v < 0 {
+			return nil, encodeErr
+		}
+		return []byte(fmt.Sprint(v)), nil
+	})
+
+	w.Send(1)
+	w.Send(-1)
+	assert.ErrorIs(t, withTimeout(t, w.ClosedChan()), encodeErr)
+	assert.Equal(t, "1", out.String())
+}
@@ -79,6 +79,44 @@ func TestFanInClosedChan(t *testing.T) {
 	}
 }
 
+// TestReaderDone verifies that a Reader's Done channel closes after Stop,
+// even while Read is blocked.
+func TestReaderDone(t *testing.T) {
+	block := make(chan int)
+	reader := NewReader(func() (int, error) { return <-block, nil })
+	defer close(block)
+
+	select {
+	case <-reader.Done():
+		t.Fatal("Done closed before Stop")
+	default:
+	}
+	reader.Stop()
+	select {
+	case <-reader.Done():
+	case <-time.After(time.Second):
+		t.Fatal("Timeout waiting for Reader Done")
+	}
+}
+
+// TestFanInDone verifies that a FanIn's Done channel closes after Stop.
+func TestFanInDone(t *testing.T) {
+	fanin := NewFanIn[int]()
+	fanin.Add(make(chan int))
+
+	select {
+	case <-fanin.Done():
+		t.Fatal("Done closed before Stop")
+	default:
+	}
+	fanin.Stop()
+	select {
+	case <-fanin.Done():
+	case <-time.After(time.Second):
+		t.Fatal("Timeout waiting for FanIn Done")
+	}
+}
+
 // TestFanOutClosedChan verifies that QueuedFanOut signals completion via ClosedChan
 func TestFanOutClosedChan(t *testing.T) {
 	fanout := NewQueuedFanOut[int]()
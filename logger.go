@@ -0,0 +1,45 @@
+package gocurrent
+
+import "sync/atomic"
+
+// Logger is the minimal logging interface used by gocurrent primitives for
+// internal diagnostics. *log.Logger satisfies it.
+type Logger interface {
+	Printf(format string, v ...any)
+}
+
+// NopLogger discards everything. It is the default package logger.
+var NopLogger Logger = nopLogger{}
+
+type nopLogger struct{}
+
+func (nopLogger) Printf(string, ...any) {}
+
+// loggerBox lets an interface value be stored in an atomic.Pointer.
+type loggerBox struct{ Logger }
+
+var packageLogger atomic.Pointer[loggerBox]
+
+// SetLogger sets the logger used for internal diagnostics by primitives that
+// have not been given their own Logger via an option. Pass log.Default() to
+// restore the chatty pre-logger behavior, or nil to silence logging again.
+// It is safe to call concurrently with running primitives.
+func SetLogger(l Logger) {
+	if l == nil {
+		l = NopLogger
+	}
+	packageLogger.Store(&loggerBox{l})
+}
+
+// defaultLogger returns the package logger set via SetLogger, or NopLogger.
+func defaultLogger() Logger {
+	if b := packageLogger.Load(); b != nil {
+		return b.Logger
+	}
+	return NopLogger
+}
+
+// logf writes an internal diagnostic to the package logger.
+func logf(format string, v ...any) {
+	defaultLogger().Printf(format, v...)
+}
@@ -0,0 +1,65 @@
+package gocurrent
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// exerciseLogging drives code paths that emit internal diagnostics: a FanIn
+// removal and a Writer failing and cleaning up.
+func exerciseLogging(t *testing.T, opts ...FanInOption[int]) {
+	t.Helper()
+	removed := make(chan struct{})
+	opts = append(opts, WithFanInOnChannelRemoved(func(*FanIn[int], <-chan int) { close(removed) }))
+	fanin := NewFanIn(opts...)
+	ch := make(chan int)
+	fanin.Add(ch)
+	fanin.Remove(ch)
+	<-removed
+	fanin.Stop()
+
+	writer := NewWriter(func(int) error { return errors.New("boom") })
+	writer.Send(1)
+	select {
+	case <-writer.ClosedChan():
+	case <-time.After(time.Second):
+		t.Fatal("Timeout waiting for writer to fail")
+	}
+	writer.Stop()
+}
+
+func TestDefaultLoggerIsSilent(t *testing.T) {
+	var buf bytes.Buffer
+	origOut := log.Writer()
+	log.SetOutput(&buf)
+	defer log.SetOutput(origOut)
+
+	exerciseLogging(t)
+	assert.Empty(t, buf.String(), "No diagnostics should be written by default")
+}
+
+func TestSetLogger(t *testing.T) {
+	logger := &captureLogger{}
+	SetLogger(logger)
+	defer SetLogger(nil)
+
+	exerciseLogging(t)
+	assert.True(t, logger.contains("Removing channel"))
+	assert.True(t, logger.contains("Write Error: boom"))
+}
+
+func TestFanInLoggerOverridesPackageLogger(t *testing.T) {
+	pkg := &captureLogger{}
+	SetLogger(pkg)
+	defer SetLogger(nil)
+
+	own := &captureLogger{}
+	exerciseLogging(t, WithFanInLogger[int](own))
+	assert.True(t, own.contains("Removing channel"))
+	assert.False(t, pkg.contains("Removing channel"))
+}
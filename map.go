@@ -0,0 +1,470 @@
+package gocurrent
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// Map is a generic map guarded by a sync.RWMutex. Unlike [SyncMap], which is
+// tuned for read-mostly workloads with stable keys, Map suits general
+// read/write use and supports operations that need a consistent view of the
+// whole map, such as point-in-time copies.
+//
+// The zero value is an empty map ready to use. Use NewMap for options such as
+// WithTTL. A Map must not be copied after first use.
+type Map[K comparable, V any] struct {
+	mu    sync.RWMutex
+	items map[K]V
+
+	// Expiry, only used when ttl > 0. expiresAt holds each key's deadline.
+	ttl           time.Duration
+	sweepInterval time.Duration
+	expiresAt     map[K]time.Time
+	sweepStop     chan struct{}
+	sweepDone     chan struct{}
+	stopOnce      sync.Once
+	clock         Clock
+
+	// Mutation hooks (see WithOnSet, WithOnDelete and WithOnEvict)
+	onSet    func(key K, old, value V, existed bool)
+	onDelete func(key K, value V)
+	onEvict  func(key K, value V)
+}
+
+// mapEvent is a mutation recorded under the lock for the hooks, which run
+// after it is released.
+type mapEvent[K comparable, V any] struct {
+	kind       mapEventKind
+	key        K
+	old, value V
+	existed    bool
+}
+
+type mapEventKind int
+
+const (
+	mapSet mapEventKind = iota
+	mapDelete
+	mapEvict
+)
+
+// MapOption is a functional option for configuring a Map.
+type MapOption[K comparable, V any] func(*Map[K, V])
+
+// WithTTL makes entries expire ttl after they were last stored. Expired
+// entries are treated as absent by every operation and deleted lazily when
+// Get finds them. A background sweeper (see WithSweepInterval) also reclaims
+// expired entries that are never read again; stop it with Stop.
+func WithTTL[K comparable, V any](ttl time.Duration) MapOption[K, V] {
+	return func(m *Map[K, V]) {
+		m.ttl = ttl
+	}
+}
+
+// WithSweepInterval sets how often the sweeper of a map with a TTL deletes
+// expired entries. Defaults to the TTL.
+func WithSweepInterval[K comparable, V any](d time.Duration) MapOption[K, V] {
+	return func(m *Map[K, V]) {
+		m.sweepInterval = d
+	}
+}
+
+// WithMapClock sets the clock used to expire entries and pace the sweeper,
+// e.g. a FakeClock in tests. The default is RealClock.
+func WithMapClock[K comparable, V any](clock Clock) MapOption[K, V] {
+	return func(m *Map[K, V]) {
+		m.clock = clock
+	}
+}
+
+// WithOnSet installs a hook called whenever a value is stored for a key, by
+// any operation, with the previous value if existed is true. A key whose
+// entry had expired is reported as evicted (see WithOnEvict) and then set
+// with existed false.
+//
+// Like the other hooks it is called after the mutation, once the map's lock
+// has been released, on the goroutine that made the change. It may therefore
+// use the map without deadlocking, but hooks for concurrent mutations can
+// run concurrently and out of order, and the map may have changed again by
+// the time a hook runs.
+func WithOnSet[K comparable, V any](fn func(key K, old, value V, existed bool)) MapOption[K, V] {
+	return func(m *Map[K, V]) {
+		m.onSet = fn
+	}
+}
+
+// WithOnDelete installs a hook called with the removed value whenever Delete
+// removes a key, or FromMap with replace discards one. See WithOnSet for
+// when hooks run.
+func WithOnDelete[K comparable, V any](fn func(key K, value V)) MapOption[K, V] {
+	return func(m *Map[K, V]) {
+		m.onDelete = fn
+	}
+}
+
+// WithOnEvict installs a hook called with the expired value whenever an
+// entry of a map with a TTL is found expired and deleted, whether by the
+// sweeper or by an operation on its key. See WithOnSet for when hooks run.
+func WithOnEvict[K comparable, V any](fn func(key K, value V)) MapOption[K, V] {
+	return func(m *Map[K, V]) {
+		m.onEvict = fn
+	}
+}
+
+// NewMap creates a Map with options. With WithTTL the map runs a sweeper
+// goroutine until Stop is called.
+//
+// Example:
+//
+//	sessions := NewMap[string, *Session](WithTTL[string, *Session](30 * time.Minute))
+//	defer sessions.Stop()
+func NewMap[K comparable, V any](opts ...MapOption[K, V]) *Map[K, V] {
+	m := &Map[K, V]{}
+	for _, opt := range opts {
+		opt(m)
+	}
+	if m.ttl > 0 {
+		if m.sweepInterval <= 0 {
+			m.sweepInterval = m.ttl
+		}
+		m.sweepStop = make(chan struct{})
+		m.sweepDone = make(chan struct{})
+		go m.sweep()
+	}
+	return m
+}
+
+// expired reports whether key has outlived its TTL. Must hold mu.
+func (m *Map[K, V]) expired(key K, now time.Time) bool {
+	return m.ttl > 0 && !now.Before(m.expiresAt[key])
+}
+
+// store sets key under the write lock, recording its deadline and the
+// mutation in events.
+func (m *Map[K, V]) store(key K, value V, events *[]mapEvent[K, V]) {
+	if m.items == nil {
+		m.items = make(map[K]V)
+	}
+	old, existed := m.items[key]
+	if existed && m.ttl > 0 && m.expired(key, m.now()) {
+		m.record(events, mapEvent[K, V]{kind: mapEvict, key: key, value: old})
+		var zero V
+		old, existed = zero, false
+	}
+	m.record(events, mapEvent[K, V]{kind: mapSet, key: key, old: old, value: value, existed: existed})
+	m.items[key] = value
+	if m.ttl > 0 {
+		if m.expiresAt == nil {
+			m.expiresAt = make(map[K]time.Time)
+		}
+		m.expiresAt[key] = m.now().Add(m.ttl)
+	}
+}
+
+// remove deletes key under the write lock.
+func (m *Map[K, V]) remove(key K) {
+	delete(m.items, key)
+	delete(m.expiresAt, key)
+}
+
+// record appends e to events if a hook is installed for its kind.
+func (m *Map[K, V]) record(events *[]mapEvent[K, V], e mapEvent[K, V]) {
+	switch {
+	case e.kind == mapSet && m.onSet != nil,
+		e.kind == mapDelete && m.onDelete != nil,
+		e.kind == mapEvict && m.onEvict != nil:
+		*events = append(*events, e)
+	}
+}
+
+// hooked reports whether any mutation hook is installed.
+func (m *Map[K, V]) hooked() bool {
+	return m.onSet != nil || m.onDelete != nil || m.onEvict != nil
+}
+
+// notify calls the hooks for events. It must be called without holding mu.
+func (m *Map[K, V]) notify(events *[]mapEvent[K, V]) {
+	for _, e := range *events {
+		switch e.kind {
+		case mapSet:
+			m.onSet(e.key, e.old, e.value, e.existed)
+		case mapDelete:
+			m.onDelete(e.key, e.value)
+		case mapEvict:
+			m.onEvict(e.key, e.value)
+		}
+	}
+}
+
+// Get returns the value stored for key. The ok result reports whether the
+// key was present.
+func (m *Map[K, V]) Get(key K) (value V, ok bool) {
+	m.mu.RLock()
+	value, ok = m.items[key]
+	if !ok || !m.expired(key, m.now()) {
+		m.mu.RUnlock()
+		return
+	}
+	m.mu.RUnlock()
+
+	// Lazily delete the expired entry, unless it was refreshed meanwhile.
+	var events []mapEvent[K, V]
+	defer m.notify(&events)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.loadOrEvict(key, &events)
+}
+
+// loadOrEvict is load that also deletes an expired entry, recording the
+// eviction in events. Must hold the write lock.
+func (m *Map[K, V]) loadOrEvict(key K, events *[]mapEvent[K, V]) (value V, ok bool) {
+	value, ok = m.items[key]
+	if ok && m.expired(key, m.now()) {
+		m.remove(key)
+		m.record(events, mapEvent[K, V]{kind: mapEvict, key: key, value: value})
+		var zero V
+		return zero, false
+	}
+	return value, ok
+}
+
+// Set stores value for key.
+func (m *Map[K, V]) Set(key K, value V) {
+	var events []mapEvent[K, V]
+	defer m.notify(&events)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.store(key, value, &events)
+}
+
+// LoadOrStore returns the existing value for key if present. Otherwise it
+// stores value and returns it. The loaded result is true if the value was
+// loaded, false if stored.
+func (m *Map[K, V]) LoadOrStore(key K, value V) (actual V, loaded bool) {
+	var events []mapEvent[K, V]
+	defer m.notify(&events)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if v, ok := m.loadOrEvict(key, &events); ok {
+		return v, true
+	}
+	m.store(key, value, &events)
+	return value, false
+}
+
+// GetOrCompute returns the value for key, computing and storing it with fn if
+// the key is absent. fn runs at most once per missing key, even when many
+// goroutines ask for the same key concurrently.
+//
+// fn runs while holding the map's write lock: every other operation on the
+// map, for any key, blocks until it returns. Keep fn fast, and never call
+// methods of the same map from it (that deadlocks). For slow computations,
+// store a placeholder (e.g. a sync.OnceValue) instead.
+func (m *Map[K, V]) GetOrCompute(key K, fn func() V) V {
+	if v, ok := m.Get(key); ok {
+		return v
+	}
+	var events []mapEvent[K, V]
+	defer m.notify(&events)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	// Re-check: another goroutine may have computed it while we waited.
+	if v, ok := m.loadOrEvict(key, &events); ok {
+		return v
+	}
+	v := fn()
+	m.store(key, v, &events)
+	return v
+}
+
+// Update atomically replaces the value for key with fn(old, ok), where ok
+// reports whether key was present, and returns the stored result. fn runs
+// under the write lock, so concurrent Updates of the same key never lose
+// writes. As with GetOrCompute, keep fn fast and do not call the map from it.
+//
+//	counts.Update(word, func(n int, _ bool) int { return n + 1 })
+func (m *Map[K, V]) Update(key K, fn func(old V, ok bool) V) V {
+	var events []mapEvent[K, V]
+	defer m.notify(&events)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	old, ok := m.loadOrEvict(key, &events)
+	v := fn(old, ok)
+	m.store(key, v, &events)
+	return v
+}
+
+// Delete removes key from the map. Deleting an absent key is a no-op.
+func (m *Map[K, V]) Delete(key K) {
+	var events []mapEvent[K, V]
+	defer m.notify(&events)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if value, ok := m.loadOrEvict(key, &events); ok {
+		m.remove(key)
+		m.record(&events, mapEvent[K, V]{kind: mapDelete, key: key, value: value})
+	}
+}
+
+// Len returns the number of entries in the map.
+func (m *Map[K, V]) Len() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.ttl <= 0 {
+		return len(m.items)
+	}
+	n := 0
+	now := m.now()
+	for k := range m.items {
+		if !m.expired(k, now) {
+			n++
+		}
+	}
+	return n
+}
+
+// Range calls fn for each entry until fn returns false. It iterates over a
+// point-in-time snapshot taken under the read lock, so it is safe against
+// concurrent writers and fn may itself modify the map without deadlocking.
+// Changes made during iteration are not reflected in it. Order is
+// unspecified.
+func (m *Map[K, V]) Range(fn func(key K, value V) bool) {
+	for k, v := range m.ToMap() {
+		if !fn(k, v) {
+			return
+		}
+	}
+}
+
+// ToMap returns a copy of the map's contents as a plain map. The copy is
+// taken under the read lock, so it is a consistent point-in-time snapshot:
+// it reflects every write that completed before ToMap and none that started
+// after. Later changes to either map do not affect the other.
+func (m *Map[K, V]) ToMap() map[K]V {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := make(map[K]V, len(m.items))
+	now := m.now()
+	for k, v := range m.items {
+		if !m.expired(k, now) {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+// Snapshot returns a point-in-time copy of the map as a plain map, taken
+// under the read lock. It is the same as ToMap and reads better at call
+// sites such as debug endpoints.
+func (m *Map[K, V]) Snapshot() map[K]V {
+	return m.ToMap()
+}
+
+// MarshalJSON encodes a snapshot of the map as a JSON object, holding the
+// read lock only while copying. K and V must be JSON-compatible (string,
+// integer or encoding.TextMarshaler keys).
+func (m *Map[K, V]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(m.ToMap())
+}
+
+// UnmarshalJSON replaces the map's contents with the decoded JSON object.
+// On error the map is left unchanged.
+func (m *Map[K, V]) UnmarshalJSON(data []byte) error {
+	var src map[K]V
+	if err := json.Unmarshal(data, &src); err != nil {
+		return err
+	}
+	m.FromMap(src, true)
+	return nil
+}
+
+// FromMap bulk-loads the entries of src under a single write lock, so
+// concurrent readers observe either none or all of them. If replace is true
+// the existing contents are discarded first; otherwise src is merged in,
+// overwriting existing values for keys present in both. src is copied and
+// may be modified afterwards.
+func (m *Map[K, V]) FromMap(src map[K]V, replace bool) {
+	var events []mapEvent[K, V]
+	defer m.notify(&events)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if replace && m.hooked() {
+		// Remove entries one by one so the hooks see them go. Live keys
+		// present in src stay and are reported as overwritten by store.
+		now := m.now()
+		for k, v := range m.items {
+			if m.expired(k, now) {
+				m.record(&events, mapEvent[K, V]{kind: mapEvict, key: k, value: v})
+			} else if _, kept := src[k]; kept {
+				continue
+			} else {
+				m.record(&events, mapEvent[K, V]{kind: mapDelete, key: k, value: v})
+			}
+			m.remove(k)
+		}
+	} else if replace || m.items == nil {
+		m.items = make(map[K]V, len(src))
+		m.expiresAt = nil
+	}
+	for k, v := range src {
+		m.store(k, v, &events)
+	}
+}
+
+// now returns the current time of the map's clock.
+func (m *Map[K, V]) now() time.Time {
+	return clockOr(m.clock).Now()
+}
+
+// sweep periodically deletes expired entries until Stop is called.
+func (m *Map[K, V]) sweep() {
+	defer close(m.sweepDone)
+	ticker := clockOr(m.clock).NewTicker(m.sweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-m.sweepStop:
+			return
+		case <-ticker.C():
+			var events []mapEvent[K, V]
+			m.mu.Lock()
+			now := m.now()
+			for k, v := range m.items {
+				if m.expired(k, now) {
+					m.remove(k)
+					m.record(&events, mapEvent[K, V]{kind: mapEvict, key: k, value: v})
+				}
+			}
+			m.mu.Unlock()
+			m.notify(&events)
+		}
+	}
+}
+
+// Stop stops the expiry sweeper, making Map a [Component]. The map remains
+// usable and entries still expire lazily. It is a no-op for maps without a
+// TTL and safe to call multiple times.
+func (m *Map[K, V]) Stop() error {
+	if m.sweepStop == nil {
+		return nil
+	}
+	m.stopOnce.Do(func() {
+		close(m.sweepStop)
+		<-m.sweepDone
+	})
+	return nil
+}
+
+// IsRunning reports whether the expiry sweeper is running.
+func (m *Map[K, V]) IsRunning() bool {
+	if m.sweepDone == nil {
+		return false
+	}
+	select {
+	case <-m.sweepDone:
+		return false
+	default:
+		return true
+	}
+}
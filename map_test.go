@@ -0,0 +1,434 @@
+package gocurrent
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMap_ToMapFromMapRoundTrip(t *testing.T) {
+	src := map[string]int{"a": 1, "b": 2, "c": 3}
+
+	var m Map[string, int]
+	m.FromMap(src, false)
+	assert.Equal(t, src, m.ToMap())
+
+	var copied Map[string, int]
+	copied.FromMap(m.ToMap(), true)
+	assert.Equal(t, src, copied.ToMap())
+
+	// The snapshot is detached from the map and vice versa.
+	snap := m.ToMap()
+	snap["a"] = 100
+	src["b"] = 200
+	v, _ := m.Get("a")
+	assert.Equal(t, 1, v)
+	v, _ = m.Get("b")
+	assert.Equal(t, 2, v)
+}
+
+func TestMap_FromMapMergeVsReplace(t *testing.T) {
+	var m Map[string, int]
+	m.Set("a", 1)
+	m.Set("b", 2)
+
+	m.FromMap(map[string]int{"b": 20, "c": 30}, false)
+	assert.Equal(t, map[string]int{"a": 1, "b": 20, "c": 30}, m.ToMap(), "Merge should keep existing keys")
+
+	m.FromMap(map[string]int{"z": 26}, true)
+	assert.Equal(t, map[string]int{"z": 26}, m.ToMap(), "Replace should discard existing keys")
+}
+
+// TestMap_ToMapConcurrent checks that snapshots taken while FromMap and Set
+// run concurrently are always internally consistent: a bulk load is observed
+// either entirely or not at all.
+// Run with: go test -race -run TestMap_ToMapConcurrent
+func TestMap_ToMapConcurrent(t *testing.T) {
+	var m Map[string, int]
+	batch := func(gen int) map[string]int {
+		out := make(map[string]int)
+		for i := 0; i < 50; i++ {
+			out[fmt.Sprintf("k%d", i)] = gen
+		}
+		return out
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for gen := 0; gen < 200; gen++ {
+			m.FromMap(batch(gen), true)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 200; i++ {
+			snap := m.ToMap()
+			if len(snap) == 0 {
+				continue
+			}
+			if len(snap) != 50 {
+				t.Errorf("Snapshot has %d entries, want 50", len(snap))
+				return
+			}
+			gen := snap["k0"]
+			for k, v := range snap {
+				if v != gen {
+					t.Errorf("Torn snapshot: %s=%d, k0=%d", k, v, gen)
+					return
+				}
+			}
+		}
+	}()
+	wg.Wait()
+}
+
+func TestMap_CoreAPI(t *testing.T) {
+	var m Map[string, int]
+	_, ok := m.Get("a")
+	assert.False(t, ok)
+	assert.Equal(t, 0, m.Len())
+	m.Delete("a") // no-op on an empty map
+
+	m.Set("a", 1)
+	m.Set("b", 2)
+	m.Set("a", 10)
+	assert.Equal(t, 2, m.Len())
+	v, ok := m.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 10, v)
+
+	m.Delete("a")
+	_, ok = m.Get("a")
+	assert.False(t, ok)
+	assert.Equal(t, 1, m.Len())
+}
+
+func TestMap_RangeEarlyStopAndMutation(t *testing.T) {
+	var m Map[int, int]
+	for i := 0; i < 10; i++ {
+		m.Set(i, i*i)
+	}
+
+	seen := 0
+	m.Range(func(k, v int) bool {
+		assert.Equal(t, k*k, v)
+		seen++
+		return seen < 3
+	})
+	assert.Equal(t, 3, seen, "Range should stop when fn returns false")
+
+	// fn may write to the map without deadlocking
+	m.Range(func(k, v int) bool {
+		m.Delete(k)
+		return true
+	})
+	assert.Equal(t, 0, m.Len())
+}
+
+func TestMap_ConcurrentReadersAndWriters(t *testing.T) {
+	var m Map[int, int]
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < 500; i++ {
+				key := w*1000 + i
+				m.Set(key, i)
+				if i%3 == 0 {
+					m.Delete(key)
+				}
+			}
+		}(w)
+	}
+	for r := 0; r < 8; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				m.Get(i)
+				m.Len()
+				m.Range(func(k, v int) bool { return true })
+			}
+		}()
+	}
+	wg.Wait()
+
+	// 500 keys per writer, every third deleted
+	assert.Equal(t, 8*(500-167), m.Len())
+}
+
+func TestMap_LoadOrStore(t *testing.T) {
+	var m Map[string, int]
+	actual, loaded := m.LoadOrStore("a", 1)
+	assert.False(t, loaded)
+	assert.Equal(t, 1, actual)
+
+	actual, loaded = m.LoadOrStore("a", 2)
+	assert.True(t, loaded)
+	assert.Equal(t, 1, actual, "Existing value should win")
+}
+
+func TestMap_GetOrComputeRunsOnce(t *testing.T) {
+	var m Map[string, int]
+	var calls atomic.Int32
+	start := make(chan struct{})
+	results := make([]int, 64)
+
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			results[i] = m.GetOrCompute("key", func() int {
+				calls.Add(1)
+				time.Sleep(5 * time.Millisecond)
+				return 42
+			})
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), calls.Load(), "fn should run exactly once under contention")
+	for _, r := range results {
+		assert.Equal(t, 42, r)
+	}
+}
+
+func TestMap_UpdateIsAtomic(t *testing.T) {
+	var m Map[string, int]
+	var wg sync.WaitGroup
+	for g := 0; g < 100; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				m.Update("counter", func(old int, _ bool) int { return old + 1 })
+			}
+		}()
+	}
+	wg.Wait()
+
+	v, _ := m.Get("counter")
+	assert.Equal(t, 100000, v)
+}
+
+func TestMap_UpdateReportsPresence(t *testing.T) {
+	var m Map[string, []string]
+	appendTo := func(s string) func([]string, bool) []string {
+		return func(old []string, ok bool) []string {
+			if !ok {
+				return []string{"first:" + s}
+			}
+			return append(old, s)
+		}
+	}
+	m.Update("k", appendTo("a"))
+	got := m.Update("k", appendTo("b"))
+	assert.Equal(t, []string{"first:a", "b"}, got)
+}
+
+// storedLen returns the number of entries physically held, expired or not.
+func storedLen[K comparable, V any](m *Map[K, V]) int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.items)
+}
+
+func TestMap_TTLLazyExpiryOnGet(t *testing.T) {
+	const ttl = 30 * time.Millisecond
+	// A long sweep interval so only lazy expiry is exercised
+	m := NewMap[string, int](WithTTL[string, int](ttl), WithSweepInterval[string, int](time.Hour))
+	defer m.Stop()
+
+	m.Set("a", 1)
+	m.Set("b", 2)
+	v, ok := m.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 1, v)
+
+	time.Sleep(2 * ttl)
+	_, ok = m.Get("a")
+	assert.False(t, ok, "Expired entry should be absent")
+	assert.Equal(t, 1, storedLen(m), "Get should delete the expired entry it found")
+	assert.Equal(t, 0, m.Len(), "Expired entries are not counted")
+	assert.Empty(t, m.ToMap())
+
+	// Storing again resets the deadline
+	m.Set("a", 3)
+	v, ok = m.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 3, v)
+	m.Update("b", func(old int, ok bool) int {
+		assert.False(t, ok, "Update should see expired entries as absent")
+		return 20
+	})
+}
+
+func TestMap_TTLSweeperReclaims(t *testing.T) {
+	const ttl = 20 * time.Millisecond
+	m := NewMap[int, int](WithTTL[int, int](ttl), WithSweepInterval[int, int](10*time.Millisecond))
+	assert.True(t, m.IsRunning())
+	var _ Component = m
+
+	for i := 0; i < 100; i++ {
+		m.Set(i, i)
+	}
+	assert.Eventually(t, func() bool { return storedLen(m) == 0 },
+		time.Second, 5*time.Millisecond, "Sweeper should reclaim never-read expired entries")
+
+	assert.NoError(t, m.Stop())
+	assert.False(t, m.IsRunning())
+	assert.NoError(t, m.Stop(), "Stop is idempotent")
+}
+
+func TestMap_Snapshot(t *testing.T) {
+	var m Map[string, int]
+	m.Set("a", 1)
+	snap := m.Snapshot()
+	m.Set("b", 2)
+	assert.Equal(t, map[string]int{"a": 1}, snap, "Snapshot should not see later writes")
+}
+
+func TestMap_JSONRoundTrip(t *testing.T) {
+	var m Map[string, int]
+	m.Set("a", 1)
+	m.Set("b", 2)
+
+	data, err := json.Marshal(&m)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"a":1,"b":2}`, string(data))
+
+	var decoded Map[string, int]
+	decoded.Set("stale", 99)
+	assert.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, m.ToMap(), decoded.ToMap(), "Unmarshal should replace the contents")
+
+	// Embedded in a response struct
+	type response struct {
+		Counts *Map[string, int] `json:"counts"`
+	}
+	data, err = json.Marshal(response{Counts: &m})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"counts":{"a":1,"b":2}}`, string(data))
+
+	assert.Error(t, json.Unmarshal([]byte(`{"a":"x"}`), &decoded))
+	assert.Equal(t, 2, decoded.Len(), "Failed unmarshal leaves the map unchanged")
+}
+
+func TestMap_MarshalWhileWriting(t *testing.T) {
+	var m Map[int, int]
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 1000; i++ {
+			m.Set(i, i)
+		}
+	}()
+	for i := 0; i < 50; i++ {
+		_, err := json.Marshal(&m)
+		assert.NoError(t, err)
+	}
+	wg.Wait()
+}
+
+// hookLog records Map hook calls as strings.
+type hookLog struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (l *hookLog) add(format string, args ...any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.events = append(l.events, fmt.Sprintf(format, args...))
+}
+
+func (l *hookLog) take() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	events := l.events
+	l.events = nil
+	return events
+}
+
+func TestMap_Hooks(t *testing.T) {
+	var log hookLog
+	var m *Map[string, int]
+	m = NewMap(
+		WithOnSet(func(k string, old, value int, existed bool) {
+			// Hooks run without the lock, so they may use the map.
+			current, _ := m.Get(k)
+			log.add("set %s %d->%d %v (now %d)", k, old, value, existed, current)
+		}),
+		WithOnDelete(func(k string, v int) { log.add("delete %s %d", k, v) }))
+
+	m.Set("a", 1)
+	m.Set("a", 2)
+	m.LoadOrStore("a", 3)
+	m.LoadOrStore("b", 4)
+	m.Update("b", func(old int, _ bool) int { return old + 1 })
+	m.GetOrCompute("c", func() int { return 6 })
+	assert.Equal(t, []string{
+		"set a 0->1 false (now 1)",
+		"set a 1->2 true (now 2)",
+		"set b 0->4 false (now 4)",
+		"set b 4->5 true (now 5)",
+		"set c 0->6 false (now 6)",
+	}, log.take())
+
+	m.Delete("a")
+	m.Delete("missing")
+	assert.Equal(t, []string{"delete a 2"}, log.take())
+
+	m.FromMap(map[string]int{"c": 7}, true)
+	assert.ElementsMatch(t, []string{"delete b 5", "set c 6->7 true (now 7)"}, log.take())
+	assert.Equal(t, map[string]int{"c": 7}, m.ToMap())
+}
+
+func TestMap_OnEvict(t *testing.T) {
+	var log hookLog
+	clock := NewFakeClock(time.Now())
+	m := NewMap(
+		WithTTL[string, int](time.Minute),
+		WithSweepInterval[string, int](time.Hour),
+		WithMapClock[string, int](clock),
+		WithOnSet(func(k string, old, value int, existed bool) {
+			log.add("set %s %d->%d %v", k, old, value, existed)
+		}),
+		WithOnEvict(func(k string, v int) { log.add("evict %s %d", k, v) }))
+	defer m.Stop()
+
+	m.Set("a", 1)
+	m.Set("b", 2)
+	m.Set("c", 3)
+	log.take()
+	clock.Advance(2 * time.Minute)
+
+	// Lazily, by an operation on the key
+	_, ok := m.Get("a")
+	assert.False(t, ok)
+	m.Set("b", 20)
+	assert.Equal(t, []string{"evict a 1", "evict b 2", "set b 0->20 false"}, log.take())
+
+	// By the sweeper
+	clock.BlockUntil(1)
+	clock.Advance(time.Hour)
+	var evicted []string
+	assert.Eventually(t, func() bool {
+		evicted = append(evicted, log.take()...)
+		return len(evicted) >= 2
+	}, time.Second, time.Millisecond)
+	assert.ElementsMatch(t, []string{"evict b 20", "evict c 3"}, evicted)
+	assert.Equal(t, 0, storedLen(m))
+}
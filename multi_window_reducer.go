@@ -0,0 +1,221 @@
+package gocurrent
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// reduceWindow is one named window of a MultiWindowReducer.
+type reduceWindow[T any] struct {
+	name   string
+	period time.Duration
+	items  []T
+	due    time.Time
+}
+
+// MultiWindowReducer reduces the same input stream over several windows at
+// once, e.g. 1s, 10s and 60s aggregates of one metric. Every input is
+// collected into each window, and each window is reduced and emitted on its
+// own schedule as a Message whose Source is the window name.
+//
+// All windows are driven by a single goroutine with a single timer that is
+// always armed for the earliest due window.
+type MultiWindowReducer[T any, U any] struct {
+	RunnerBase[string]
+	windows    []*reduceWindow[T]
+	reduce     func([]T) U
+	inputChan  chan T
+	outputChan chan Message[U]
+	closedChan chan error
+	clock      Clock
+}
+
+// MultiWindowReducerOption is a functional option for configuring a
+// MultiWindowReducer.
+type MultiWindowReducerOption[T any, U any] func(*MultiWindowReducer[T, U])
+
+// WithMultiWindowOutputBuffer gives the output channel a buffer of size
+// messages, so a slow consumer does not delay the other windows' flushes
+// until the buffer fills.
+func WithMultiWindowOutputBuffer[T any, U any](size int) MultiWindowReducerOption[T, U] {
+	return func(r *MultiWindowReducer[T, U]) {
+		r.outputChan = make(chan Message[U], size)
+	}
+}
+
+// WithMultiWindowContext ties the reducer's lifetime to ctx. When ctx is done
+// the reducer stops itself and ClosedChan() receives ctx.Err().
+func WithMultiWindowContext[T any, U any](ctx context.Context) MultiWindowReducerOption[T, U] {
+	return func(r *MultiWindowReducer[T, U]) {
+		r.ctx = ctx
+	}
+}
+
+// WithMultiWindowClock sets the clock timing the windows, e.g. a FakeClock
+// in tests. The default is RealClock.
+func WithMultiWindowClock[T any, U any](clock Clock) MultiWindowReducerOption[T, U] {
+	return func(r *MultiWindowReducer[T, U]) {
+		r.clock = clock
+	}
+}
+
+// NewMultiWindowReducer creates a reducer that collects every input into each
+// of the given windows, keyed by name. Every period, a window's items are
+// passed to reduce and the result is emitted as Message{Value, Source: name};
+// the window then starts empty. A window without items is still emitted,
+// with reduce(nil). Windows falling due together are emitted in name order.
+// The reducer owns its input and output channels and closes the output when
+// it stops; collected but unflushed items are discarded.
+//
+// Panics if windows is empty or a period is not positive.
+//
+// Example:
+//
+//	r := NewMultiWindowReducer(map[string]time.Duration{
+//	    "1s": time.Second, "10s": 10 * time.Second,
+//	}, func(latencies []time.Duration) int { return len(latencies) })
+//	defer r.Stop()
+//	for msg := range r.OutputChan() {
+//	    log.Printf("%v: %d requests", msg.Source, msg.Value)
+//	}
+func NewMultiWindowReducer[T any, U any](windows map[string]time.Duration, reduce func([]T) U, opts ...MultiWindowReducerOption[T, U]) *MultiWindowReducer[T, U] {
+	if len(windows) == 0 {
+		panic("NewMultiWindowReducer requires at least one window")
+	}
+	out := &MultiWindowReducer[T, U]{
+		RunnerBase: NewRunnerBase("stop"),
+		reduce:     reduce,
+		inputChan:  make(chan T),
+		closedChan: make(chan error, 1),
+	}
+	out.component = "MultiWindowReducer"
+	for name, period := range windows {
+		if period <= 0 {
+			panic("NewMultiWindowReducer requires positive window periods")
+		}
+		out.windows = append(out.windows, &reduceWindow[T]{name: name, period: period})
+	}
+	sort.Slice(out.windows, func(i, j int) bool { return out.windows[i].name < out.windows[j].name })
+	for _, opt := range opts {
+		opt(out)
+	}
+	if out.outputChan == nil {
+		out.outputChan = make(chan Message[U])
+	}
+	out.clock = clockOr(out.clock)
+	out.start()
+	return out
+}
+
+// InputChan returns the channel onto which values can be sent. Prefer Send,
+// which is safe to call after Stop.
+func (r *MultiWindowReducer[T, U]) InputChan() chan<- T {
+	return r.inputChan
+}
+
+// OutputChan returns the channel on which the reduced windows are emitted.
+func (r *MultiWindowReducer[T, U]) OutputChan() <-chan Message[U] {
+	return r.outputChan
+}
+
+// ClosedChan returns the channel used to signal when the reducer is done.
+func (r *MultiWindowReducer[T, U]) ClosedChan() <-chan error {
+	return r.closedChan
+}
+
+// Send adds value to every window. It returns false if the reducer is
+// stopped.
+func (r *MultiWindowReducer[T, U]) Send(value T) bool {
+	if !r.IsRunning() {
+		return false
+	}
+	select {
+	case r.inputChan <- value:
+		return true
+	case <-r.Done():
+		return false
+	}
+}
+
+func (r *MultiWindowReducer[T, U]) cleanup() {
+	close(r.outputChan)
+	close(r.closedChan)
+	r.RunnerBase.cleanup()
+}
+
+func (r *MultiWindowReducer[T, U]) start() {
+	r.RunnerBase.start()
+	go func() {
+		defer r.cleanup()
+		now := r.clock.Now()
+		for _, w := range r.windows {
+			w.due = now.Add(w.period)
+		}
+		timer := r.clock.NewTimer(r.untilNextDue())
+		defer timer.Stop()
+		for {
+			select {
+			case <-r.controlChan:
+				return
+			case <-r.ctxDone():
+				r.setErr(r.contextErr())
+				r.closedChan <- r.contextErr()
+				return
+			case value := <-r.inputChan:
+				for _, w := range r.windows {
+					w.items = append(w.items, value)
+				}
+			case now := <-timer.C():
+				if !r.flushDue(now) {
+					return
+				}
+				timer.Reset(r.untilNextDue())
+			}
+		}
+	}()
+}
+
+// nextDue returns the earliest time a window is due.
+func (r *MultiWindowReducer[T, U]) nextDue() time.Time {
+	next := r.windows[0].due
+	for _, w := range r.windows[1:] {
+		if w.due.Before(next) {
+			next = w.due
+		}
+	}
+	return next
+}
+
+// untilNextDue returns how long until the earliest window is due.
+func (r *MultiWindowReducer[T, U]) untilNextDue() time.Duration {
+	return r.nextDue().Sub(r.clock.Now())
+}
+
+// flushDue emits every window due at now and schedules its next flush. It
+// returns false if the reducer was stopped while emitting.
+func (r *MultiWindowReducer[T, U]) flushDue(now time.Time) bool {
+	for _, w := range r.windows {
+		if w.due.After(now) {
+			continue
+		}
+		msg := Message[U]{Value: r.reduce(w.items), Source: w.name}
+		w.items = nil
+		w.due = w.due.Add(w.period)
+		if !w.due.After(now) {
+			// Fell behind (e.g. a slow consumer): skip the missed ticks
+			// rather than emitting a burst of empty windows.
+			w.due = now.Add(w.period)
+		}
+		select {
+		case r.outputChan <- msg:
+		case <-r.controlChan:
+			return false
+		case <-r.ctxDone():
+			r.setErr(r.contextErr())
+			r.closedChan <- r.contextErr()
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,73 @@
+package gocurrent
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMultiWindowReducer(t *testing.T) {
+	r := NewMultiWindowReducer(map[string]time.Duration{
+		"fast": 100 * time.Millisecond,
+		"slow": 400 * time.Millisecond,
+	}, func(items []int) int { return len(items) })
+	defer r.Stop()
+
+	for i := range 3 {
+		assert.True(t, r.Send(i))
+	}
+
+	// Collect until the slow window has flushed twice.
+	counts := map[string][]int{}
+	for len(counts["slow"]) < 2 {
+		msg := withTimeout(t, r.OutputChan())
+		counts[msg.Source.(string)] = append(counts[msg.Source.(string)], msg.Value)
+	}
+
+	// Both windows saw the inputs; each started empty after its own flush.
+	assert.Equal(t, 3, counts["fast"][0])
+	assert.Equal(t, []int{3, 0}, counts["slow"])
+	assert.Equal(t, 0, counts["fast"][len(counts["fast"])-1])
+	// The fast window flushed on its own, much shorter schedule.
+	assert.GreaterOrEqual(t, len(counts["fast"]), 4)
+}
+
+func TestMultiWindowReducerStop(t *testing.T) {
+	r := NewMultiWindowReducer(map[string]time.Duration{"w": time.Hour},
+		func(items []int) int { return len(items) })
+	assert.True(t, r.Send(1))
+	r.Stop()
+
+	_, ok := <-r.OutputChan()
+	assert.False(t, ok)
+	assert.False(t, r.Send(2))
+	assert.NoError(t, r.Wait())
+}
+
+func TestMultiWindowReducerFakeClock(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	r := NewMultiWindowReducer(map[string]time.Duration{
+		"fast": time.Second,
+		"slow": 3 * time.Second,
+	}, func(items []int) int { return len(items) },
+		WithMultiWindowClock[int, int](clock))
+	defer r.Stop()
+
+	clock.BlockUntil(1)
+	assert.True(t, r.Send(1))
+	assert.True(t, r.Send(2))
+
+	var got []string
+	for range 3 {
+		clock.BlockUntil(1)
+		clock.Advance(time.Second)
+		msg := withTimeout(t, r.OutputChan())
+		got = append(got, fmt.Sprintf("%v=%d", msg.Source, msg.Value))
+	}
+	// Both windows fall due at 3s and are emitted in name order.
+	msg := withTimeout(t, r.OutputChan())
+	got = append(got, fmt.Sprintf("%v=%d", msg.Source, msg.Value))
+	assert.Equal(t, []string{"fast=2", "fast=0", "fast=0", "slow=2"}, got)
+}
@@ -0,0 +1,79 @@
+package gocurrent
+
+import "container/heap"
+
+// mergeItem is the current head of one input in an ordered merge.
+type mergeItem[T any] struct {
+	value T
+	input int
+}
+
+// mergeHeap is a min-heap of input heads ordered by less. Ties are broken
+// by input index so the merge is deterministic.
+type mergeHeap[T any] struct {
+	items []mergeItem[T]
+	less  func(a, b T) bool
+}
+
+func (h *mergeHeap[T]) Len() int { return len(h.items) }
+func (h *mergeHeap[T]) Less(i, j int) bool {
+	a, b := h.items[i], h.items[j]
+	if h.less(a.value, b.value) {
+		return true
+	}
+	if h.less(b.value, a.value) {
+		return false
+	}
+	return a.input < b.input
+}
+func (h *mergeHeap[T]) Swap(i, j int) { h.items[i], h.items[j] = h.items[j], h.items[i] }
+func (h *mergeHeap[T]) Push(x any)    { h.items = append(h.items, x.(mergeItem[T])) }
+func (h *mergeHeap[T]) Pop() any {
+	n := len(h.items)
+	item := h.items[n-1]
+	h.items = h.items[:n-1]
+	return item
+}
+
+// NewOrderedMerge performs a k-way merge of inputs that are each already
+// sorted by less, returning a single channel that yields all values in
+// globally sorted order. Unlike FanIn, which interleaves values as they
+// arrive, this waits until every open input has a value available before
+// emitting the smallest one, so a stalled input stalls the merge.
+//
+// Inputs may close independently; a closed input is dropped from the merge.
+// The returned channel is closed once every input has closed and all values
+// have been emitted. The merge stops only when its inputs close, so callers
+// should keep reading the output until then.
+//
+// Example:
+//
+//	merged := NewOrderedMerge(func(a, b LogLine) bool { return a.Time.Before(b.Time) },
+//	    serverA, serverB, serverC)
+//	for line := range merged {
+//	    fmt.Println(line)
+//	}
+func NewOrderedMerge[T any](less func(a, b T) bool, inputs ...<-chan T) <-chan T {
+	output := make(chan T)
+	go func() {
+		defer close(output)
+		h := &mergeHeap[T]{less: less}
+		for i, in := range inputs {
+			if v, ok := <-in; ok {
+				h.items = append(h.items, mergeItem[T]{value: v, input: i})
+			}
+		}
+		heap.Init(h)
+		for h.Len() > 0 {
+			head := h.items[0]
+			output <- head.value
+			if v, ok := <-inputs[head.input]; ok {
+				h.items[0].value = v
+				heap.Fix(h, 0)
+			} else {
+				heap.Pop(h)
+			}
+		}
+	}()
+	return output
+}
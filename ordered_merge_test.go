@@ -0,0 +1,43 @@
+package gocurrent
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func sendAndClose(ch chan int, values ...int) {
+	for _, v := range values {
+		ch <- v
+	}
+	close(ch)
+}
+
+func TestOrderedMerge(t *testing.T) {
+	a, b, c := make(chan int), make(chan int), make(chan int)
+	go sendAndClose(a, 1, 4, 7, 10)
+	go sendAndClose(b, 2, 5, 8)
+	go sendAndClose(c, 0, 3, 6, 9, 11, 12)
+
+	var got []int
+	for v := range NewOrderedMerge(func(x, y int) bool { return x < y }, a, b, c) {
+		got = append(got, v)
+	}
+	assert.Equal(t, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}, got)
+}
+
+func TestOrderedMergeEmptyAndDuplicateInputs(t *testing.T) {
+	a, b, c := make(chan int), make(chan int), make(chan int)
+	go sendAndClose(a)
+	go sendAndClose(b, 1, 1, 3)
+	go sendAndClose(c, 1, 2)
+
+	var got []int
+	for v := range NewOrderedMerge(func(x, y int) bool { return x < y }, a, b, c) {
+		got = append(got, v)
+	}
+	assert.Equal(t, []int{1, 1, 1, 2, 3}, got)
+
+	_, ok := <-NewOrderedMerge[int](func(x, y int) bool { return x < y })
+	assert.False(t, ok, "Merging no inputs yields a closed channel")
+}
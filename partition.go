@@ -0,0 +1,145 @@
+package gocurrent
+
+import (
+	"context"
+	"sync"
+)
+
+// Partitioner routes each value from input to one of several output
+// channels chosen by a classifier. Output channels are created lazily per
+// key, either when a value with a new key arrives or when Output is called
+// for it, so new keys can appear at runtime. It is the inverse of FanIn.
+//
+// Every output must be read: a value for a key whose output nobody reads
+// blocks the partitioner (use WithPartitionBuffer to absorb bursts). All
+// outputs are closed when the input closes or the partitioner is stopped.
+type Partitioner[T any, K comparable] struct {
+	RunnerBase[string]
+	input      <-chan T
+	classify   func(T) K
+	bufferSize int
+	mu         sync.Mutex
+	outputs    map[K]chan T
+	closed     bool
+	closedChan chan error
+}
+
+// PartitionerOption is a functional option for configuring a Partitioner.
+type PartitionerOption[T any, K comparable] func(*Partitioner[T, K])
+
+// WithPartitionBuffer sets the buffer size of each output channel.
+func WithPartitionBuffer[T any, K comparable](size int) PartitionerOption[T, K] {
+	return func(p *Partitioner[T, K]) {
+		p.bufferSize = size
+	}
+}
+
+// WithPartitionerContext ties the partitioner's lifetime to ctx. When ctx is
+// done the partitioner stops itself and ClosedChan() receives ctx.Err().
+func WithPartitionerContext[T any, K comparable](ctx context.Context) PartitionerOption[T, K] {
+	return func(p *Partitioner[T, K]) {
+		p.ctx = ctx
+	}
+}
+
+// NewPartitioner creates a partitioner reading from input and routing each
+// value to the output for classify(value).
+//
+// Example:
+//
+//	p := NewPartitioner(orders, func(o Order) string { return o.Region })
+//	go handleEU(p.Output("eu"))
+//	go handleUS(p.Output("us"))
+func NewPartitioner[T any, K comparable](input <-chan T, classify func(T) K, opts ...PartitionerOption[T, K]) *Partitioner[T, K] {
+	out := &Partitioner[T, K]{
+		RunnerBase: NewRunnerBase("stop"),
+		input:      input,
+		classify:   classify,
+		outputs:    make(map[K]chan T),
+		closedChan: make(chan error, 1),
+	}
+	out.component = "Partitioner"
+	for _, opt := range opts {
+		opt(out)
+	}
+	out.start()
+	return out
+}
+
+// Output returns the channel carrying values classified as key, creating it
+// if needed. After the partitioner has stopped, outputs for unseen keys are
+// returned already closed.
+func (p *Partitioner[T, K]) Output(key K) <-chan T {
+	return p.output(key)
+}
+
+// Keys returns the keys that currently have an output channel.
+func (p *Partitioner[T, K]) Keys() []K {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	keys := make([]K, 0, len(p.outputs))
+	for k := range p.outputs {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// ClosedChan returns the channel used to signal when the partitioner is done.
+func (p *Partitioner[T, K]) ClosedChan() <-chan error {
+	return p.closedChan
+}
+
+func (p *Partitioner[T, K]) output(key K) chan T {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	ch, ok := p.outputs[key]
+	if !ok {
+		ch = make(chan T, p.bufferSize)
+		if p.closed {
+			close(ch)
+		}
+		p.outputs[key] = ch
+	}
+	return ch
+}
+
+func (p *Partitioner[T, K]) cleanup() {
+	p.mu.Lock()
+	p.closed = true
+	for _, ch := range p.outputs {
+		close(ch)
+	}
+	p.mu.Unlock()
+	close(p.closedChan)
+	p.RunnerBase.cleanup()
+}
+
+func (p *Partitioner[T, K]) start() {
+	p.RunnerBase.start()
+	go func() {
+		defer p.cleanup()
+		for {
+			select {
+			case <-p.controlChan:
+				return
+			case <-p.ctxDone():
+				p.setErr(p.contextErr())
+				p.closedChan <- p.contextErr()
+				return
+			case value, ok := <-p.input:
+				if !ok {
+					return
+				}
+				select {
+				case p.output(p.classify(value)) <- value:
+				case <-p.controlChan:
+					return
+				case <-p.ctxDone():
+					p.setErr(p.contextErr())
+					p.closedChan <- p.contextErr()
+					return
+				}
+			}
+		}
+	}()
+}
@@ -0,0 +1,62 @@
+package gocurrent
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPartitionerEvenOdd(t *testing.T) {
+	input := make(chan int)
+	p := NewPartitioner(input, func(v int) string {
+		if v%2 == 0 {
+			return "even"
+		}
+		return "odd"
+	})
+
+	var evens, odds []int
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for v := range p.Output("even") {
+			evens = append(evens, v)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for v := range p.Output("odd") {
+			odds = append(odds, v)
+		}
+	}()
+
+	for i := 0; i < 10; i++ {
+		input <- i
+	}
+	close(input)
+	wg.Wait()
+
+	assert.Equal(t, []int{0, 2, 4, 6, 8}, evens)
+	assert.Equal(t, []int{1, 3, 5, 7, 9}, odds)
+	assert.ElementsMatch(t, []string{"even", "odd"}, p.Keys())
+}
+
+func TestPartitionerLazyKeys(t *testing.T) {
+	input := make(chan int)
+	p := NewPartitioner(input, func(v int) int { return v % 3 },
+		WithPartitionBuffer[int, int](10))
+
+	// Outputs for keys not requested yet are created as values arrive
+	for i := 0; i < 9; i++ {
+		input <- i
+	}
+	assert.ElementsMatch(t, []int{0, 1, 2}, p.Keys())
+	assert.Equal(t, 1, withTimeout(t, p.Output(1)))
+	assert.Equal(t, 4, withTimeout(t, p.Output(1)))
+
+	p.Stop()
+	_, ok := <-p.Output(42)
+	assert.False(t, ok, "Outputs requested after stop are closed")
+}
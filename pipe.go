@@ -1,5 +1,19 @@
 package gocurrent
 
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrNilInput is returned by Mapper.SetInput for a nil channel.
+var ErrNilInput = errors.New("input channel is nil")
+
+// ErrMapperPanic wraps a panic recovered from a MapFunc by a mapper created
+// with WithMapperRecover.
+var ErrMapperPanic = errors.New("mapper function panicked")
+
 func idMapperFunc[T any](input T) (output T, skip bool, stop bool) {
 	output = input
 	return
@@ -8,11 +22,25 @@ func idMapperFunc[T any](input T) (output T, skip bool, stop bool) {
 // Mapper connects an input and output channel applying transforms between them.
 // It reads from the input channel, applies a transformation function, and writes
 // the result to the output channel.
+//
+// A value being sent is never dropped: Stop waits until it has been read
+// (except with WithMaxInFlight, which abandons unsent results).
 type Mapper[I any, O any] struct {
 	RunnerBase[string]
 	input      <-chan I
 	output     chan<- O
 	closedChan chan error
+	inFlight   *InFlightLimiter
+	recover    bool
+	maxFlight  int // see WithMaxInFlight
+	newInput   chan (<-chan I)
+	drainStop  bool        // see WithDrainOnStop
+	conn       *connection // set by Connect and ConnectWith
+
+	// Deadlock detection (see WithMapperDeadlockDetection). logger is only
+	// set by components that run mappers on their own behalf, e.g. FanIn.
+	deadlockTimeout time.Duration
+	logger          Logger
 
 	// MapFunc is applied to each value in the input channel
 	// and returns a tuple of 3 things - outval, skip, stop
@@ -34,6 +62,59 @@ func WithMapperOnDone[I, O any](fn func(*Mapper[I, O])) MapperOption[I, O] {
 	}
 }
 
+// WithMapperContext ties the mapper's lifetime to ctx. When ctx is done the
+// mapper stops itself and ClosedChan() receives ctx.Err().
+func WithMapperContext[I, O any](ctx context.Context) MapperOption[I, O] {
+	return func(m *Mapper[I, O]) {
+		m.ctx = ctx
+	}
+}
+
+// WithMapperInFlightLimiter makes the mapper hold a slot of l from the
+// moment it receives a value until the result has been sent (or skipped).
+func WithMapperInFlightLimiter[I, O any](l *InFlightLimiter) MapperOption[I, O] {
+	return func(m *Mapper[I, O]) {
+		m.inFlight = l
+	}
+}
+
+// WithMapperRecover makes the mapper recover from a panicking MapFunc instead
+// of crashing the process. The panic is logged, the mapper stops, and an
+// error wrapping ErrMapperPanic is delivered on ClosedChan().
+func WithMapperRecover[I, O any](recover bool) MapperOption[I, O] {
+	return func(m *Mapper[I, O]) {
+		m.recover = recover
+	}
+}
+
+// WithDrainOnStop makes a MapFunc stop request finish the values already
+// buffered in the input channel before the mapper exits: it stops accepting
+// new work but maps (and sends) what is queued, until the input is empty or
+// closed. Stop requests from those values are ignored. An external Stop()
+// never drains; it ends the mapper right away. It has no effect with
+// WithMaxInFlight(n) for n > 1.
+func WithDrainOnStop[I, O any](drain bool) MapperOption[I, O] {
+	return func(m *Mapper[I, O]) {
+		m.drainStop = drain
+	}
+}
+
+// WithMapperDeadlockDetection warns when an output send stays blocked for
+// longer than timeout. See "Deadlock detection" in the README.
+func WithMapperDeadlockDetection[I, O any](timeout time.Duration) MapperOption[I, O] {
+	return func(m *Mapper[I, O]) {
+		m.deadlockTimeout = timeout
+	}
+}
+
+// WithMapperDeferredStart creates the mapper without starting it; call Start
+// (or Block.Start) to begin mapping.
+func WithMapperDeferredStart[I, O any]() MapperOption[I, O] {
+	return func(m *Mapper[I, O]) {
+		m.deferStart()
+	}
+}
+
 // NewMapper creates a new mapper between an input and output channel with functional options.
 // The ownership of the channels is by the caller and not the Mapper, so they
 // will not be closed when the mapper stops.
@@ -58,22 +139,57 @@ func NewMapper[T any, U any](input <-chan T, output chan<- U, mapper func(T) (U,
 		output:     output,
 		MapFunc:    mapper,
 		closedChan: make(chan error, 1),
+		newInput:   make(chan (<-chan T)),
 	}
+	out.component = "Mapper"
 
 	// Apply options
 	for _, opt := range opts {
 		opt(out)
 	}
 
-	out.start()
+	if !out.startPending.Load() {
+		out.start()
+	}
 	return out
 }
 
+// Start starts a mapper created with WithMapperDeferredStart. It returns
+// ErrAlreadyRunning if the mapper was not deferred or has already been
+// started.
+func (m *Mapper[I, O]) Start() error {
+	if !m.claimStart() {
+		return ErrAlreadyRunning
+	}
+	m.start()
+	return nil
+}
+
 // ClosedChan returns the channel used to signal when the mapper is done
 func (m *Mapper[I, O]) ClosedChan() <-chan error {
 	return m.closedChan
 }
 
+// SetInput repoints a running mapper at a new input channel, e.g. after
+// reconnecting to a source. Values already taken from the old input are
+// still mapped; the rest of the old input is left unread, and its closing no
+// longer stops the mapper. SetInput returns once the mapper has switched,
+// ErrStopped if the mapper is not running, or ErrNilInput for a nil channel.
+func (m *Mapper[I, O]) SetInput(input <-chan I) error {
+	if input == nil {
+		return ErrNilInput
+	}
+	if !m.IsRunning() {
+		return ErrStopped
+	}
+	select {
+	case m.newInput <- input:
+		return nil
+	case <-m.Done():
+		return ErrStopped
+	}
+}
+
 func (m *Mapper[I, O]) cleanup() {
 	if m.OnDone != nil {
 		m.OnDone(m)
@@ -82,8 +198,27 @@ func (m *Mapper[I, O]) cleanup() {
 	m.RunnerBase.cleanup()
 }
 
+// apply calls MapFunc, converting a panic into an error when recovery is
+// enabled.
+func (m *Mapper[I, O]) apply(value I) (outval O, skip bool, stop bool, err error) {
+	if m.recover {
+		defer func() {
+			if r := recover(); r != nil {
+				logf("Recovered from mapper panic: %v", r)
+				err = fmt.Errorf("%w: %v", ErrMapperPanic, r)
+			}
+		}()
+	}
+	outval, skip, stop = m.MapFunc(value)
+	return
+}
+
 func (m *Mapper[I, O]) start() {
 	m.RunnerBase.start()
+	if m.maxFlight > 1 {
+		go m.runParallel()
+		return
+	}
 	go func() {
 		defer m.cleanup()
 		for {
@@ -91,13 +226,37 @@ func (m *Mapper[I, O]) start() {
 			case <-m.controlChan:
 				// stopped - only "stop" allowed here
 				return
+			case input := <-m.newInput:
+				m.input = input
+			case <-m.ctxDone():
+				m.setErr(m.contextErr())
+				m.closedChan <- m.contextErr()
+				return
 			case value, ok := <-m.input:
 				if ok {
-					outval, filter, stop := m.MapFunc(value)
+					if !acquireOr(m.inFlight, m.controlChan, m.ctxDone()) {
+						if m.ctxErr() != nil {
+							m.setErr(m.contextErr())
+							m.closedChan <- m.contextErr()
+						}
+						return
+					}
+					outval, filter, stop, err := m.apply(value)
+					if err != nil {
+						m.inFlight.Release()
+						err = m.wrapErr(err)
+						m.setErr(err)
+						m.closedChan <- err
+						return
+					}
 					if !filter {
-						m.output <- outval
+						m.send(outval)
 					}
+					m.inFlight.Release()
 					if stop {
+						if m.drainStop {
+							m.drainBuffered()
+						}
 						return
 					}
 				} else {
@@ -110,9 +269,128 @@ func (m *Mapper[I, O]) start() {
 	}()
 }
 
+// drainBuffered maps the values currently buffered in the input channel
+// after a MapFunc stop request (see WithDrainOnStop).
+func (m *Mapper[I, O]) drainBuffered() {
+	for {
+		var value I
+		select {
+		case <-m.controlChan:
+			return
+		case v, ok := <-m.input:
+			if !ok {
+				return
+			}
+			value = v
+		default:
+			return
+		}
+		if !acquireOr(m.inFlight, m.controlChan, m.ctxDone()) {
+			return
+		}
+		outval, skip, _, err := m.apply(value)
+		if err != nil {
+			m.inFlight.Release()
+			err = m.wrapErr(err)
+			m.setErr(err)
+			m.closedChan <- err
+			return
+		}
+		if !skip {
+			m.send(outval)
+		}
+		m.inFlight.Release()
+	}
+}
+
+// send delivers a mapped value to the output channel, watched for deadlocks
+// if enabled.
+func (m *Mapper[I, O]) send(outval O) {
+	done := m.watchSend()
+	m.output <- outval
+	done()
+}
+
+// watchSend arms the deadlock watchdog for a send to the output channel.
+func (m *Mapper[I, O]) watchSend() (done func()) {
+	logger := m.logger
+	if logger == nil {
+		logger = defaultLogger()
+	}
+	return watchBlocking(logger, m.deadlockTimeout, "Mapper", "send to output")
+}
+
 // NewPipe creates a new pipe that connects an input and output channel.
 // A pipe is a mapper with the identity function, so it simply forwards
 // all values from input to output without transformation.
 func NewPipe[T any](input <-chan T, output chan<- T) *Mapper[T, T] {
 	return NewMapper(input, output, idMapperFunc)
 }
+
+// NewFilter creates a mapper that forwards only the values for which keep
+// returns true. Like NewMapper, the channels are owned by the caller.
+func NewFilter[T any](input <-chan T, output chan<- T, keep func(T) bool, opts ...MapperOption[T, T]) *Mapper[T, T] {
+	return NewMapper(input, output, func(value T) (T, bool, bool) {
+		return value, !keep(value), false
+	}, opts...)
+}
+
+// NewStatefulMapper creates a mapper that threads a state value through
+// successive items, e.g. for running totals or deltas. fn receives the current
+// state and the next input and returns the new state, the output and a skip
+// flag. The state starts at initial and is only touched by the mapper
+// goroutine, so fn needs no locking.
+func NewStatefulMapper[I, O, S any](input <-chan I, output chan<- O, initial S, fn func(S, I) (S, O, bool), opts ...MapperOption[I, O]) *Mapper[I, O] {
+	state := initial
+	return NewMapper(input, output, func(value I) (out O, skip bool, stop bool) {
+		state, out, skip = fn(state, value)
+		return
+	}, opts...)
+}
+
+// Chain composes two map functions into one with the same (output, skip,
+// stop) contract, so a multi-stage transform can run in a single Mapper:
+//
+//   - If m1 skips a value, m2 is not called and the composed function skips
+//     it too. m1's stop flag is still returned.
+//   - Otherwise m2 is called with m1's output, and the composed function
+//     returns m2's output and skip flag.
+//   - The composed function asks to stop if either m1 or m2 does. A value for
+//     which m1 asks to stop without skipping still passes through m2.
+func Chain[A, B, C any](m1 func(A) (B, bool, bool), m2 func(B) (C, bool, bool)) func(A) (C, bool, bool) {
+	return func(value A) (out C, skip bool, stop bool) {
+		mid, skip, stop := m1(value)
+		if skip {
+			return out, true, stop
+		}
+		out, skip, stop2 := m2(mid)
+		return out, skip, stop || stop2
+	}
+}
+
+// Then wires two mappers through an intermediate channel that it owns:
+// first maps input with m1 onto the intermediate channel and second maps
+// that onto output with m2. The caller still owns input and output.
+//
+// Values skipped by m1 never reach m2. When first finishes (its input is
+// closed, m1 asks to stop, or it is stopped) the intermediate channel is
+// closed, so second forwards what it has already received and then stops.
+// When second finishes first, first is stopped and whatever it still sends
+// is discarded. Stopping second is therefore enough to stop both; wait on
+// first's Done() to know it has exited.
+func Then[A, B, C any](input <-chan A, output chan<- C, m1 func(A) (B, bool, bool), m2 func(B) (C, bool, bool)) (first *Mapper[A, B], second *Mapper[B, C]) {
+	mid := make(chan B)
+	first = NewMapper(input, mid, m1, WithMapperOnDone(func(*Mapper[A, B]) {
+		close(mid)
+	}))
+	second = NewMapper(mid, output, m2, WithMapperOnDone(func(*Mapper[B, C]) {
+		// first may be blocked sending to mid; keep draining until it has
+		// exited and closed mid.
+		go func() {
+			for range mid {
+			}
+		}()
+		go first.Stop()
+	}))
+	return first, second
+}
@@ -0,0 +1,136 @@
+package gocurrent
+
+import "sync"
+
+// WithMaxInFlight lets the mapper work on up to n values at once: up to n
+// MapFunc calls run concurrently, and no further input is read while n
+// values have been read but not yet written to (or skipped for) the output.
+// Outputs keep the input order. MapFunc must be safe for concurrent use, so
+// this does not suit NewStatefulMapper. The default, n <= 1, maps one value
+// at a time.
+//
+// Stop behaves differently in this mode: results not yet written to the
+// output are abandoned, whereas a serial mapper blocked sending a value
+// keeps waiting until that value is read.
+func WithMaxInFlight[I, O any](n int) MapperOption[I, O] {
+	return func(m *Mapper[I, O]) {
+		m.maxFlight = n
+	}
+}
+
+// mapResult is the outcome of one MapFunc call in parallel mode.
+type mapResult[O any] struct {
+	out  O
+	skip bool
+	stop bool
+	err  error
+}
+
+// runParallel is the mapper loop used with WithMaxInFlight. The loop reads
+// input only after taking one of maxFlight slots and hands each value to its
+// own goroutine; an emitter goroutine writes the results in input order and
+// frees the slots.
+func (m *Mapper[I, O]) runParallel() {
+	defer m.cleanup()
+
+	slots := make(chan struct{}, m.maxFlight)
+	queue := make(chan chan mapResult[O], m.maxFlight) // results in input order
+	quit := make(chan struct{})                        // closed to abandon unsent results
+	emitterDone := make(chan struct{})                 // closed on MapFunc error or stop
+	var workers sync.WaitGroup
+
+	go func() {
+		defer close(emitterDone)
+		for pending := range queue {
+			r := <-pending
+			if r.err != nil {
+				m.inFlight.Release()
+				err := m.wrapErr(r.err)
+				m.setErr(err)
+				m.closedChan <- err
+				return
+			}
+			if !r.skip {
+				done := m.watchSend()
+				select {
+				case m.output <- r.out:
+					done()
+				case <-quit:
+					done()
+					m.inFlight.Release()
+					return
+				}
+			}
+			m.inFlight.Release()
+			<-slots
+			if r.stop {
+				return
+			}
+		}
+	}()
+
+	drain := m.readParallel(slots, queue, emitterDone, &workers)
+	if !drain {
+		close(quit)
+	}
+	close(queue)
+	<-emitterDone
+	workers.Wait()
+	// Results the emitter gave up on still hold their limiter slots.
+	for range queue {
+		m.inFlight.Release()
+	}
+	if m.ctxErr() != nil && m.terminalErr() == nil {
+		m.setErr(m.contextErr())
+		m.closedChan <- m.contextErr()
+	}
+}
+
+// readParallel feeds values to MapFunc goroutines until the mapper is
+// stopped or the emitter finishes. It reports whether the remaining results
+// should still be written, i.e. whether the input was closed.
+func (m *Mapper[I, O]) readParallel(slots chan struct{}, queue chan chan mapResult[O], emitterDone <-chan struct{}, workers *sync.WaitGroup) (drain bool) {
+	for {
+		select {
+		case slots <- struct{}{}:
+		case <-m.controlChan:
+			return false
+		case <-m.ctxDone():
+			return false
+		case <-emitterDone:
+			return false
+		}
+
+		var value I
+		for received := false; !received; {
+			select {
+			case v, ok := <-m.input:
+				if !ok {
+					return true
+				}
+				value, received = v, true
+			case input := <-m.newInput:
+				m.input = input
+			case <-m.controlChan:
+				return false
+			case <-m.ctxDone():
+				return false
+			case <-emitterDone:
+				return false
+			}
+		}
+
+		if !acquireOr(m.inFlight, m.controlChan, m.ctxDone()) {
+			return false
+		}
+		pending := make(chan mapResult[O], 1)
+		queue <- pending
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			var r mapResult[O]
+			r.out, r.skip, r.stop, r.err = m.apply(value)
+			pending <- r
+		}()
+	}
+}
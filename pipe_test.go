@@ -2,8 +2,11 @@ package gocurrent
 
 import (
 	"log"
+	"strconv"
 	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -69,3 +72,300 @@ func TestReader2Pipe(t *testing.T) {
 		inch <- i
 	}
 }
+
+// TestMapperRecover verifies a panicking MapFunc stops the mapper with an
+// ErrMapperPanic on ClosedChan and still runs OnDone.
+func TestMapperRecover(t *testing.T) {
+	input := make(chan int)
+	output := make(chan int, 10)
+	onDone := make(chan struct{})
+	mapper := NewMapper(input, output, func(i int) (int, bool, bool) {
+		if i < 0 {
+			panic("negative input")
+		}
+		return i * 2, false, false
+	}, WithMapperRecover[int, int](true),
+		WithMapperOnDone(func(*Mapper[int, int]) { close(onDone) }))
+	defer mapper.Stop()
+
+	input <- 1
+	input <- -1
+	assert.Equal(t, 2, <-output)
+
+	select {
+	case err := <-mapper.ClosedChan():
+		assert.ErrorIs(t, err, ErrMapperPanic)
+		assert.Contains(t, err.Error(), "negative input")
+	case <-time.After(time.Second):
+		t.Fatal("Timeout waiting for the recovered panic")
+	}
+	<-onDone
+	<-mapper.Done()
+	assert.False(t, mapper.IsRunning())
+	assert.Equal(t, StateErrored, mapper.State())
+}
+
+func TestFilter(t *testing.T) {
+	input := make(chan int)
+	output := make(chan int, 10)
+	filter := NewFilter(input, output, func(i int) bool { return i%2 == 0 })
+	defer filter.Stop()
+
+	for i := 0; i < 10; i++ {
+		input <- i
+	}
+	close(input)
+	<-filter.ClosedChan()
+	close(output)
+
+	var got []int
+	for v := range output {
+		got = append(got, v)
+	}
+	assert.Equal(t, []int{0, 2, 4, 6, 8}, got)
+}
+
+func TestStatefulMapperDeltas(t *testing.T) {
+	input := make(chan int)
+	output := make(chan int, 10)
+	type last struct {
+		value int
+		seen  bool
+	}
+	mapper := NewStatefulMapper(input, output, last{}, func(s last, i int) (last, int, bool) {
+		return last{i, true}, i - s.value, !s.seen
+	})
+	defer mapper.Stop()
+
+	for _, v := range []int{3, 5, 10, 9, 9} {
+		input <- v
+	}
+	close(input)
+	<-mapper.ClosedChan()
+	close(output)
+
+	var got []int
+	for v := range output {
+		got = append(got, v)
+	}
+	assert.Equal(t, []int{2, 5, -1, 0}, got)
+}
+
+// TestMapperMaxInFlight stalls the transform and checks that the mapper reads
+// exactly n values, runs them concurrently, and still emits in input order.
+func TestMapperMaxInFlight(t *testing.T) {
+	const n = 3
+	gate := make(chan struct{})
+	var active, peak atomic.Int32
+	in := make(chan int)
+	out := make(chan int)
+	mapper := NewMapper(in, out, func(v int) (int, bool, bool) {
+		cur := active.Add(1)
+		for p := peak.Load(); cur > p && !peak.CompareAndSwap(p, cur); p = peak.Load() {
+		}
+		<-gate
+		active.Add(-1)
+		return v * 10, false, false
+	}, WithMaxInFlight[int, int](n))
+	defer mapper.Stop()
+
+	for i := 0; i < n; i++ {
+		select {
+		case in <- i:
+		case <-time.After(testTimeout):
+			t.Fatalf("Mapper should accept %d values while stalled", n)
+		}
+	}
+	select {
+	case in <- n:
+		t.Fatal("Mapper read input beyond its in-flight limit")
+	case <-time.After(50 * time.Millisecond):
+	}
+	assert.Eventually(t, func() bool { return peak.Load() == n },
+		testTimeout, time.Millisecond, "Transforms should run concurrently")
+
+	close(gate)
+	go func() {
+		for i := n; i < 20; i++ {
+			in <- i
+		}
+		close(in)
+	}()
+	for i := 0; i < 20; i++ {
+		assert.Equal(t, i*10, withTimeout(t, out))
+	}
+	assert.LessOrEqual(t, peak.Load(), int32(n))
+	assert.NoError(t, withTimeout(t, mapper.ClosedChan()))
+}
+
+// TestMapperMaxInFlightError verifies that a failing transform stops a
+// parallel mapper with the error and releases all limiter slots.
+func TestMapperMaxInFlightError(t *testing.T) {
+	limiter := NewInFlightLimiter(10)
+	in := make(chan int, 10)
+	out := make(chan int, 10)
+	mapper := NewMapper(in, out, func(v int) (int, bool, bool) {
+		if v == 2 {
+			panic("two")
+		}
+		return v, false, false
+	}, WithMaxInFlight[int, int](4), WithMapperRecover[int, int](true),
+		WithMapperInFlightLimiter[int, int](limiter))
+	for i := 0; i < 6; i++ {
+		in <- i
+	}
+	assert.ErrorIs(t, withTimeout(t, mapper.ClosedChan()), ErrMapperPanic)
+	withTimeout(t, mapper.Done())
+	assert.Equal(t, 0, limiter.InFlight())
+	assert.Equal(t, 0, withTimeout(t, out))
+	assert.Equal(t, 1, withTimeout(t, out))
+}
+
+// TestMapperSetInput switches a running mapper to a new source mid-stream
+// and checks that values arrive from both sources in switch order.
+func TestMapperSetInput(t *testing.T) {
+	for name, opts := range map[string][]MapperOption[int, int]{
+		"Serial":   nil,
+		"Parallel": {WithMaxInFlight[int, int](4)},
+	} {
+		t.Run(name, func(t *testing.T) {
+			first, second := make(chan int), make(chan int)
+			out := make(chan int)
+			mapper := NewMapper(first, out, func(v int) (int, bool, bool) {
+				return v * 10, false, false
+			}, opts...)
+			defer mapper.Stop()
+
+			go func() {
+				first <- 1
+				first <- 2
+			}()
+			assert.Equal(t, 10, withTimeout(t, out))
+			assert.Equal(t, 20, withTimeout(t, out))
+
+			assert.NoError(t, mapper.SetInput(second))
+			go func() {
+				second <- 3
+				second <- 4
+			}()
+			assert.Equal(t, 30, withTimeout(t, out))
+			assert.Equal(t, 40, withTimeout(t, out))
+
+			// The old input is no longer read, and closing it is harmless.
+			select {
+			case first <- 5:
+				t.Fatal("Old input should not be read after SetInput")
+			case <-time.After(20 * time.Millisecond):
+			}
+			close(first)
+			go func() { second <- 6 }()
+			assert.Equal(t, 60, withTimeout(t, out))
+			assert.True(t, mapper.IsRunning())
+
+			assert.ErrorIs(t, mapper.SetInput(nil), ErrNilInput)
+			mapper.Stop()
+			assert.ErrorIs(t, mapper.SetInput(second), ErrStopped)
+		})
+	}
+}
+
+func TestChain(t *testing.T) {
+	var m2Calls []int
+	double := func(i int) (int, bool, bool) { return i * 2, i%3 == 0, i == 4 }
+	format := func(i int) (string, bool, bool) {
+		m2Calls = append(m2Calls, i)
+		return strconv.Itoa(i), false, i == 10
+	}
+	fn := Chain(double, format)
+
+	// m1 skipping short-circuits m2.
+	_, skip, stop := fn(3)
+	assert.True(t, skip)
+	assert.False(t, stop)
+	assert.Empty(t, m2Calls)
+
+	out, skip, stop := fn(1)
+	assert.Equal(t, "2", out)
+	assert.False(t, skip)
+	assert.False(t, stop)
+
+	// A stop from m1 still lets the value through m2.
+	out, skip, stop = fn(4)
+	assert.Equal(t, "8", out)
+	assert.False(t, skip)
+	assert.True(t, stop)
+
+	// A stop from m2 propagates.
+	_, _, stop = fn(5)
+	assert.True(t, stop)
+	assert.Equal(t, []int{2, 8, 10}, m2Calls)
+}
+
+func TestThen(t *testing.T) {
+	input := make(chan int)
+	output := make(chan string, 10)
+	first, second := Then(input, output,
+		func(i int) (int, bool, bool) { return i * 2, i%2 == 1, false },
+		func(i int) (string, bool, bool) { return strconv.Itoa(i), false, i == 8 })
+
+	for i := range 10 {
+		select {
+		case input <- i:
+		case <-second.Done():
+		}
+	}
+	// m2 stopped at 8: second exits and takes first down with it.
+	withTimeout(t, second.Done())
+	withTimeout(t, first.Done())
+	close(output)
+
+	var got []string
+	for v := range output {
+		got = append(got, v)
+	}
+	assert.Equal(t, []string{"0", "4", "8"}, got)
+}
+
+func TestThenInputClosed(t *testing.T) {
+	input := make(chan int)
+	output := make(chan int, 10)
+	first, second := Then(input, output,
+		func(i int) (int, bool, bool) { return i + 1, false, false },
+		func(i int) (int, bool, bool) { return i * 10, false, false })
+
+	for i := range 3 {
+		input <- i
+	}
+	close(input)
+	withTimeout(t, first.Done())
+	withTimeout(t, second.Done())
+	close(output)
+
+	var got []int
+	for v := range output {
+		got = append(got, v)
+	}
+	assert.Equal(t, []int{10, 20, 30}, got)
+}
+
+func TestMapperDrainOnStop(t *testing.T) {
+	stopAt := func(v int) (int, bool, bool) { return v * 10, false, v == 3 }
+	run := func(drain bool) []int {
+		input := make(chan int, 10)
+		output := make(chan int, 10)
+		for i := 1; i <= 6; i++ {
+			input <- i
+		}
+		mapper := NewMapper(input, output, stopAt, WithDrainOnStop[int, int](drain))
+		withTimeout(t, mapper.Done())
+		close(output)
+		var got []int
+		for v := range output {
+			got = append(got, v)
+		}
+		return got
+	}
+
+	assert.Equal(t, []int{10, 20, 30}, run(false))
+	assert.Equal(t, []int{10, 20, 30, 40, 50, 60}, run(true))
+}
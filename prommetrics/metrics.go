@@ -0,0 +1,157 @@
+// Package prommetrics exports gocurrent component statistics as Prometheus
+// metrics. It lives in its own module so the core gocurrent package does not
+// depend on the Prometheus client.
+//
+// Metrics are read from the component on every scrape; nothing is polled in
+// the background. All metrics carry a "component" label with the name given
+// to RegisterMetrics, so many components can share a registry.
+package prommetrics
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/panyam/gocurrent"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Namespace prefixes the name of every exported metric.
+const Namespace = "gocurrent"
+
+// ErrNilComponent is returned by RegisterMetrics for a nil component.
+var ErrNilComponent = errors.New("cannot register metrics for a nil component")
+
+// RegisterMetrics registers a collector on reg that reports c's statistics
+// under the "component" label name. The following are exported, depending on
+// which methods c has:
+//
+//   - gocurrent_running (gauge): 1 while c.IsRunning(), else 0.
+//   - Stats(): every integer field but QueueDepth (a level, covered by
+//     Len()) becomes a counter named after it, e.g.
+//     FanOutStats.Input → gocurrent_input_total; time.Duration fields become
+//     seconds, e.g. ReaderStats.BlockedSendDuration →
+//     gocurrent_blocked_send_seconds_total. Per-output maps such as
+//     FanOutStats.Outputs are summed, e.g. gocurrent_outputs_dropped_total.
+//   - Pending() int (gauge): gocurrent_pending, e.g. a Reducer's unflushed
+//     inputs.
+//   - Len() int (gauge): gocurrent_queue_depth, e.g. a Buffer's length.
+//   - Count() int (gauge): gocurrent_count, e.g. a FanIn's inputs or a
+//     FanOut's outputs.
+func RegisterMetrics(reg prometheus.Registerer, c gocurrent.Component, name string) error {
+	if c == nil {
+		return ErrNilComponent
+	}
+	return reg.Register(&collector{component: c, name: name})
+}
+
+// collector reads the component's statistics on each Collect. It is an
+// unchecked collector: the metric set follows the shape of Stats().
+type collector struct {
+	component gocurrent.Component
+	name      string
+}
+
+// Describe sends no descriptors, making this an unchecked collector.
+func (col *collector) Describe(chan<- *prometheus.Desc) {}
+
+// Collect implements prometheus.Collector.
+func (col *collector) Collect(ch chan<- prometheus.Metric) {
+	running := 0.0
+	if col.component.IsRunning() {
+		running = 1
+	}
+	col.emit(ch, "running", "Whether the component is running.", prometheus.GaugeValue, running)
+
+	if m, ok := col.component.(interface{ Pending() int }); ok {
+		col.emit(ch, "pending", "Inputs received but not yet flushed.", prometheus.GaugeValue, float64(m.Pending()))
+	}
+	if m, ok := col.component.(interface{ Len() int }); ok {
+		col.emit(ch, "queue_depth", "Items currently queued.", prometheus.GaugeValue, float64(m.Len()))
+	}
+	if m, ok := col.component.(interface{ Count() int }); ok {
+		col.emit(ch, "count", "Channels currently attached.", prometheus.GaugeValue, float64(m.Count()))
+	}
+
+	// Stats() returns a different struct type per component (and per type
+	// parameter), so it is found and flattened via reflection.
+	method := reflect.ValueOf(col.component).MethodByName("Stats")
+	if !method.IsValid() || method.Type().NumIn() != 0 || method.Type().NumOut() != 1 {
+		return
+	}
+	totals := map[string]float64{}
+	var order []string
+	flatten(method.Call(nil)[0], "", func(name string, v float64) {
+		if _, seen := totals[name]; !seen {
+			order = append(order, name)
+		}
+		totals[name] += v
+	})
+	for _, name := range order {
+		col.emit(ch, name, "Cumulative "+strings.ReplaceAll(strings.TrimSuffix(name, "_total"), "_", " ")+" reported by Stats().",
+			prometheus.CounterValue, totals[name])
+	}
+}
+
+func (col *collector) emit(ch chan<- prometheus.Metric, name, help string, typ prometheus.ValueType, v float64) {
+	desc := prometheus.NewDesc(prometheus.BuildFQName(Namespace, "", name), help,
+		nil, prometheus.Labels{"component": col.name})
+	ch <- prometheus.MustNewConstMetric(desc, typ, v)
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// flatten walks a Stats() value and reports each numeric leaf as a counter
+// name and value. Map entries of the same field are reported under the same
+// name, so callers sum them.
+func flatten(v reflect.Value, prefix string, report func(string, float64)) {
+	switch v.Kind() {
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if !field.IsExported() || field.Name == "QueueDepth" {
+				// QueueDepth is a level, reported via Len() as a gauge.
+				continue
+			}
+			flatten(v.Field(i), join(prefix, snakeCase(field.Name)), report)
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			flatten(iter.Value(), prefix, report)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Type() == durationType {
+			report(strings.TrimSuffix(prefix, "_duration")+"_seconds_total", time.Duration(v.Int()).Seconds())
+		} else {
+			report(prefix+"_total", float64(v.Int()))
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		report(prefix+"_total", float64(v.Uint()))
+	}
+}
+
+func join(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "_" + name
+}
+
+// snakeCase converts a Go field name such as BlockedSendDuration to
+// blocked_send_duration.
+func snakeCase(s string) string {
+	var b strings.Builder
+	for i, r := range s {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
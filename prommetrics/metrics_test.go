@@ -0,0 +1,95 @@
+package prommetrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/panyam/gocurrent"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// gather scrapes reg and returns each metric's value by name for the given
+// component label.
+func gather(t *testing.T, reg *prometheus.Registry, component string) map[string]float64 {
+	t.Helper()
+	families, err := reg.Gather()
+	require.NoError(t, err)
+	values := map[string]float64{}
+	for _, family := range families {
+		for _, m := range family.GetMetric() {
+			for _, label := range m.GetLabel() {
+				if label.GetName() != "component" || label.GetValue() != component {
+					continue
+				}
+				if c := m.GetCounter(); c != nil {
+					values[family.GetName()] = c.GetValue()
+				} else if g := m.GetGauge(); g != nil {
+					values[family.GetName()] = g.GetValue()
+				}
+			}
+		}
+	}
+	return values
+}
+
+func TestRegisterMetricsFanOut(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	fo := gocurrent.NewQueuedFanOut[int](gocurrent.WithFanOutDropPolicy[int](gocurrent.DropNewest))
+	defer fo.Stop()
+	require.NoError(t, RegisterMetrics(reg, fo, "events"))
+
+	fast := make(chan int, 10)
+	slow := make(chan int, 1)
+	<-fo.Add(fast, nil, true)
+	<-fo.Add(slow, nil, true)
+	for i := 0; i < 5; i++ {
+		fo.Send(i)
+	}
+	for i := 0; i < 5; i++ {
+		<-fast
+	}
+	assert.Eventually(t, func() bool {
+		v := gather(t, reg, "events")
+		return v["gocurrent_outputs_delivered_total"]+v["gocurrent_outputs_dropped_total"] == 10
+	}, 5*time.Second, 10*time.Millisecond)
+
+	v := gather(t, reg, "events")
+	assert.Equal(t, 1.0, v["gocurrent_running"])
+	assert.Equal(t, 2.0, v["gocurrent_count"])
+	assert.Equal(t, 5.0, v["gocurrent_input_total"])
+	assert.Equal(t, 6.0, v["gocurrent_outputs_delivered_total"])
+	assert.Equal(t, 4.0, v["gocurrent_outputs_dropped_total"])
+}
+
+func TestRegisterMetricsMultipleComponents(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	input := make(chan int, 3)
+	buf := gocurrent.NewBuffer(input)
+	defer buf.Stop()
+	reader := gocurrent.NewReader(func() (int, error) { return 1, nil })
+	defer reader.Stop()
+	require.NoError(t, RegisterMetrics(reg, buf, "buffer"))
+	require.NoError(t, RegisterMetrics(reg, reader, "reader"))
+
+	for i := 0; i < 3; i++ {
+		input <- i
+	}
+	// Nothing reads the buffer's output, so the values stay queued.
+	assert.Eventually(t, func() bool {
+		return gather(t, reg, "buffer")["gocurrent_queue_depth"] >= 2
+	}, 5*time.Second, 10*time.Millisecond)
+
+	<-reader.OutputChan()
+	assert.Contains(t, gather(t, reg, "reader"), "gocurrent_blocked_send_seconds_total")
+
+	reader.Stop()
+	assert.Eventually(t, func() bool {
+		return gather(t, reg, "reader")["gocurrent_running"] == 0
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestRegisterMetricsNil(t *testing.T) {
+	assert.ErrorIs(t, RegisterMetrics(prometheus.NewRegistry(), nil, "nil"), ErrNilComponent)
+}
@@ -0,0 +1,203 @@
+package gocurrent
+
+import (
+	"sync"
+	"time"
+)
+
+// tokenBucket is a minimal token-bucket limiter. Tokens refill continuously
+// at rate per second up to burst.
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+	clock  Clock
+	paused bool // no tokens are handed out while paused
+	// changed is closed and replaced whenever the rate or pause state
+	// changes, waking waiters so they recompute their wait.
+	changed chan struct{}
+}
+
+func newTokenBucket(rate float64, burst int, clock Clock) *tokenBucket {
+	if burst < 1 {
+		burst = 1
+	}
+	return &tokenBucket{
+		rate:    rate,
+		burst:   float64(burst),
+		tokens:  float64(burst),
+		last:    clock.Now(),
+		clock:   clock,
+		changed: make(chan struct{}),
+	}
+}
+
+// refill adds the tokens accrued since the last call. Must hold mu.
+func (b *tokenBucket) refill(now time.Time) {
+	if b.rate > 0 {
+		b.tokens += now.Sub(b.last).Seconds() * b.rate
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+	}
+	b.last = now
+}
+
+// take blocks until a token is available and consumes it. It returns false
+// without consuming a token if cancel is closed first.
+func (b *tokenBucket) take(cancel <-chan struct{}) bool {
+	for {
+		b.mu.Lock()
+		b.refill(b.clock.Now())
+		if b.tokens >= 1 && !b.paused {
+			b.tokens--
+			b.mu.Unlock()
+			return true
+		}
+		// With a zero rate nothing refills until SetRate raises it, and
+		// while paused nothing is handed out until setPaused clears it.
+		wait := time.Hour
+		if b.rate > 0 && !b.paused {
+			wait = time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+		}
+		changed := b.changed
+		b.mu.Unlock()
+
+		timer := b.clock.NewTimer(wait)
+		select {
+		case <-cancel:
+			timer.Stop()
+			return false
+		case <-changed:
+			timer.Stop()
+		case <-timer.C():
+		}
+	}
+}
+
+func (b *tokenBucket) available() float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refill(b.clock.Now())
+	return b.tokens
+}
+
+func (b *tokenBucket) setRate(rate float64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refill(b.clock.Now())
+	b.rate = rate
+	close(b.changed)
+	b.changed = make(chan struct{})
+}
+
+func (b *tokenBucket) setPaused(paused bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.paused = paused
+	close(b.changed)
+	b.changed = make(chan struct{})
+}
+
+// RateLimitedPipe forwards values from an input to an output channel, like
+// NewPipe, while enforcing a token-bucket rate limit. Up to burst values may
+// pass back to back; after that values pass at ratePerSec on average.
+//
+// When no token is available the pipe blocks without reading further input,
+// so back-pressure propagates upstream. Stop interrupts the wait; the value
+// being held is dropped.
+type RateLimitedPipe[T any] struct {
+	*Mapper[T, T]
+	bucket   *tokenBucket
+	stopping chan struct{}
+	stopOnce sync.Once
+	clock    Clock
+}
+
+// RateLimitedPipeOption is a functional option for configuring a
+// RateLimitedPipe.
+type RateLimitedPipeOption[T any] func(*RateLimitedPipe[T])
+
+// WithRateLimitClock sets the clock refilling the token bucket, e.g. a
+// FakeClock in tests. The default is RealClock.
+func WithRateLimitClock[T any](clock Clock) RateLimitedPipeOption[T] {
+	return func(p *RateLimitedPipe[T]) {
+		p.clock = clock
+	}
+}
+
+// NewRateLimitedPipe creates a pipe from input to output that forwards at
+// most ratePerSec values per second on average, with bursts of up to burst
+// values. A burst below 1 is treated as 1. The channels are owned by the
+// caller.
+//
+// Example:
+//
+//	// At most 10 requests per second, allowing short bursts of 5
+//	limiter := NewRateLimitedPipe(requests, throttled, 10, 5)
+//	defer limiter.Stop()
+func NewRateLimitedPipe[T any](input <-chan T, output chan<- T, ratePerSec float64, burst int, opts ...RateLimitedPipeOption[T]) *RateLimitedPipe[T] {
+	out := &RateLimitedPipe[T]{
+		stopping: make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(out)
+	}
+	out.bucket = newTokenBucket(ratePerSec, burst, clockOr(out.clock))
+	out.Mapper = NewMapper(input, output, out.apply)
+	return out
+}
+
+func (p *RateLimitedPipe[T]) apply(value T) (T, bool, bool) {
+	if !p.bucket.take(p.stopping) {
+		return value, true, true
+	}
+	return value, false, false
+}
+
+// Stop stops the pipe, interrupting any wait for a token.
+func (p *RateLimitedPipe[T]) Stop() error {
+	p.stopOnce.Do(func() { close(p.stopping) })
+	return p.Mapper.Stop()
+}
+
+// Tokens returns the number of tokens currently available.
+func (p *RateLimitedPipe[T]) Tokens() float64 {
+	return p.bucket.available()
+}
+
+// SetRate changes the refill rate. Tokens accrued so far are kept. This
+// makes RateLimitedPipe a [RateSetter].
+func (p *RateLimitedPipe[T]) SetRate(perSecond float64) {
+	p.bucket.setRate(perSecond)
+}
+
+// Pause holds back every value not yet forwarded until Resume is called,
+// without stopping the pipe. As with an exhausted bucket, no further input
+// is read meanwhile, so back-pressure propagates upstream. Tokens keep
+// accruing while paused. Pause and Resume make RateLimitedPipe a [Pauser].
+func (p *RateLimitedPipe[T]) Pause() {
+	p.bucket.setPaused(true)
+}
+
+// Resume lets values pass again after Pause.
+func (p *RateLimitedPipe[T]) Resume() {
+	p.bucket.setPaused(false)
+}
+
+// DebugInfo returns diagnostic information including the current token
+// count and limiter configuration.
+func (p *RateLimitedPipe[T]) DebugInfo() any {
+	p.bucket.mu.Lock()
+	rate, burst, paused := p.bucket.rate, p.bucket.burst, p.bucket.paused
+	p.bucket.mu.Unlock()
+	return map[string]any{
+		"base":   p.Mapper.DebugInfo(),
+		"tokens": p.Tokens(),
+		"rate":   rate,
+		"burst":  burst,
+		"paused": paused,
+	}
+}
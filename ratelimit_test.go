@@ -0,0 +1,112 @@
+package gocurrent
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimitedPipeThroughput(t *testing.T) {
+	const rate = 50
+	input := make(chan int)
+	output := make(chan int)
+	pipe := NewRateLimitedPipe(input, output, rate, 1)
+	defer pipe.Stop()
+
+	go func() {
+		for i := 0; ; i++ {
+			select {
+			case input <- i:
+			case <-pipe.Done():
+				return
+			}
+		}
+	}()
+
+	count := 0
+	deadline := time.After(time.Second)
+loop:
+	for {
+		select {
+		case <-output:
+			count++
+		case <-deadline:
+			break loop
+		}
+	}
+	// One initial token plus ~rate refills per second
+	assert.InDelta(t, rate, count, rate*0.2, "Throughput should be roughly ratePerSec")
+}
+
+func TestRateLimitedPipeBurst(t *testing.T) {
+	input := make(chan int, 10)
+	output := make(chan int, 10)
+	pipe := NewRateLimitedPipe(input, output, 1, 3)
+	defer pipe.Stop()
+
+	for i := 0; i < 5; i++ {
+		input <- i
+	}
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, i, withTimeout(t, output))
+	}
+	select {
+	case v := <-output:
+		t.Fatalf("Value %d passed before a token was refilled", v)
+	case <-time.After(200 * time.Millisecond):
+	}
+	info := pipe.DebugInfo().(map[string]any)
+	assert.Less(t, info["tokens"].(float64), 1.0)
+	assert.Equal(t, 3.0, info["burst"])
+}
+
+func TestRateLimitedPipeStopWhileWaiting(t *testing.T) {
+	input := make(chan int, 2)
+	output := make(chan int, 2)
+	pipe := NewRateLimitedPipe(input, output, 0.1, 1)
+
+	input <- 1
+	input <- 2
+	assert.Equal(t, 1, withTimeout(t, output))
+
+	// The second value is waiting ~10s for a token; Stop must not wait for it
+	stopped := make(chan error, 1)
+	go func() { stopped <- pipe.Stop() }()
+	withTimeout(t, stopped)
+	assert.False(t, pipe.IsRunning())
+	assert.Len(t, output, 0, "The held value should be dropped on Stop")
+}
+
+func TestRateLimitedPipeSetRate(t *testing.T) {
+	input := make(chan int, 2)
+	output := make(chan int, 2)
+	pipe := NewRateLimitedPipe(input, output, 0.1, 1)
+	defer pipe.Stop()
+
+	input <- 1
+	input <- 2
+	assert.Equal(t, 1, withTimeout(t, output))
+
+	var rs RateSetter = pipe
+	rs.SetRate(100)
+	assert.Equal(t, 2, withTimeout(t, output))
+}
+
+func TestRateLimitedPipeFakeClock(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	input := make(chan int, 2)
+	output := make(chan int, 2)
+	pipe := NewRateLimitedPipe(input, output, 1, 1, WithRateLimitClock[int](clock))
+	defer pipe.Stop()
+
+	input <- 1
+	input <- 2
+	assert.Equal(t, 1, withTimeout(t, output))
+
+	// The second value waits for a token that only refills as the clock moves.
+	clock.BlockUntil(1)
+	assert.Len(t, output, 0)
+	clock.Advance(time.Second)
+	assert.Equal(t, 2, withTimeout(t, output))
+}
@@ -1,24 +1,69 @@
 package gocurrent
 
 import (
+	"context"
 	"errors"
-	"log"
-	"log/slog"
+	"io"
+	"math/rand/v2"
 	"net"
+	"sync/atomic"
+	"time"
 )
 
+// ErrSendTimeout is reported by a Reader configured with WithSendTimeout
+// (and no WithOnDrop callback) when its consumer does not accept a message in
+// time.
+var ErrSendTimeout = errors.New("timed out sending to reader output")
+
 // ReaderFunc is the type of the reader method used by the Reader goroutine primitive.
 type ReaderFunc[R any] func() (msg R, err error)
 
+// ReaderFuncCtx is the type of a reader method that is told to stop: its ctx
+// is cancelled when the Reader stops, so a blocking read can return early
+// (see NewReaderCtx).
+type ReaderFuncCtx[R any] func(ctx context.Context) (msg R, err error)
+
 // Reader is a typed Reader goroutine which calls a Read method to return data
 // over a channel. It continuously calls the reader function and sends results
 // to a channel wrapped in Message structs.
 type Reader[R any] struct {
 	RunnerBase[string]
 	msgChannel chan Message[R]
+	errChannel chan error // nil unless WithSeparateErrors
 	Read       ReaderFunc[R]
+	readCtx    ReaderFuncCtx[R] // set by NewReaderCtx instead of Read
 	closedChan chan error
 	OnDone     func(r *Reader[R])
+	inFlight   *InFlightLimiter
+	onError    func(error) bool
+	stopWait   time.Duration
+
+	sendTimeout time.Duration
+	onDrop      func(Message[R])
+
+	// Read deadlines (see WithReadDeadline)
+	readTimeout time.Duration
+	setDeadline func(time.Time) error
+	emitTimeout bool
+
+	// Polling (see WithPollInterval and WithJitter)
+	pollInterval time.Duration
+	jitter       float64
+	clock        Clock
+
+	blockedSend atomic.Int64 // nanoseconds spent waiting to send on msgChannel
+
+	// release is called by the reading goroutine when it exits (see
+	// NewSeqReader).
+	release func()
+}
+
+// ReaderStats are cumulative counters of a Reader.
+type ReaderStats struct {
+	// BlockedSendDuration is the total time spent waiting for the consumer
+	// to accept messages on OutputChan(). A large value points at a slow
+	// downstream.
+	BlockedSendDuration time.Duration
 }
 
 // ReaderOption is a functional option for configuring a Reader
@@ -38,6 +83,138 @@ func WithOnDone[R any](fn func(*Reader[R])) ReaderOption[R] {
 	}
 }
 
+// WithOnError installs a callback consulted for every read error (other than
+// timeouts, which are always retried). Returning true skips the failed read
+// and keeps reading; the error is not sent on OutputChan(). Returning false
+// reports the error on OutputChan() and ClosedChan() and stops the reader.
+// Without a callback a read error is reported the same way and ends reading,
+// but the reader keeps running (IsRunning() stays true and OnDone is not
+// called) until Stop().
+func WithOnError[R any](fn func(error) bool) ReaderOption[R] {
+	return func(r *Reader[R]) {
+		r.onError = fn
+	}
+}
+
+// WithReaderStopHandshake makes the reader wait, when stopping, for its
+// reading goroutine to return from Read before the reader counts as stopped,
+// so no Read call and no OutputChan() send outlives Stop(). A negative wait
+// waits as long as Read takes; a positive one waits at most that long. The
+// default, 0, does not wait at all, since Read may block indefinitely (e.g.
+// on a network connection that is never closed).
+func WithReaderStopHandshake[R any](wait time.Duration) ReaderOption[R] {
+	return func(r *Reader[R]) {
+		r.stopWait = wait
+	}
+}
+
+// WithSendTimeout bounds how long the reader waits for the consumer to accept
+// each message on OutputChan(). When d elapses the message is handed to the
+// WithOnDrop callback and reading continues; without such a callback the
+// reader instead stops, reporting ErrSendTimeout on ClosedChan(). This keeps
+// a consumer that stops reading (without calling Stop) from leaking the
+// reading goroutine. A d <= 0 (the default) waits forever.
+func WithSendTimeout[R any](d time.Duration) ReaderOption[R] {
+	return func(r *Reader[R]) {
+		r.sendTimeout = d
+	}
+}
+
+// WithOnDrop makes a reader with WithSendTimeout drop messages the consumer
+// does not take in time, passing each to fn, instead of stopping.
+func WithOnDrop[R any](fn func(Message[R])) ReaderOption[R] {
+	return func(r *Reader[R]) {
+		r.onDrop = fn
+	}
+}
+
+// WithReadDeadline supports connection-like sources whose reads time out.
+// Before each Read the reader calls setDeadline with a deadline d from now
+// (pass e.g. conn.SetReadDeadline). Reads failing with a net.Error whose
+// Timeout() is true are retried, as they always are, while any other read
+// error, or a failure to set the deadline, stops the reader with that error
+// on ClosedChan() unless a WithOnError callback chooses to continue.
+func WithReadDeadline[R any](d time.Duration, setDeadline func(time.Time) error) ReaderOption[R] {
+	return func(r *Reader[R]) {
+		r.readTimeout = d
+		r.setDeadline = setDeadline
+	}
+}
+
+// WithEmitOnTimeout makes the reader deliver timed-out reads (a net.Error
+// whose Timeout() is true) as a Message carrying the timeout error and
+// whatever value Read returned, e.g. to drive a keepalive. The read is still
+// retried and the error is not reported on ClosedChan(). By default timeouts
+// are silently retried.
+func WithEmitOnTimeout[R any](emit bool) ReaderOption[R] {
+	return func(r *Reader[R]) {
+		r.emitTimeout = emit
+	}
+}
+
+// WithPollInterval makes the reader wait d between successive Read calls,
+// for sources that are polled rather than blocking until data is ready. A
+// Stop() during the wait takes effect immediately.
+func WithPollInterval[R any](d time.Duration) ReaderOption[R] {
+	return func(r *Reader[R]) {
+		r.pollInterval = d
+	}
+}
+
+// WithJitter randomizes each poll interval (see WithPollInterval) by up to
+// ±fraction of it, e.g. 0.1 for intervals within 10% of the configured one,
+// so that many readers polling the same backend do not synchronize. The
+// fraction is clamped to [0, 1]. It has no effect without a poll interval.
+func WithJitter[R any](fraction float64) ReaderOption[R] {
+	return func(r *Reader[R]) {
+		r.jitter = min(max(fraction, 0), 1)
+	}
+}
+
+// WithReaderClock sets the clock timing the poll intervals (see
+// WithPollInterval), e.g. a FakeClock in tests. The default is RealClock.
+func WithReaderClock[R any](clock Clock) ReaderOption[R] {
+	return func(r *Reader[R]) {
+		r.clock = clock
+	}
+}
+
+// WithSeparateErrors delivers read errors on ErrorsChan() instead of
+// OutputChan(), so that OutputChan() only carries successful reads and
+// consumers need not check Message.Error. Errors are reported on
+// ClosedChan() as usual. The errors channel is unbuffered and must be
+// drained alongside OutputChan(); the reader blocks on it otherwise.
+func WithSeparateErrors[R any]() ReaderOption[R] {
+	return func(r *Reader[R]) {
+		r.errChannel = make(chan error)
+	}
+}
+
+// WithReaderContext ties the reader's lifetime to ctx. When ctx is done the
+// reader stops itself and ClosedChan() receives ctx.Err().
+func WithReaderContext[R any](ctx context.Context) ReaderOption[R] {
+	return func(r *Reader[R]) {
+		r.ctx = ctx
+	}
+}
+
+// WithReaderInFlightLimiter makes the reader hold a slot of l from just
+// before each Read until the resulting message has been delivered (or
+// discarded). Reads block while the limiter is full.
+func WithReaderInFlightLimiter[R any](l *InFlightLimiter) ReaderOption[R] {
+	return func(r *Reader[R]) {
+		r.inFlight = l
+	}
+}
+
+// WithReaderDeferredStart creates the reader without starting it; call Start
+// (or Block.Start) to begin reading.
+func WithReaderDeferredStart[R any]() ReaderOption[R] {
+	return func(r *Reader[R]) {
+		r.deferStart()
+	}
+}
+
 // NewReader creates a new reader instance with functional options.
 // The reader function is required as the first parameter, with optional
 // configuration via functional options.
@@ -61,20 +238,70 @@ func NewReader[R any](read ReaderFunc[R], opts ...ReaderOption[R]) *Reader[R] {
 		closedChan: make(chan error, 1),
 		msgChannel: make(chan Message[R]), // default unbuffered
 	}
+	out.component = "Reader"
 
 	// Apply options
 	for _, opt := range opts {
 		opt(out)
 	}
 
-	out.start()
+	if !out.startPending.Load() {
+		out.start()
+	}
 	return out
 }
 
+// NewReaderCtx creates a reader like NewReader whose read function receives
+// a context that is cancelled as soon as the reader is asked to stop (or its
+// WithReaderContext context is done). A read blocked on something that
+// cannot be interrupted by the reader itself, such as conn.Read, can watch
+// ctx and abort, e.g. by setting a past read deadline:
+//
+//	reader := NewReaderCtx(func(ctx context.Context) ([]byte, error) {
+//	    stop := context.AfterFunc(ctx, func() { conn.SetReadDeadline(time.Now()) })
+//	    defer stop()
+//	    buf := make([]byte, 4096)
+//	    n, err := conn.Read(buf)
+//	    return buf[:n], err
+//	})
+//
+// Combined with WithReaderStopHandshake this makes Stop() return only after
+// the aborted read has returned.
+func NewReaderCtx[R any](read ReaderFuncCtx[R], opts ...ReaderOption[R]) *Reader[R] {
+	return NewReader(nil, append([]ReaderOption[R]{withReadCtx(read)}, opts...)...)
+}
+
+// withReadCtx sets the context-aware read function.
+func withReadCtx[R any](read ReaderFuncCtx[R]) ReaderOption[R] {
+	return func(r *Reader[R]) {
+		r.readCtx = read
+	}
+}
+
+// Start starts a reader created with WithReaderDeferredStart. It returns
+// ErrAlreadyRunning if the reader was not deferred or has already been
+// started.
+func (rc *Reader[R]) Start() error {
+	if !rc.claimStart() {
+		return ErrAlreadyRunning
+	}
+	rc.start()
+	return nil
+}
+
 func (r *Reader[R]) DebugInfo() any {
 	return map[string]any{
 		"base":    r.RunnerBase.DebugInfo(),
 		"msgChan": r.msgChannel,
+		"stats":   r.Stats(),
+	}
+}
+
+// Stats returns the reader's cumulative counters. Safe to call from any
+// goroutine.
+func (r *Reader[R]) Stats() ReaderStats {
+	return ReaderStats{
+		BlockedSendDuration: time.Duration(r.blockedSend.Load()),
 	}
 }
 
@@ -83,75 +310,244 @@ func (rc *Reader[R]) OutputChan() <-chan Message[R] {
 	return rc.msgChannel
 }
 
+// ErrorsChan returns the channel on which read errors are delivered when
+// the reader was created with WithSeparateErrors, and nil otherwise.
+func (rc *Reader[R]) ErrorsChan() <-chan error {
+	return rc.errChannel
+}
+
 // ClosedChan returns the channel used to signal when the reader is done.
 func (rc *Reader[R]) ClosedChan() <-chan error {
 	return rc.closedChan
 }
 
+// Restart relaunches a stopped reader with the same ReaderFunc and output
+// channel, which is convenient for reconnect loops. ClosedChan() is re-armed,
+// so call it again after Restart to observe the new run, and Stats() starts
+// from zero. Restart returns ErrAlreadyRunning if the reader is still
+// running, and must not be called concurrently with Stop().
+func (rc *Reader[R]) Restart() error {
+	rc.restartMu.Lock()
+	defer rc.restartMu.Unlock()
+	if err := rc.reset(); err != nil {
+		return err
+	}
+	rc.blockedSend.Store(0)
+	rc.closedChan = make(chan error, 1)
+	rc.start()
+	return nil
+}
+
 func (rc *Reader[R]) start() {
 	rc.RunnerBase.start()
+	// The inner reading goroutine may outlive this run (Read can block), so
+	// it must only ever report to this run's closedChan.
+	closedChan := rc.closedChan
+	// Cancelled once the reader is asked to stop (see NewReaderCtx).
+	parent := rc.ctx
+	if parent == nil {
+		parent = context.Background()
+	}
+	readCtx, cancelRead := context.WithCancel(parent)
 	go func() {
 		defer rc.cleanup()
+		defer cancelRead()
 
 		// Channel to signal the inner goroutine to stop
 		stopReading := make(chan struct{})
+		// Closed by the inner goroutine when it returns
+		readerDone := make(chan struct{})
 
 		go func() {
+			defer close(readerDone)
+			if rc.release != nil {
+				defer rc.release()
+			}
 			// Recover from any panics (e.g., send on closed closedChan).
 			defer func() { recover() }()
-			for {
+			for first := true; ; first = false {
 				// Check if we should stop before calling Read
 				select {
 				case <-stopReading:
 					return
 				default:
 				}
+				if !first && !rc.pollWait(stopReading) {
+					return
+				}
 
-				newMessage, err := rc.Read()
+				if !acquireOr(rc.inFlight, stopReading, nil) {
+					return
+				}
+				newMessage, err := rc.read(readCtx)
+				if err == errSeqDone {
+					// The sequence is exhausted: stop with a nil error.
+					rc.inFlight.Release()
+					go rc.Stop()
+					return
+				}
 				timedOut := false
 				if err != nil {
 					nerr, ok := err.(net.Error)
 					if ok {
 						timedOut = nerr.Timeout()
 					}
-					log.Println("Net Error, TimedOut, Closed, errors.Is.ErrClosed: ", nerr, timedOut, errors.Is(err, net.ErrClosed), nil)
+					logf("Net Error, TimedOut, Closed, errors.Is.ErrClosed: %v %v %v", nerr, timedOut, errors.Is(err, net.ErrClosed))
+				}
+				// A read error ends reading unless the callback skips it.
+				if err != nil && !timedOut && rc.onError != nil && rc.onError(err) {
+					rc.inFlight.Release()
+					continue
 				}
 
 				// Try to send, but respect stop signal
-				if !timedOut && !errors.Is(err, net.ErrClosed) {
-					select {
-					case <-stopReading:
-						return
-					case rc.msgChannel <- Message[R]{
+				if (!timedOut || rc.emitTimeout) && !errors.Is(err, net.ErrClosed) {
+					msg := Message[R]{
 						Value: newMessage,
 						Error: err,
-					}:
+					}
+					sent, stopped := rc.send(msg, stopReading)
+					if stopped {
+						rc.inFlight.Release()
+						return
+					}
+					if !sent {
+						if rc.onDrop == nil {
+							rc.inFlight.Release()
+							logf("Send Timeout: %v", ErrSendTimeout)
+							err := rc.wrapErr(ErrSendTimeout)
+							rc.setErr(err)
+							select {
+							case <-stopReading:
+							case closedChan <- err:
+								go rc.Stop()
+							}
+							return
+						}
+						rc.onDrop(msg)
 					}
 				}
+				rc.inFlight.Release()
 
 				if err != nil && !timedOut {
-					slog.Debug("Read Error: ", "error", err)
+					logf("Read Error: %v", err)
+					if errors.Is(err, io.EOF) || errors.Is(err, net.ErrClosed) {
+						err = &reasonError{reason: ErrReaderClosed, err: err}
+					}
+					err = rc.wrapErr(err)
+					rc.setErr(err)
 					select {
 					case <-stopReading:
 						return
-					case rc.closedChan <- err:
+					case closedChan <- err:
 					}
-					break
+					// Without a callback or read deadline reading just ends,
+					// as it always has, and the reader runs until Stop().
+					if rc.onError != nil || rc.setDeadline != nil {
+						go rc.Stop()
+					}
+					return
 				}
 			}
 		}()
 
-		// Wait for control signal to stop
-		<-rc.controlChan
+		// Wait for control signal to stop (or for the context to be done)
+		select {
+		case <-rc.controlChan:
+		case <-rc.ctxDone():
+			rc.setErr(rc.contextErr())
+			select {
+			case rc.closedChan <- rc.contextErr():
+			default:
+			}
+		}
 		// Signal the reading goroutine to stop. It will exit when Read()
-		// returns and it sees stopReading closed. We don't wait for it
-		// because Read() may block indefinitely (e.g., network read).
+		// returns and it sees stopReading closed. By default we don't wait
+		// for it because Read() may block indefinitely (e.g., network read).
 		close(stopReading)
+		cancelRead()
+		if rc.stopWait < 0 {
+			<-readerDone
+		} else if rc.stopWait > 0 {
+			timer := time.NewTimer(rc.stopWait)
+			defer timer.Stop()
+			select {
+			case <-readerDone:
+			case <-timer.C:
+			}
+		}
 	}()
 }
 
+// pollWait waits for the next poll interval, if one is configured. It
+// returns false if the reader was stopped while waiting.
+func (rc *Reader[R]) pollWait(stop <-chan struct{}) bool {
+	if rc.pollInterval <= 0 {
+		return true
+	}
+	timer := clockOr(rc.clock).NewTimer(rc.nextPollInterval())
+	defer timer.Stop()
+	select {
+	case <-stop:
+		return false
+	case <-timer.C():
+		return true
+	}
+}
+
+// nextPollInterval returns the poll interval with jitter applied.
+func (rc *Reader[R]) nextPollInterval() time.Duration {
+	d := float64(rc.pollInterval)
+	return time.Duration(d + (rand.Float64()*2-1)*rc.jitter*d)
+}
+
+// read calls Read, or the NewReaderCtx function with ctx, first setting the
+// read deadline if one is configured.
+func (rc *Reader[R]) read(ctx context.Context) (R, error) {
+	if rc.setDeadline != nil {
+		if err := rc.setDeadline(time.Now().Add(rc.readTimeout)); err != nil {
+			var zero R
+			return zero, err
+		}
+	}
+	if rc.readCtx != nil {
+		return rc.readCtx(ctx)
+	}
+	return rc.Read()
+}
+
+// send delivers msg on OutputChan(), or its error on ErrorsChan() with
+// WithSeparateErrors, giving up after the send timeout if one is
+// configured. It reports whether msg was sent and whether the reader was
+// stopped while waiting.
+func (rc *Reader[R]) send(msg Message[R], stop <-chan struct{}) (sent, stopped bool) {
+	var timeout <-chan time.Time
+	if rc.sendTimeout > 0 {
+		timer := time.NewTimer(rc.sendTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	// Exactly one of msgs and errs is non-nil.
+	msgs, errs := rc.msgChannel, chan error(nil)
+	if msg.Error != nil && rc.errChannel != nil {
+		msgs, errs = nil, rc.errChannel
+	}
+	start := time.Now()
+	defer func() { rc.blockedSend.Add(int64(time.Since(start))) }()
+	select {
+	case <-stop:
+		return false, true
+	case msgs <- msg:
+		return true, false
+	case errs <- msg.Error:
+		return true, false
+	case <-timeout:
+		return false, false
+	}
+}
+
 func (r *Reader[T]) cleanup() {
-	defer log.Println("Cleaned up reader...")
+	defer logf("Cleaned up reader...")
 	if r.OnDone != nil {
 		r.OnDone(r)
 	}
@@ -2,6 +2,7 @@ package gocurrent
 
 import (
 	"errors"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -86,3 +87,47 @@ func TestReaderRace_StopDuringBlockingRead(t *testing.T) {
 		reader.Stop()
 	}
 }
+
+// TestReaderRace_StopHandshake repeatedly stops readers mid-read with a stop
+// handshake and checks that no Read call is still running, or starts, once
+// Stop() has returned.
+// Run with: go test -run TestReaderRace_StopHandshake -race
+func TestReaderRace_StopHandshake(t *testing.T) {
+	for i := 0; i < 200; i++ {
+		var inRead, stopped atomic.Bool
+		reader := NewReader(func() (int, error) {
+			inRead.Store(true)
+			if stopped.Load() {
+				t.Error("Read called after Stop returned")
+			}
+			time.Sleep(50 * time.Microsecond)
+			inRead.Store(false)
+			return i, nil
+		}, WithReaderStopHandshake[int](-1), WithOutputBuffer[int](4))
+
+		<-reader.OutputChan()
+		reader.Stop()
+		stopped.Store(true)
+		if inRead.Load() {
+			t.Fatalf("Iteration %d: Read still running after Stop returned", i)
+		}
+	}
+}
+
+// TestReaderStopHandshakeBounded verifies a positive handshake wait does not
+// hang Stop() on a Read that never returns.
+func TestReaderStopHandshakeBounded(t *testing.T) {
+	block := make(chan struct{})
+	defer close(block)
+	reader := NewReader(func() (int, error) {
+		<-block
+		return 0, nil
+	}, WithReaderStopHandshake[int](20*time.Millisecond))
+
+	time.Sleep(time.Millisecond)
+	start := time.Now()
+	reader.Stop()
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond || elapsed > time.Second {
+		t.Errorf("Expected Stop to wait about 20ms for the blocked Read, took %v", elapsed)
+	}
+}
@@ -1,8 +1,14 @@
 package gocurrent
 
 import (
+	"context"
+	"errors"
+	"io"
 	"log"
+	"net"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -41,3 +47,359 @@ func TestReader(t *testing.T) {
 		assert.Equal(t, true, results[i], "Out vals dont match")
 	}
 }
+
+// TestReaderOnErrorContinue verifies that errors classified as "continue"
+// are skipped and the reader keeps delivering values.
+func TestReaderOnErrorContinue(t *testing.T) {
+	transient := errors.New("transient")
+	var calls atomic.Int32
+	var n int
+	reader := NewReader(func() (int, error) {
+		n++
+		if n%2 == 0 {
+			return 0, transient
+		}
+		return n, nil
+	}, WithOnError[int](func(err error) bool {
+		calls.Add(1)
+		return errors.Is(err, transient)
+	}))
+	defer reader.Stop()
+
+	for _, want := range []int{1, 3, 5} {
+		select {
+		case msg := <-reader.OutputChan():
+			assert.NoError(t, msg.Error)
+			assert.Equal(t, want, msg.Value)
+		case <-time.After(time.Second):
+			t.Fatal("Timeout waiting for value after a continued error")
+		}
+	}
+	assert.True(t, reader.IsRunning())
+	assert.GreaterOrEqual(t, calls.Load(), int32(2))
+	select {
+	case err := <-reader.ClosedChan():
+		t.Fatalf("Continued errors should not be reported, got %v", err)
+	default:
+	}
+}
+
+// TestReaderOnErrorTerminate verifies that a "terminate" classification
+// reports the error and stops the reader.
+func TestReaderOnErrorTerminate(t *testing.T) {
+	fatal := errors.New("fatal")
+	reader := NewReader(func() (int, error) {
+		return 0, fatal
+	}, WithOnError[int](func(err error) bool { return false }))
+	defer reader.Stop()
+
+	msg := <-reader.OutputChan()
+	assert.ErrorIs(t, msg.Error, fatal)
+	select {
+	case err := <-reader.ClosedChan():
+		assert.ErrorIs(t, err, fatal)
+	case <-time.After(time.Second):
+		t.Fatal("Timeout waiting for ClosedChan")
+	}
+	select {
+	case <-reader.Done():
+	case <-time.After(time.Second):
+		t.Fatal("Reader should stop after a terminate-classified error")
+	}
+	assert.False(t, reader.IsRunning())
+}
+
+// TestReaderErrorEndsReadingWithoutOnError verifies that without a
+// WithOnError callback a read error ends reading, so the source is not read
+// again, while the reader keeps running until it is stopped.
+func TestReaderErrorEndsReadingWithoutOnError(t *testing.T) {
+	var calls atomic.Int32
+	var done atomic.Bool
+	reader := NewReader(func() (int, error) {
+		calls.Add(1)
+		return 0, io.EOF
+	}, WithOnDone(func(*Reader[int]) { done.Store(true) }))
+	defer reader.Stop()
+
+	msg := <-reader.OutputChan()
+	assert.ErrorIs(t, msg.Error, io.EOF)
+	select {
+	case err := <-reader.ClosedChan():
+		assert.ErrorIs(t, err, io.EOF)
+	case <-time.After(time.Second):
+		t.Fatal("Timeout waiting for ClosedChan")
+	}
+	select {
+	case msg := <-reader.OutputChan():
+		t.Fatalf("Unexpected message after the error: %v", msg)
+	case <-reader.Done():
+		t.Fatal("Reader should not stop itself without an OnError callback")
+	case <-time.After(50 * time.Millisecond):
+	}
+	assert.True(t, reader.IsRunning())
+	assert.False(t, done.Load(), "OnDone should wait for Stop")
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+// TestReaderSeparateErrors verifies that with WithSeparateErrors a read
+// error is delivered on ErrorsChan and not in the value stream.
+func TestReaderSeparateErrors(t *testing.T) {
+	failed := errors.New("failed")
+	var n int
+	reader := NewReader(func() (int, error) {
+		n++
+		if n == 2 {
+			return 0, failed
+		}
+		return n, nil
+	}, WithSeparateErrors[int]())
+	defer reader.Stop()
+
+	var values []int
+	var errs []error
+	for len(values) < 1 || len(errs) < 1 {
+		select {
+		case msg := <-reader.OutputChan():
+			assert.NoError(t, msg.Error)
+			values = append(values, msg.Value)
+		case err := <-reader.ErrorsChan():
+			errs = append(errs, err)
+		case <-time.After(time.Second):
+			t.Fatal("Timeout waiting for reader output")
+		}
+	}
+	assert.Equal(t, []int{1}, values)
+	assert.ErrorIs(t, errs[0], failed)
+	assert.Nil(t, NewReader(func() (int, error) { return 0, nil }, WithReaderDeferredStart[int]()).ErrorsChan())
+}
+
+// TestReaderCtxCancelledOnStop verifies that the context passed to a
+// NewReaderCtx read is cancelled by Stop, so a blocked read returns and a
+// stop handshake completes.
+func TestReaderCtxCancelledOnStop(t *testing.T) {
+	reading := make(chan struct{}, 1)
+	aborted := make(chan error, 1)
+	reader := NewReaderCtx(func(ctx context.Context) (int, error) {
+		reading <- struct{}{}
+		<-ctx.Done()
+		aborted <- ctx.Err()
+		return 0, ctx.Err()
+	}, WithReaderStopHandshake[int](-1))
+
+	withTimeout(t, reading)
+	stopped := make(chan error, 1)
+	go func() { stopped <- reader.Stop() }()
+	select {
+	case err := <-stopped:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("Stop should not wait for a read that respects ctx")
+	}
+	assert.ErrorIs(t, withTimeout(t, aborted), context.Canceled)
+}
+
+// TestReaderCtxParentContext verifies that NewReaderCtx reads also see the
+// WithReaderContext context being cancelled.
+func TestReaderCtxParentContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	reading := make(chan struct{}, 1)
+	reader := NewReaderCtx(func(readCtx context.Context) (int, error) {
+		reading <- struct{}{}
+		<-readCtx.Done()
+		return 0, readCtx.Err()
+	}, WithReaderContext[int](ctx))
+	defer reader.Stop()
+
+	withTimeout(t, reading)
+	cancel()
+	select {
+	case err := <-reader.ClosedChan():
+		assert.ErrorIs(t, err, context.Canceled)
+	case <-time.After(time.Second):
+		t.Fatal("Timeout waiting for ClosedChan")
+	}
+	select {
+	case <-reader.Done():
+	case <-time.After(time.Second):
+		t.Fatal("Reader should stop when its context is cancelled")
+	}
+}
+
+// TestReaderBlockedSendDuration verifies that time spent waiting on a slow
+// consumer is accounted in Stats.
+func TestReaderBlockedSendDuration(t *testing.T) {
+	reader := NewReader(func() (int, error) { return 1, nil })
+	defer reader.Stop()
+
+	for i := 0; i < 3; i++ {
+		time.Sleep(20 * time.Millisecond) // deliberately slow consumer
+		<-reader.OutputChan()
+	}
+	assert.GreaterOrEqual(t, reader.Stats().BlockedSendDuration, 40*time.Millisecond)
+}
+
+// TestReaderSendTimeoutTerminates verifies a reader whose consumer never
+// drains stops with ErrSendTimeout instead of blocking forever.
+func TestReaderSendTimeoutTerminates(t *testing.T) {
+	reader := NewReader(func() (int, error) { return 1, nil },
+		WithSendTimeout[int](20*time.Millisecond))
+	defer reader.Stop()
+
+	select {
+	case err := <-reader.ClosedChan():
+		assert.ErrorIs(t, err, ErrSendTimeout)
+	case <-time.After(time.Second):
+		t.Fatal("Timeout waiting for ErrSendTimeout")
+	}
+	select {
+	case <-reader.Done():
+	case <-time.After(time.Second):
+		t.Fatal("Reader should stop after a send timeout")
+	}
+}
+
+// TestReaderSendTimeoutDrops verifies that with WithOnDrop, undelivered
+// messages are dropped and the reader keeps running.
+func TestReaderSendTimeoutDrops(t *testing.T) {
+	var dropped atomic.Int32
+	var n atomic.Int32
+	reader := NewReader(func() (int, error) { return int(n.Add(1)), nil },
+		WithSendTimeout[int](5*time.Millisecond),
+		WithOnDrop(func(Message[int]) { dropped.Add(1) }))
+	defer reader.Stop()
+
+	assert.Eventually(t, func() bool { return dropped.Load() >= 3 }, time.Second, time.Millisecond)
+	assert.True(t, reader.IsRunning())
+	msg := <-reader.OutputChan()
+	assert.Greater(t, msg.Value, 3, "Dropped messages should not be redelivered")
+}
+
+// fakeTimeout is a net.Error reporting a read timeout.
+type fakeTimeout struct{}
+
+func (fakeTimeout) Error() string   { return "i/o timeout" }
+func (fakeTimeout) Timeout() bool   { return true }
+func (fakeTimeout) Temporary() bool { return true }
+
+// TestReaderReadDeadline verifies that WithReadDeadline sets a deadline
+// before every read, retries timed-out reads, and stops on a real error.
+func TestReaderReadDeadline(t *testing.T) {
+	broken := errors.New("connection reset")
+	var deadlines, reads atomic.Int32
+	reader := NewReader(func() (string, error) {
+		switch reads.Add(1) {
+		case 1, 2, 3:
+			return "", fakeTimeout{}
+		case 4:
+			return "data", nil
+		default:
+			return "", broken
+		}
+	}, WithReadDeadline[string](time.Second, func(deadline time.Time) error {
+		assert.True(t, deadline.After(time.Now()))
+		deadlines.Add(1)
+		return nil
+	}))
+	defer reader.Stop()
+
+	msg := withTimeout(t, reader.OutputChan())
+	assert.Equal(t, "data", msg.Value)
+	assert.NoError(t, msg.Error)
+	assert.ErrorIs(t, withTimeout(t, reader.OutputChan()).Error, broken)
+	assert.ErrorIs(t, withTimeout(t, reader.ClosedChan()), broken)
+	withTimeout(t, reader.Done())
+	assert.Equal(t, int32(5), reads.Load())
+	assert.Equal(t, reads.Load(), deadlines.Load())
+}
+
+// TestReaderEmitOnTimeout verifies that timed-out reads are delivered as
+// messages when WithEmitOnTimeout is set, without stopping the reader.
+func TestReaderEmitOnTimeout(t *testing.T) {
+	var reads atomic.Int32
+	reader := NewReader(func() (int, error) {
+		n := reads.Add(1)
+		if n%2 == 1 {
+			return 0, fakeTimeout{}
+		}
+		return int(n), nil
+	}, WithEmitOnTimeout[int](true))
+	defer reader.Stop()
+
+	for i := 0; i < 3; i++ {
+		msg := withTimeout(t, reader.OutputChan())
+		var nerr net.Error
+		assert.True(t, errors.As(msg.Error, &nerr) && nerr.Timeout(), "expected a timeout message")
+		msg = withTimeout(t, reader.OutputChan())
+		assert.NoError(t, msg.Error)
+		assert.Equal(t, 2*(i+1), msg.Value)
+	}
+	select {
+	case err := <-reader.ClosedChan():
+		t.Fatalf("Timeouts should not be reported on ClosedChan, got %v", err)
+	default:
+	}
+	assert.True(t, reader.IsRunning())
+}
+
+func TestReaderPollInterval(t *testing.T) {
+	var reads atomic.Int32
+	reader := NewReader(func() (int, error) {
+		return int(reads.Add(1)), nil
+	}, WithPollInterval[int](30*time.Millisecond), WithOutputBuffer[int](10))
+
+	start := time.Now()
+	for i := 1; i <= 3; i++ {
+		msg := withTimeout(t, reader.OutputChan())
+		assert.Equal(t, i, msg.Value)
+	}
+	assert.GreaterOrEqual(t, time.Since(start), 60*time.Millisecond,
+		"Three reads should span at least two poll intervals")
+	reader.Stop()
+}
+
+// TestReaderPollIntervalClock verifies that the poll interval is timed by
+// the reader's clock.
+func TestReaderPollIntervalClock(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	var reads atomic.Int32
+	reader := NewReader(func() (int, error) {
+		return int(reads.Add(1)), nil
+	}, WithPollInterval[int](time.Minute), WithReaderClock[int](clock))
+	defer reader.Stop()
+
+	assert.Equal(t, 1, withTimeout(t, reader.OutputChan()).Value)
+	clock.BlockUntil(1)
+	select {
+	case msg := <-reader.OutputChan():
+		t.Fatalf("Read %v before the poll interval elapsed", msg)
+	case <-time.After(20 * time.Millisecond):
+	}
+	clock.Advance(time.Minute)
+	assert.Equal(t, 2, withTimeout(t, reader.OutputChan()).Value)
+}
+
+func TestReaderJitter(t *testing.T) {
+	interval := 100 * time.Millisecond
+	unstarted := &Reader[int]{}
+	WithPollInterval[int](interval)(unstarted)
+	WithJitter[int](0.2)(unstarted)
+
+	lo, hi := interval, interval
+	for range 1000 {
+		d := unstarted.nextPollInterval()
+		assert.GreaterOrEqual(t, d, 80*time.Millisecond)
+		assert.LessOrEqual(t, d, 120*time.Millisecond)
+		lo, hi = min(lo, d), max(hi, d)
+	}
+	// The intervals spread across the band rather than repeating.
+	assert.Less(t, lo, 90*time.Millisecond)
+	assert.Greater(t, hi, 110*time.Millisecond)
+
+	// A reader waiting out a long jittered interval still stops promptly.
+	reader := NewReader(func() (int, error) { return 0, nil },
+		WithPollInterval[int](time.Hour), WithJitter[int](0.2))
+	withTimeout(t, reader.OutputChan())
+	stopped := make(chan error, 1)
+	go func() { stopped <- reader.Stop() }()
+	assert.NoError(t, withTimeout(t, stopped))
+}
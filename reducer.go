@@ -1,10 +1,19 @@
 package gocurrent
 
 import (
+	"context"
+	"errors"
+	"reflect"
+	"slices"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
+// ErrPendingFull is returned by Reducer.SendContext when the reducer already
+// holds its configured maximum of pending (unflushed) inputs.
+var ErrPendingFull = errors.New("reducer pending collection is full")
+
 // Reducer is a way to collect messages of type T in some kind of window
 // and reduce them to type U. For example this could be used to batch messages
 // into a list every 10 seconds. Alternatively if a time based window is not
@@ -18,13 +27,80 @@ type Reducer[T any, C any, U any] struct {
 	pendingEvents C
 	selfOwnIn     bool
 	inputChan     chan T
+	inputBuffer   int
 	selfOwnOut    bool
 	outputChan    chan U
+	outputBuffer  int
 	cmdChan       chan reducerCmd[U]
 	closedChan    chan error
+	done          chan struct{} // closed when the reducer goroutine exits
 	wg            sync.WaitGroup
+
+	// OnFlush, if set, is called from the reducer goroutine at every flush
+	// with the collection and its reduced value, before the value is sent
+	// to the output channel. Set it via WithOnFlush.
+	OnFlush func(collectedItems C, reducedOutputs U)
+
+	// ReduceManyFunc, if set, is used instead of ReduceFunc and may reduce a
+	// collection to any number of outputs, sent one at a time. Set it via
+	// WithReduceManyFunc.
+	ReduceManyFunc func(collectedItems C) (reducedOutputs []U)
+
+	logger          Logger
+	deadlockTimeout time.Duration
+	clock           Clock
+
+	// orderBatch optionally reorders the collection before it is reduced
+	// (see WithEmitOrder).
+	orderBatch func(C) C
+
+	// Acknowledged outputs (see AckableBatch and WithAckTimeout)
+	ackTimeout   time.Duration
+	redeliveries atomic.Int64
+
+	// newCollection, if set, creates the collection of each window instead
+	// of the zero value (see WithInitialCapacity).
+	newCollection func() C
+
+	// Output overflow handling (see WithOutputOverflow)
+	overflow       OverflowPolicy
+	overflowMerge  func(unsent U, next C) C
+	unsent         U
+	hasUnsent      bool
+	droppedBatches atomic.Int64
+
+	// Bounded collection mode (see WithMaxPending)
+	maxPending int64
+	pending    atomic.Int64
+
+	// Monitoring (see Pending and LastFlushAt)
+	countFunc   func(C) int
+	pendingLen  atomic.Int64
+	lastFlushAt atomic.Int64 // UnixNano, 0 until the first flush
 }
 
+// OverflowPolicy controls what a Reducer does at flush time when its output
+// channel cannot accept the reduced value immediately (an unbuffered channel
+// with no ready reader, or a full buffered channel).
+type OverflowPolicy int
+
+const (
+	// OverflowBlock blocks the reducer goroutine until the output is sent.
+	// This is the default and the historical behavior. While blocked the
+	// reducer neither collects input nor handles Flush/Stop.
+	OverflowBlock OverflowPolicy = iota
+
+	// OverflowDropBatch discards the reduced value and counts it (see
+	// DroppedBatches). The reducer never blocks on its output.
+	OverflowDropBatch
+
+	// OverflowKeepAndMerge retains the unsent reduced value and merges it
+	// into the next window's collection (via the function given to
+	// WithOverflowMerge) before that window is reduced. Nothing is lost and
+	// the reducer never blocks on its output.
+	OverflowKeepAndMerge
+)
+
 type reducerCmd[T any] struct {
 	Name    string
 	Channel chan T
@@ -56,6 +132,28 @@ func WithOutputChan[T any, C any, U any](ch chan U) ReducerOption[T, C, U] {
 	}
 }
 
+// WithReducerInputBuffer gives the reducer-owned input channel a buffer of
+// size values, so bursts from fast producers are absorbed without each Send
+// waiting for the reducer goroutine, e.g. while it is flushing. Buffered
+// values are held in memory in addition to the pending collection, and are
+// discarded if the reducer stops before collecting them. It has no effect
+// when the input channel is supplied via WithInputChan.
+func WithReducerInputBuffer[T any, C any, U any](size int) ReducerOption[T, C, U] {
+	return func(r *Reducer[T, C, U]) {
+		r.inputBuffer = size
+	}
+}
+
+// WithReducerOutputBuffer gives the reducer-owned output channel a buffer of
+// size reduced values, so flushes do not wait for a slow consumer until the
+// buffer fills. Each buffered value holds a whole batch in memory. It has no
+// effect when the output channel is supplied via WithOutputChan.
+func WithReducerOutputBuffer[T any, C any, U any](size int) ReducerOption[T, C, U] {
+	return func(r *Reducer[T, C, U]) {
+		r.outputBuffer = size
+	}
+}
+
 // WithReduceFunc sets the reduce function for the reducer
 func WithReduceFunc[T any, C any, U any](fn func(C) U) ReducerOption[T, C, U] {
 	return func(r *Reducer[T, C, U]) {
@@ -63,6 +161,17 @@ func WithReduceFunc[T any, C any, U any](fn func(C) U) ReducerOption[T, C, U] {
 	}
 }
 
+// WithReduceManyFunc sets a reduce function that yields several outputs per
+// flush (e.g. one summary per partition), replacing ReduceFunc. Each output
+// is sent to the output channel individually, waiting for the consumer
+// regardless of the OverflowPolicy, and a Stop() between (or during) those
+// sends discards the rest. OnFlush is called once per output.
+func WithReduceManyFunc[T any, C any, U any](fn func(C) []U) ReducerOption[T, C, U] {
+	return func(r *Reducer[T, C, U]) {
+		r.ReduceManyFunc = fn
+	}
+}
+
 // WithCollectFunc sets the collect function for the reducer
 func WithCollectFunc[T any, C any, U any](fn func(C, ...T) (C, bool)) ReducerOption[T, C, U] {
 	return func(r *Reducer[T, C, U]) {
@@ -70,6 +179,85 @@ func WithCollectFunc[T any, C any, U any](fn func(C, ...T) (C, bool)) ReducerOpt
 	}
 }
 
+// WithOnFlush sets a hook run synchronously at every flush with the
+// collection and the value it was reduced to, before that value is sent to
+// the output channel. It suits recording metrics or logs in step with the
+// output; a slow hook delays the flush.
+func WithOnFlush[T any, C any, U any](fn func(C, U)) ReducerOption[T, C, U] {
+	return func(r *Reducer[T, C, U]) {
+		r.OnFlush = fn
+	}
+}
+
+// WithReducerLogger sets the logger used for the reducer's internal
+// diagnostics. By default the package logger (see SetLogger) is used.
+func WithReducerLogger[T any, C any, U any](logger Logger) ReducerOption[T, C, U] {
+	return func(r *Reducer[T, C, U]) {
+		r.logger = logger
+	}
+}
+
+// WithReducerClock sets the clock driving the flush period and LastFlushAt,
+// e.g. a FakeClock in tests. The default is RealClock.
+func WithReducerClock[T any, C any, U any](clock Clock) ReducerOption[T, C, U] {
+	return func(r *Reducer[T, C, U]) {
+		r.clock = clock
+	}
+}
+
+// WithDeadlockDetection warns, via the reducer's logger, when a blocking
+// flush or re-send to OutputChan() stays blocked for longer than timeout.
+// See "Deadlock detection" in the README.
+func WithDeadlockDetection[T any, C any, U any](timeout time.Duration) ReducerOption[T, C, U] {
+	return func(r *Reducer[T, C, U]) {
+		r.deadlockTimeout = timeout
+	}
+}
+
+// WithOutputOverflow sets the policy applied when a flush finds the output
+// channel unable to accept the reduced value. See [OverflowPolicy].
+// OverflowKeepAndMerge also requires [WithOverflowMerge].
+func WithOutputOverflow[T any, C any, U any](policy OverflowPolicy) ReducerOption[T, C, U] {
+	return func(r *Reducer[T, C, U]) {
+		r.overflow = policy
+	}
+}
+
+// WithOverflowMerge sets the function used by OverflowKeepAndMerge to fold a
+// reduced value that could not be sent back into the next window's
+// collection. For an ID reducer this is typically
+// func(unsent, next []T) []T { return append(unsent, next...) }.
+func WithOverflowMerge[T any, C any, U any](merge func(unsent U, next C) C) ReducerOption[T, C, U] {
+	return func(r *Reducer[T, C, U]) {
+		r.overflowMerge = merge
+	}
+}
+
+// WithMaxPending bounds the number of inputs the reducer holds between
+// flushes. Once n inputs have been collected the reducer stops reading its
+// input channel until the next flush, so Send blocks (backpressure) and
+// SendContext returns ErrPendingFull without blocking.
+//
+// This is a hard cap, not a flush trigger: to flush when a batch reaches a
+// size, return true from CollectFunc. If CollectFunc flushes at or below n
+// the cap is only reached while a flush is pending. A value <= 0 (the
+// default) means unbounded.
+func WithMaxPending[T any, C any, U any](n int) ReducerOption[T, C, U] {
+	return func(r *Reducer[T, C, U]) {
+		r.maxPending = int64(n)
+	}
+}
+
+// WithCountFunc sets how Pending() measures the collection. By default slices,
+// maps, arrays, channels and strings report their length and any other
+// collection type reports the number of inputs collected since the last
+// flush.
+func WithCountFunc[T any, C any, U any](fn func(C) int) ReducerOption[T, C, U] {
+	return func(r *Reducer[T, C, U]) {
+		r.countFunc = fn
+	}
+}
+
 // NewReducer creates a reducer over generic input and output types. Options can be
 // provided to configure the input channel, output channel, flush period, etc.
 // If channels are not provided via options, the reducer will create and own them.
@@ -79,6 +267,7 @@ func NewReducer[T any, C any, U any](opts ...ReducerOption[T, C, U]) *Reducer[T,
 		FlushPeriod: 100 * time.Millisecond,
 		cmdChan:     make(chan reducerCmd[U]),
 		closedChan:  make(chan error, 1),
+		done:        make(chan struct{}),
 		selfOwnIn:   true,
 		selfOwnOut:  true,
 	}
@@ -86,12 +275,19 @@ func NewReducer[T any, C any, U any](opts ...ReducerOption[T, C, U]) *Reducer[T,
 	for _, opt := range opts {
 		opt(out)
 	}
+	out.clock = clockOr(out.clock)
+	if out.overflow == OverflowKeepAndMerge && out.overflowMerge == nil {
+		panic("OverflowKeepAndMerge requires WithOverflowMerge")
+	}
 	// Create channels if not provided via options
 	if out.inputChan == nil {
-		out.inputChan = make(chan T)
+		out.inputChan = make(chan T, out.inputBuffer)
 	}
 	if out.outputChan == nil {
-		out.outputChan = make(chan U)
+		out.outputChan = make(chan U, out.outputBuffer)
+	}
+	if out.newCollection != nil {
+		out.pendingEvents = out.newCollection()
 	}
 	out.start()
 	return out
@@ -109,6 +305,57 @@ type Reducer2[T any, C any] = Reducer[T, C, C]
 // ReducerOption2 is a functional option for configuring a Reducer2
 type ReducerOption2[T any, C any] = ReducerOption[T, C, C]
 
+// EmitOrder controls the order of items in batches emitted by the built-in
+// slice collectors.
+type EmitOrder int
+
+const (
+	// OldestFirst emits items in insertion order. This is the default.
+	OldestFirst EmitOrder = iota
+
+	// NewestFirst emits the most recently collected item first.
+	NewestFirst
+)
+
+// WithEmitOrder sets the order in which the built-in slice collectors
+// ([NewIDReducer] and [NewListReducer]) emit each flushed batch. With
+// NewestFirst the collected slice is reversed just before it is handed to
+// ReduceFunc, so the identity reducers emit a LIFO batch.
+//
+// The type parameters mirror the reducer being configured: T is the input
+// type and E the element type of the collected slice, e.g.
+// WithEmitOrder[int, int] for NewIDReducer[int] and WithEmitOrder[[]int, int]
+// for NewListReducer[int]. Custom collectors should order their collection
+// in their own ReduceFunc instead.
+func WithEmitOrder[T any, E any](order EmitOrder) ReducerOption[T, []E, []E] {
+	return func(r *Reducer[T, []E, []E]) {
+		if order == NewestFirst {
+			r.orderBatch = func(batch []E) []E {
+				slices.Reverse(batch)
+				return batch
+			}
+		} else {
+			r.orderBatch = nil
+		}
+	}
+}
+
+// WithInitialCapacity makes the built-in slice collectors ([NewIDReducer] and
+// [NewListReducer]) start every window with a slice of capacity n, instead of
+// a nil slice grown by append. For a known batch size this avoids repeated
+// reallocation while collecting. The type parameters are as for
+// WithEmitOrder, e.g. WithInitialCapacity[int, int](1000) for
+// NewIDReducer[int].
+func WithInitialCapacity[T any, E any](n int) ReducerOption[T, []E, []E] {
+	return func(r *Reducer[T, []E, []E]) {
+		if n > 0 {
+			r.newCollection = func() []E { return make([]E, 0, n) }
+		} else {
+			r.newCollection = nil
+		}
+	}
+}
+
 // NewReducer2 creates a 2-parameter reducer where collection type equals output type.
 // This is a simpler API for the common case where no type transformation is needed.
 func NewReducer2[T any, C any](opts ...ReducerOption2[T, C]) *Reducer2[T, C] {
@@ -170,40 +417,144 @@ func (fo *Reducer[T, C, U]) Send(value T) {
 	fo.inputChan <- value
 }
 
-// Stop stops the reducer and closes all channels it owns.
-func (fo *Reducer[T, C, U]) Stop() {
-	fo.cmdChan <- reducerCmd[U]{Name: "stop"}
+// SendContext sends a value to the reducer, giving up when ctx is done.
+// It returns ErrPendingFull immediately if the reducer is in bounded mode
+// (see WithMaxPending) and already full, ctx.Err() if ctx is done before the
+// value is accepted, and ErrStopped if the reducer has stopped. A sender that
+// passes the full check concurrently with another sender filling the last
+// slot blocks until the next flush or until ctx is done.
+func (fo *Reducer[T, C, U]) SendContext(ctx context.Context, value T) (err error) {
+	if fo.isFull() {
+		return ErrPendingFull
+	}
+	// A self-owned input channel is closed when the reducer exits.
+	defer func() {
+		if recover() != nil {
+			err = ErrStopped
+		}
+	}()
+	select {
+	case fo.inputChan <- value:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-fo.done:
+		return ErrStopped
+	}
+}
+
+// TrySend offers a value to the reducer without blocking. It returns false if
+// the reducer cannot take the value right now: it is busy (e.g. flushing to a
+// slow consumer), at its WithMaxPending cap, or stopped. Intended for
+// best-effort producers such as telemetry.
+func (fo *Reducer[T, C, U]) TrySend(value T) (ok bool) {
+	if fo.isFull() {
+		return false
+	}
+	// A self-owned input channel is closed when the reducer exits.
+	defer func() {
+		if recover() != nil {
+			ok = false
+		}
+	}()
+	select {
+	case fo.inputChan <- value:
+		return true
+	default:
+		return false
+	}
+}
+
+// isFull reports whether the reducer holds its maximum of pending inputs.
+func (fo *Reducer[T, C, U]) isFull() bool {
+	return fo.maxPending > 0 && fo.pending.Load() >= fo.maxPending
+}
+
+// Pending returns the size of the collection gathered since the last flush,
+// as measured by the WithCountFunc function.
+func (fo *Reducer[T, C, U]) Pending() int {
+	return int(fo.pendingLen.Load())
+}
+
+// LastFlushAt returns when the reducer last flushed, or the zero time if it
+// has not flushed yet.
+func (fo *Reducer[T, C, U]) LastFlushAt() time.Time {
+	ns := fo.lastFlushAt.Load()
+	if ns == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, ns)
+}
+
+// count measures the collection for Pending. Only called from the reducer
+// goroutine.
+func (fo *Reducer[T, C, U]) count() int {
+	if fo.countFunc != nil {
+		return fo.countFunc(fo.pendingEvents)
+	}
+	v := reflect.ValueOf(fo.pendingEvents)
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map, reflect.Array, reflect.Chan, reflect.String:
+		return v.Len()
+	}
+	return int(fo.pending.Load())
+}
+
+// DroppedBatches returns the number of reduced values discarded because the
+// output was not ready under OverflowDropBatch.
+func (fo *Reducer[T, C, U]) DroppedBatches() int64 {
+	return fo.droppedBatches.Load()
+}
+
+// Stop stops the reducer and closes all channels it owns. It is safe to call
+// multiple times; calls after the reducer has stopped return immediately.
+func (fo *Reducer[T, C, U]) Stop() error {
+	select {
+	case fo.cmdChan <- reducerCmd[U]{Name: "stop"}:
+	case <-fo.done:
+	}
 	fo.wg.Wait()
+	return nil
 }
 
 func (fo *Reducer[T, C, U]) start() {
-	ticker := time.NewTicker(fo.FlushPeriod)
+	ticker := fo.clock.NewTicker(fo.FlushPeriod)
 	fo.wg.Add(1)
 	go func() {
 		// keep reading from input and send to outputs
 		defer func() {
 			defer ticker.Stop()
 			if fo.selfOwnIn {
-				close(fo.inputChan)
+				DrainAndClose(fo.inputChan)
 			}
 			close(fo.closedChan)
+			close(fo.done)
 			fo.wg.Done()
 		}()
 		for {
+			// In bounded mode stop reading input while full.
+			input := fo.inputChan
+			if fo.isFull() {
+				input = nil
+			}
 			select {
-			case event := <-fo.inputChan:
+			case event := <-input:
+				fo.pending.Add(1)
 				var shouldFlush bool
 				fo.pendingEvents, shouldFlush = fo.CollectFunc(fo.pendingEvents, event)
-				if shouldFlush {
-					fo.doFlush()
+				fo.pendingLen.Store(int64(fo.count()))
+				if shouldFlush && fo.doFlush() {
+					return
+				}
+			case <-ticker.C():
+				if fo.doFlush() {
+					return
 				}
-			case <-ticker.C:
-				fo.doFlush()
 			case cmd := <-fo.cmdChan:
 				if cmd.Name == "stop" {
 					return
-				} else if cmd.Name == "flush" {
-					fo.doFlush()
+				} else if cmd.Name == "flush" && fo.doFlush() {
+					return
 				}
 			}
 		}
@@ -212,15 +563,106 @@ func (fo *Reducer[T, C, U]) start() {
 
 // Flush triggers an immediate flush of pending events by sending a command to
 // the reducer goroutine. This is safe to call from any goroutine.
+// Flushing a stopped reducer is a no-op.
 func (fo *Reducer[T, C, U]) Flush() {
-	fo.cmdChan <- reducerCmd[U]{Name: "flush"}
+	select {
+	case fo.cmdChan <- reducerCmd[U]{Name: "flush"}:
+	case <-fo.done:
+	}
+}
+
+// log returns the reducer's logger, falling back to the package logger.
+func (fo *Reducer[T, C, U]) log() Logger {
+	if fo.logger != nil {
+		return fo.logger
+	}
+	return defaultLogger()
 }
 
 // doFlush is the internal flush method called only from the reducer goroutine.
-// It processes all pending events and sends the result to the output channel.
-func (fo *Reducer[T, C, U]) doFlush() {
-	joinedEvents := fo.ReduceFunc(fo.pendingEvents)
-	var zero C
-	fo.pendingEvents = zero
-	fo.outputChan <- joinedEvents
+// It processes all pending events and sends the result to the output channel,
+// applying the configured OverflowPolicy if the output is not ready. It
+// reports whether the reducer was stopped while flushing.
+func (fo *Reducer[T, C, U]) doFlush() (stopped bool) {
+	if fo.hasUnsent {
+		fo.pendingEvents = fo.overflowMerge(fo.unsent, fo.pendingEvents)
+		var zero U
+		fo.unsent, fo.hasUnsent = zero, false
+	}
+	collected := fo.pendingEvents
+	if fo.orderBatch != nil {
+		collected = fo.orderBatch(collected)
+	}
+	if fo.ReduceManyFunc != nil {
+		outputs := fo.ReduceManyFunc(collected)
+		fo.resetPending()
+		return fo.sendMany(collected, outputs)
+	}
+	joinedEvents := fo.ReduceFunc(collected)
+	fo.resetPending()
+	if fo.OnFlush != nil {
+		fo.OnFlush(collected, joinedEvents)
+	}
+
+	if fo.overflow == OverflowBlock {
+		done := watchBlocking(fo.log(), fo.deadlockTimeout, "Reducer", "send to outputChan")
+		fo.outputChan <- joinedEvents
+		done()
+		return fo.awaitAck(joinedEvents)
+	}
+
+	select {
+	case fo.outputChan <- joinedEvents:
+		return fo.awaitAck(joinedEvents)
+	default:
+		if fo.overflow == OverflowKeepAndMerge {
+			fo.unsent, fo.hasUnsent = joinedEvents, true
+		} else {
+			fo.droppedBatches.Add(1)
+		}
+	}
+	return false
+}
+
+// resetPending starts a new collection after a flush.
+func (fo *Reducer[T, C, U]) resetPending() {
+	if fo.newCollection != nil {
+		fo.pendingEvents = fo.newCollection()
+	} else {
+		var zero C
+		fo.pendingEvents = zero
+	}
+	fo.pending.Store(0)
+	fo.pendingLen.Store(int64(fo.count()))
+	fo.lastFlushAt.Store(fo.clock.Now().UnixNano())
+}
+
+// sendMany sends the outputs of ReduceManyFunc in order, still accepting a
+// stop command between and during the sends. It reports whether the reducer
+// was stopped.
+func (fo *Reducer[T, C, U]) sendMany(collected C, outputs []U) (stopped bool) {
+	for _, out := range outputs {
+		if fo.OnFlush != nil {
+			fo.OnFlush(collected, out)
+		}
+		done := watchBlocking(fo.log(), fo.deadlockTimeout, "Reducer", "send to outputChan")
+		for sent := false; !sent; {
+			select {
+			case fo.outputChan <- out:
+				sent = true
+				done()
+				if fo.awaitAck(out) {
+					return true
+				}
+			case cmd := <-fo.cmdChan:
+				// No input is read while flushing, so a flush request has
+				// nothing to add.
+				if cmd.Name == "stop" {
+					done()
+					return true
+				}
+			}
+		}
+	}
+	return false
 }
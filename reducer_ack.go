@@ -0,0 +1,139 @@
+package gocurrent
+
+import (
+	"sync"
+	"time"
+)
+
+// AckableBatch is a reduced value that its consumer acknowledges once it
+// has been handled, e.g. durably written. A Reducer whose ReduceFunc returns
+// *AckableBatch values does not flush its next window until the batch it
+// last sent is acked, and with WithAckTimeout re-sends an unacked batch.
+// This gives a Reducer→Writer pipeline at-least-once delivery:
+//
+//	reducer := NewReducer(
+//	    WithCollectFunc[Event, []Event, *AckableBatch[[]Event]](appendEvents),
+//	    WithReduceFunc[Event, []Event](func(events []Event) *AckableBatch[[]Event] {
+//	        return &AckableBatch[[]Event]{Value: events}
+//	    }),
+//	    WithAckTimeout[Event, []Event, *AckableBatch[[]Event]](5*time.Second))
+//	writer := NewWriter(func(batch *AckableBatch[[]Event]) error {
+//	    if err := store(batch.Value); err != nil {
+//	        return err // not acked: re-sent after the timeout
+//	    }
+//	    batch.Ack()
+//	    return nil
+//	})
+//
+// The zero value, with Value set, is ready to use.
+type AckableBatch[U any] struct {
+	Value U
+
+	mu    sync.Mutex
+	acked chan struct{}
+	done  bool
+}
+
+// Ack marks the batch as handled. It is safe to call more than once and from
+// any goroutine; acking any delivery of a re-sent batch acks the batch.
+func (b *AckableBatch[U]) Ack() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	ch := b.ackedLocked()
+	if !b.done {
+		b.done = true
+		close(ch)
+	}
+}
+
+// Acked returns a channel that is closed once the batch is acked.
+func (b *AckableBatch[U]) Acked() <-chan struct{} {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.ackedLocked()
+}
+
+func (b *AckableBatch[U]) ackedLocked() chan struct{} {
+	if b.acked == nil {
+		b.acked = make(chan struct{})
+	}
+	return b.acked
+}
+
+// ackable is implemented by reduced values the Reducer waits on.
+type ackable interface {
+	Acked() <-chan struct{}
+}
+
+// WithAckTimeout makes a Reducer whose outputs are *AckableBatch values
+// re-send a batch that has not been acked within d, until it is. Without it
+// the reducer waits for the ack indefinitely. It has no effect on other
+// output types.
+func WithAckTimeout[T any, C any, U any](d time.Duration) ReducerOption[T, C, U] {
+	return func(r *Reducer[T, C, U]) {
+		r.ackTimeout = d
+	}
+}
+
+// Redeliveries returns the number of times an unacked batch was re-sent
+// (see WithAckTimeout).
+func (fo *Reducer[T, C, U]) Redeliveries() int64 {
+	return fo.redeliveries.Load()
+}
+
+// awaitAck waits, if out is ackable, until it is acked, re-sending it every
+// ack timeout. Like a blocked flush it neither collects input nor flushes
+// meanwhile, but still accepts a stop command. It reports whether the
+// reducer was stopped. Only called from the reducer goroutine.
+func (fo *Reducer[T, C, U]) awaitAck(out U) (stopped bool) {
+	batch, ok := any(out).(ackable)
+	if !ok {
+		return false
+	}
+	acked := batch.Acked()
+	var timeout <-chan time.Time
+	var timer Timer
+	if fo.ackTimeout > 0 {
+		timer = fo.clock.NewTimer(fo.ackTimeout)
+		defer timer.Stop()
+		timeout = timer.C()
+	}
+	for {
+		select {
+		case <-acked:
+			return false
+		case <-timeout:
+			fo.redeliveries.Add(1)
+			fo.log().Printf("Reducer batch not acked within %v, re-sending", fo.ackTimeout)
+			if fo.resend(out, acked) {
+				return true
+			}
+			timer.Reset(fo.ackTimeout)
+		case cmd := <-fo.cmdChan:
+			// No input is read while waiting, so a flush request has
+			// nothing to add.
+			if cmd.Name == "stop" {
+				return true
+			}
+		}
+	}
+}
+
+// resend sends an unacked batch again, giving up if it is acked meanwhile.
+// It reports whether the reducer was stopped.
+func (fo *Reducer[T, C, U]) resend(out U, acked <-chan struct{}) (stopped bool) {
+	done := watchBlocking(fo.log(), fo.deadlockTimeout, "Reducer", "re-send to outputChan")
+	defer done()
+	for {
+		select {
+		case fo.outputChan <- out:
+			return false
+		case <-acked:
+			return false
+		case cmd := <-fo.cmdChan:
+			if cmd.Name == "stop" {
+				return true
+			}
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package gocurrent
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"sync"
@@ -519,3 +520,532 @@ func TestReducer2CustomCollectFunc(t *testing.T) {
 	result := withTimeout(t, outputChan)
 	assert.Equal(t, 15, result, "Sum should be 15")
 }
+
+func TestReducerEmitNewestFirst(t *testing.T) {
+	log.Println("============== TestReducerEmitNewestFirst ================")
+	reducer := NewIDReducer(
+		WithFlushPeriod[int, []int, []int](10*time.Second),
+		WithEmitOrder[int, int](NewestFirst))
+	defer reducer.Stop()
+
+	for i := range 3 {
+		reducer.Send(i)
+	}
+	go reducer.Flush()
+
+	batch := withTimeout(t, reducer.OutputChan())
+	assert.Equal(t, []int{2, 1, 0}, batch, "Batch should be newest-first")
+}
+
+func TestListReducerEmitNewestFirst(t *testing.T) {
+	log.Println("============== TestListReducerEmitNewestFirst ================")
+	reducer := NewListReducer(
+		WithFlushPeriod[[]int, []int, []int](10*time.Second),
+		WithEmitOrder[[]int, int](NewestFirst))
+	defer reducer.Stop()
+
+	reducer.Send([]int{0, 1})
+	reducer.Send([]int{2})
+	go reducer.Flush()
+
+	batch := withTimeout(t, reducer.OutputChan())
+	assert.Equal(t, []int{2, 1, 0}, batch, "Batch should be newest-first")
+}
+
+func TestReducerEmitOldestFirstDefault(t *testing.T) {
+	log.Println("============== TestReducerEmitOldestFirstDefault ================")
+	reducer := NewIDReducer(
+		WithFlushPeriod[int, []int, []int](10*time.Second),
+		WithEmitOrder[int, int](OldestFirst))
+	defer reducer.Stop()
+
+	for i := range 3 {
+		reducer.Send(i)
+	}
+	go reducer.Flush()
+
+	batch := withTimeout(t, reducer.OutputChan())
+	assert.Equal(t, []int{0, 1, 2}, batch, "Batch should be in insertion order")
+}
+
+func TestReducerOverflowDropBatch(t *testing.T) {
+	log.Println("============== TestReducerOverflowDropBatch ================")
+	// Nobody ever reads the output channel.
+	reducer := NewIDReducer(
+		WithFlushPeriod[int, []int, []int](10*time.Second),
+		WithOutputOverflow[int, []int, []int](OverflowDropBatch))
+
+	for i := range 3 {
+		reducer.Send(i)
+		reducer.Flush()
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		reducer.Stop()
+		close(stopped)
+	}()
+	withTimeout(t, stopped)
+	assert.Equal(t, int64(3), reducer.DroppedBatches(), "Every batch should have been dropped")
+}
+
+func TestReducerOverflowKeepAndMerge(t *testing.T) {
+	log.Println("============== TestReducerOverflowKeepAndMerge ================")
+	outputChan := make(chan []int, 1)
+	reducer := NewIDReducer(
+		WithOutputChan[int, []int](outputChan),
+		WithFlushPeriod[int, []int, []int](10*time.Second),
+		WithOutputOverflow[int, []int, []int](OverflowKeepAndMerge),
+		WithOverflowMerge[int, []int, []int](func(unsent, next []int) []int {
+			return append(unsent, next...)
+		}))
+
+	// The first batch fills the buffer; the next two overflow and are merged.
+	reducer.Send(1)
+	reducer.Flush()
+	reducer.Send(2)
+	reducer.Flush()
+	reducer.Send(3)
+	reducer.Flush()
+
+	assert.Equal(t, []int{1}, withTimeout(t, outputChan))
+	reducer.Flush()
+	assert.Equal(t, []int{2, 3}, withTimeout(t, outputChan), "Overflowed batches should be merged, not lost")
+	assert.Equal(t, int64(0), reducer.DroppedBatches())
+
+	stopped := make(chan struct{})
+	go func() {
+		reducer.Stop()
+		close(stopped)
+	}()
+	withTimeout(t, stopped)
+}
+
+func TestReducerOverflowBlock(t *testing.T) {
+	log.Println("============== TestReducerOverflowBlock ================")
+	reducer := NewIDReducer(
+		WithFlushPeriod[int, []int, []int](10*time.Second),
+		WithOutputOverflow[int, []int, []int](OverflowBlock))
+	defer reducer.Stop()
+
+	reducer.Send(1)
+	reducer.Flush()
+
+	// The reducer is blocked on the output, so it cannot accept input.
+	select {
+	case reducer.InputChan() <- 2:
+		t.Fatal("Reducer should be blocked on its output under OverflowBlock")
+	case <-time.After(50 * time.Millisecond):
+	}
+	assert.Equal(t, []int{1}, withTimeout(t, reducer.OutputChan()))
+}
+
+func TestReducerKeepAndMergeRequiresMerge(t *testing.T) {
+	assert.Panics(t, func() {
+		NewIDReducer(WithOutputOverflow[int, []int, []int](OverflowKeepAndMerge))
+	})
+}
+
+func TestReducerSendContextPendingFull(t *testing.T) {
+	log.Println("============== TestReducerSendContextPendingFull ================")
+	reducer := NewIDReducer(
+		WithFlushPeriod[int, []int, []int](10*time.Second),
+		WithMaxPending[int, []int, []int](3))
+	defer reducer.Stop()
+
+	ctx := context.Background()
+	for i := range 3 {
+		assert.NoError(t, reducer.SendContext(ctx, i))
+	}
+
+	start := time.Now()
+	err := reducer.SendContext(ctx, 3)
+	assert.ErrorIs(t, err, ErrPendingFull)
+	assert.Less(t, time.Since(start), 100*time.Millisecond, "ErrPendingFull should be returned promptly")
+
+	// A flush frees the collection again.
+	go reducer.Flush()
+	assert.Equal(t, []int{0, 1, 2}, withTimeout(t, reducer.OutputChan()))
+	assert.NoError(t, reducer.SendContext(ctx, 4))
+}
+
+func TestReducerSendContextCancelled(t *testing.T) {
+	log.Println("============== TestReducerSendContextCancelled ================")
+	reducer := NewIDReducer(WithFlushPeriod[int, []int, []int](10 * time.Second))
+
+	// Stall the reducer on its unread output.
+	reducer.Send(1)
+	reducer.Flush()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := reducer.SendContext(ctx, 2)
+	assert.ErrorIs(t, err, context.DeadlineExceeded, "Expected the context error, not ErrPendingFull")
+
+	withTimeout(t, reducer.OutputChan())
+	reducer.Stop()
+	assert.ErrorIs(t, reducer.SendContext(context.Background(), 3), ErrStopped)
+}
+
+func TestReducerOutputBuffer(t *testing.T) {
+	log.Println("============== TestReducerOutputBuffer ================")
+	reducer := NewIDReducer(
+		WithFlushPeriod[int, []int, []int](10*time.Second),
+		WithReducerOutputBuffer[int, []int, []int](3))
+	defer reducer.Stop()
+
+	// With nobody reading, three batches fit in the buffer and the producer
+	// is never blocked.
+	produced := make(chan struct{})
+	go func() {
+		for i := range 3 {
+			reducer.Send(i)
+			reducer.Flush()
+		}
+		reducer.Send(3)
+		close(produced)
+	}()
+	withTimeout(t, produced)
+
+	for i := range 3 {
+		assert.Equal(t, []int{i}, withTimeout(t, reducer.OutputChan()))
+	}
+}
+
+func TestReducerTrySend(t *testing.T) {
+	log.Println("============== TestReducerTrySend ================")
+	reducer := NewIDReducer(WithFlushPeriod[int, []int, []int](10 * time.Second))
+
+	// Wait until the idle reducer accepts a value.
+	deadline := time.Now().Add(testTimeout)
+	for !reducer.TrySend(1) {
+		if time.Now().After(deadline) {
+			t.Fatal("TrySend never succeeded on an idle reducer")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// Nobody reads the output, so the reducer stalls inside the flush.
+	reducer.Flush()
+	assert.False(t, reducer.TrySend(2), "TrySend should fail while the reducer is stalled on output")
+
+	assert.Equal(t, []int{1}, withTimeout(t, reducer.OutputChan()))
+	reducer.Stop()
+	assert.False(t, reducer.TrySend(3), "TrySend should fail once the reducer has stopped")
+}
+
+func TestReducerPendingAndLastFlushAt(t *testing.T) {
+	log.Println("============== TestReducerPendingAndLastFlushAt ================")
+	reducer := NewIDReducer(WithFlushPeriod[int, []int, []int](10 * time.Second))
+	defer reducer.Stop()
+
+	assert.Equal(t, 0, reducer.Pending())
+	assert.True(t, reducer.LastFlushAt().IsZero())
+
+	for i := range 3 {
+		reducer.Send(i)
+		assert.Eventually(t, func() bool { return reducer.Pending() == i+1 },
+			testTimeout, time.Millisecond, "Pending should grow between flushes")
+	}
+
+	before := time.Now()
+	reducer.Flush()
+	assert.Equal(t, []int{0, 1, 2}, withTimeout(t, reducer.OutputChan()))
+	assert.Eventually(t, func() bool { return reducer.Pending() == 0 },
+		testTimeout, time.Millisecond, "Pending should reset after a flush")
+	assert.False(t, reducer.LastFlushAt().Before(before))
+}
+
+func TestReducerCountFunc(t *testing.T) {
+	log.Println("============== TestReducerCountFunc ================")
+	// A sum collection has no length; count it in hundreds instead.
+	reducer := NewReducer(
+		WithFlushPeriod[int, int, int](10*time.Second),
+		WithCollectFunc[int, int, int](func(sum int, inputs ...int) (int, bool) {
+			for _, i := range inputs {
+				sum += i
+			}
+			return sum, false
+		}),
+		WithReduceFunc[int, int, int](IDFunc[int]),
+		WithCountFunc[int, int, int](func(sum int) int { return sum / 100 }))
+	defer reducer.Stop()
+
+	reducer.Send(150)
+	reducer.Send(250)
+	assert.Eventually(t, func() bool { return reducer.Pending() == 4 },
+		testTimeout, time.Millisecond)
+}
+
+func TestReducerOnFlush(t *testing.T) {
+	log.Println("============== TestReducerOnFlush ================")
+	type flush struct {
+		collected []int
+		sum       int
+	}
+	flushes := make(chan flush, 10)
+	reducer := NewReducer(
+		WithFlushPeriod[int, []int, int](10*time.Second),
+		WithCollectFunc[int, []int, int](func(c []int, in ...int) ([]int, bool) {
+			return append(c, in...), false
+		}),
+		WithReduceFunc[int, []int](func(c []int) int {
+			sum := 0
+			for _, v := range c {
+				sum += v
+			}
+			return sum
+		}),
+		WithOnFlush[int](func(c []int, sum int) {
+			flushes <- flush{c, sum}
+		}))
+	defer reducer.Stop()
+
+	for batch := 1; batch <= 3; batch++ {
+		for i := 1; i <= batch; i++ {
+			reducer.Send(i)
+		}
+		assert.Eventually(t, func() bool { return reducer.Pending() == batch },
+			testTimeout, time.Millisecond)
+		reducer.Flush()
+		// The output is unbuffered, so receiving the hook's report before
+		// the output shows the hook ran before the send.
+		f := withTimeout(t, flushes)
+		sum := withTimeout(t, reducer.OutputChan())
+		assert.Equal(t, batch, len(f.collected))
+		assert.Equal(t, sum, f.sum)
+	}
+	assert.Len(t, flushes, 0)
+}
+
+func TestReducerReduceMany(t *testing.T) {
+	log.Println("============== TestReducerReduceMany ================")
+	// One output per parity class present in the batch plus a total.
+	reducer := NewReducer(
+		WithFlushPeriod[int, []int, string](10*time.Second),
+		WithCollectFunc[int, []int, string](func(c []int, in ...int) ([]int, bool) {
+			return append(c, in...), false
+		}),
+		WithReduceManyFunc[int, []int](func(c []int) []string {
+			var odd, even int
+			for _, v := range c {
+				if v%2 == 0 {
+					even++
+				} else {
+					odd++
+				}
+			}
+			return []string{fmt.Sprintf("odd=%d", odd), fmt.Sprintf("even=%d", even), fmt.Sprintf("total=%d", len(c))}
+		}))
+	defer reducer.Stop()
+
+	for i := 1; i <= 5; i++ {
+		reducer.Send(i)
+	}
+	assert.Eventually(t, func() bool { return reducer.Pending() == 5 }, testTimeout, time.Millisecond)
+	reducer.Flush()
+	for _, want := range []string{"odd=3", "even=2", "total=5"} {
+		assert.Equal(t, want, withTimeout(t, reducer.OutputChan()))
+	}
+}
+
+func TestReducerReduceManyStopMidFlush(t *testing.T) {
+	log.Println("============== TestReducerReduceManyStopMidFlush ================")
+	reducer := NewReducer(
+		WithFlushPeriod[int, []int, int](10*time.Second),
+		WithCollectFunc[int, []int, int](func(c []int, in ...int) ([]int, bool) {
+			return append(c, in...), true
+		}),
+		WithReduceManyFunc[int, []int](func(c []int) []int {
+			return make([]int, 1000)
+		}))
+	reducer.Send(1)
+	withTimeout(t, reducer.OutputChan())
+
+	// The flush is blocked sending the rest of a large result; Stop must
+	// not wait for all of it to be consumed.
+	stopped := make(chan error)
+	go func() { stopped <- reducer.Stop() }()
+	assert.NoError(t, withTimeout(t, stopped))
+	withTimeout(t, reducer.ClosedChan())
+}
+
+func TestReducerFakeClock(t *testing.T) {
+	log.Println("============== TestReducerFakeClock ================")
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+	reducer := NewIDReducer(
+		WithFlushPeriod[int, []int, []int](10*time.Second),
+		WithReducerClock[int, []int, []int](clock))
+	defer reducer.Stop()
+
+	reducer.Send(1)
+	reducer.Send(2)
+	clock.Advance(10*time.Second - time.Nanosecond)
+	select {
+	case batch := <-reducer.OutputChan():
+		t.Fatalf("Flushed %v before the period elapsed", batch)
+	default:
+	}
+
+	clock.Advance(time.Nanosecond)
+	assert.Equal(t, []int{1, 2}, withTimeout(t, reducer.OutputChan()))
+	assert.WithinDuration(t, start.Add(10*time.Second), reducer.LastFlushAt(), 0)
+
+	reducer.Send(3)
+	clock.Advance(10 * time.Second)
+	assert.Equal(t, []int{3}, withTimeout(t, reducer.OutputChan()))
+	assert.WithinDuration(t, start.Add(20*time.Second), reducer.LastFlushAt(), 0)
+
+	clock.Advance(10 * time.Second)
+	assert.Empty(t, withTimeout(t, reducer.OutputChan()))
+}
+
+func TestReducerInputBuffer(t *testing.T) {
+	log.Println("============== TestReducerInputBuffer ================")
+	flushing := make(chan struct{}, 1)
+	reducer := NewReducer2(
+		WithFlushPeriod2[int, []int](time.Hour),
+		WithCollectFunc[int, []int, []int](func(c []int, inputs ...int) ([]int, bool) {
+			c = append(c, inputs...)
+			return c, len(c) >= 3
+		}),
+		WithReducerInputBuffer[int, []int, []int](10),
+		WithOnFlush[int, []int, []int](func([]int, []int) {
+			select {
+			case flushing <- struct{}{}:
+			default:
+			}
+		}))
+	defer reducer.Stop()
+
+	for i := range 3 {
+		reducer.Send(i)
+	}
+	// The reducer is now stuck flushing [0 1 2] to an unread output, yet the
+	// next 10 values are accepted without blocking.
+	withTimeout(t, flushing)
+	for i := 3; i < 13; i++ {
+		assert.True(t, reducer.TrySend(i), "Send %d should not block before the buffer fills", i)
+	}
+	assert.False(t, reducer.TrySend(13), "Send should block once the buffer is full")
+
+	for _, batch := range [][]int{{0, 1, 2}, {3, 4, 5}, {6, 7, 8}, {9, 10, 11}} {
+		assert.Equal(t, batch, withTimeout(t, reducer.OutputChan()))
+	}
+}
+
+func TestReducerInitialCapacity(t *testing.T) {
+	log.Println("============== TestReducerInitialCapacity ================")
+	reducer := NewIDReducer(
+		WithFlushPeriod[int, []int, []int](10*time.Second),
+		WithInitialCapacity[int, int](8))
+	defer reducer.Stop()
+
+	for round := range 2 {
+		for i := range 3 {
+			reducer.Send(round*3 + i)
+		}
+		go reducer.Flush()
+		batch := withTimeout(t, reducer.OutputChan())
+		assert.Equal(t, []int{round * 3, round*3 + 1, round*3 + 2}, batch)
+		assert.Equal(t, 8, cap(batch), "Each window should start pre-sized")
+	}
+}
+
+// benchmarkIDReducer sends b.N values to an ID reducer, flushing every
+// batchSize values.
+func benchmarkIDReducer(b *testing.B, opts ...ReducerOption2[int, []int]) {
+	const batchSize = 1024
+	opts = append([]ReducerOption2[int, []int]{WithFlushPeriod[int, []int, []int](time.Hour)}, opts...)
+	reducer := NewIDReducer(opts...)
+	defer reducer.Stop()
+	go func() {
+		for range reducer.OutputChan() {
+		}
+	}()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		reducer.Send(i)
+		if i%batchSize == batchSize-1 {
+			reducer.Flush()
+		}
+	}
+}
+
+func BenchmarkIDReducer(b *testing.B) {
+	benchmarkIDReducer(b)
+}
+
+func BenchmarkIDReducer_InitialCapacity(b *testing.B) {
+	benchmarkIDReducer(b, WithInitialCapacity[int, int](1024))
+}
+
+// newAckReducer creates a reducer emitting every two values as an
+// AckableBatch.
+func newAckReducer(opts ...ReducerOption[int, []int, *AckableBatch[[]int]]) *Reducer[int, []int, *AckableBatch[[]int]] {
+	opts = append([]ReducerOption[int, []int, *AckableBatch[[]int]]{
+		WithFlushPeriod[int, []int, *AckableBatch[[]int]](time.Hour),
+		WithCollectFunc[int, []int, *AckableBatch[[]int]](func(c []int, inputs ...int) ([]int, bool) {
+			c = append(c, inputs...)
+			return c, len(c) >= 2
+		}),
+		WithReduceFunc[int, []int](func(c []int) *AckableBatch[[]int] {
+			return &AckableBatch[[]int]{Value: c}
+		}),
+	}, opts...)
+	return NewReducer(opts...)
+}
+
+func TestReducerAckWaitsBeforeNextFlush(t *testing.T) {
+	log.Println("============== TestReducerAckWaitsBeforeNextFlush ================")
+	reducer := newAckReducer(WithReducerInputBuffer[int, []int, *AckableBatch[[]int]](10))
+	defer reducer.Stop()
+
+	for i := 1; i <= 4; i++ {
+		reducer.Send(i)
+	}
+	first := withTimeout(t, reducer.OutputChan())
+	assert.Equal(t, []int{1, 2}, first.Value)
+	select {
+	case batch := <-reducer.OutputChan():
+		t.Fatalf("Batch %v flushed before the previous one was acked", batch.Value)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	first.Ack()
+	first.Ack()
+	second := withTimeout(t, reducer.OutputChan())
+	assert.Equal(t, []int{3, 4}, second.Value)
+	second.Ack()
+	assert.Equal(t, int64(0), reducer.Redeliveries())
+}
+
+func TestReducerAckTimeoutRedelivers(t *testing.T) {
+	log.Println("============== TestReducerAckTimeoutRedelivers ================")
+	reducer := newAckReducer(WithAckTimeout[int, []int, *AckableBatch[[]int]](50 * time.Millisecond))
+	defer reducer.Stop()
+
+	reducer.Send(1)
+	reducer.Send(2)
+	first := withTimeout(t, reducer.OutputChan())
+	assert.Equal(t, []int{1, 2}, first.Value)
+
+	// Not acked: the same batch is sent again.
+	again := withTimeout(t, reducer.OutputChan())
+	assert.Same(t, first, again)
+	assert.GreaterOrEqual(t, reducer.Redeliveries(), int64(1))
+
+	again.Ack()
+	reducer.Send(3)
+	reducer.Send(4)
+	second := withTimeout(t, reducer.OutputChan())
+	for second == first {
+		// Re-sent again before the ack was seen
+		second = withTimeout(t, reducer.OutputChan())
+	}
+	assert.Equal(t, []int{3, 4}, second.Value)
+	second.Ack()
+}
@@ -0,0 +1,97 @@
+package gocurrent
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReaderRestart(t *testing.T) {
+	var counter atomic.Int64
+	reader := NewReader(func() (int64, error) {
+		time.Sleep(time.Millisecond)
+		return counter.Add(1), nil
+	})
+
+	first := withTimeout(t, reader.OutputChan()).Value
+	assert.ErrorIs(t, reader.Restart(), ErrAlreadyRunning, "Restart on a running reader should fail")
+
+	reader.Stop()
+	assert.Nil(t, withTimeout(t, reader.ClosedChan()))
+	assert.False(t, reader.IsRunning())
+
+	assert.NoError(t, reader.Restart())
+	assert.True(t, reader.IsRunning())
+	assert.Equal(t, StateRunning, reader.State())
+
+	// The restarted reader keeps delivering on the same output channel.
+	next := withTimeout(t, reader.OutputChan()).Value
+	assert.Greater(t, next, first)
+
+	reader.Stop()
+	assert.Nil(t, withTimeout(t, reader.ClosedChan()), "ClosedChan should be re-armed for the new run")
+}
+
+func TestReaderRestartResetsStats(t *testing.T) {
+	// The stop handshake ensures no send of the first run is still being
+	// accounted after Stop returns.
+	reader := NewReader(func() (int, error) { return 1, nil },
+		WithReaderStopHandshake[int](-1))
+	defer reader.Stop()
+
+	time.Sleep(10 * time.Millisecond)
+	withTimeout(t, reader.OutputChan())
+	assert.Eventually(t, func() bool { return reader.Stats().BlockedSendDuration > 0 },
+		time.Second, time.Millisecond)
+
+	reader.Stop()
+	assert.NoError(t, reader.Restart())
+	assert.Equal(t, ReaderStats{}, reader.Stats(), "Stats should start from zero after Restart")
+}
+
+func TestWriterRestartAfterError(t *testing.T) {
+	written := make(chan int, 10)
+	writer := NewWriter(func(val int) error {
+		if val < 0 {
+			return errors.New("negative")
+		}
+		written <- val
+		return nil
+	})
+
+	writer.Send(1)
+	writer.Send(-1)
+	assert.EqualError(t, withTimeout(t, writer.ClosedChan()), "negative")
+	<-writer.Done()
+	assert.False(t, writer.Send(2), "Send should fail on a stopped writer")
+
+	assert.Equal(t, int64(1), writer.Stats().Writes)
+	assert.Equal(t, int64(1), writer.Stats().Errors)
+
+	assert.NoError(t, writer.Restart())
+	assert.Equal(t, WriterStats{}, writer.Stats(), "Stats should start from zero after Restart")
+	assert.True(t, writer.Send(3))
+	assert.Equal(t, 1, withTimeout(t, written))
+	assert.Equal(t, 3, withTimeout(t, written))
+
+	assert.NoError(t, writer.Stop())
+	select {
+	case err, ok := <-writer.ClosedChan():
+		assert.False(t, ok, "ClosedChan should be closed without error, got %v", err)
+	case <-time.After(time.Second):
+		t.Fatal("Timeout waiting for restarted writer to close")
+	}
+}
+
+func TestWriterRestartTwice(t *testing.T) {
+	writer := NewWriter(func(val int) error { return nil })
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, writer.Stop())
+		assert.NoError(t, writer.Restart())
+		assert.True(t, writer.Send(i))
+	}
+	assert.NoError(t, writer.Stop())
+}
@@ -0,0 +1,40 @@
+package gocurrent
+
+import "time"
+
+// RetryPolicy describes how long to wait between attempts to re-establish
+// something that failed, with exponential backoff. The zero value retries
+// immediately and never gives up.
+type RetryPolicy struct {
+	// InitialDelay is the wait before the first retry.
+	InitialDelay time.Duration
+	// MaxDelay caps the wait between retries. Zero means no cap.
+	MaxDelay time.Duration
+	// Multiplier scales the wait after each failed retry. Values below 1
+	// are treated as 1, i.e. a constant delay.
+	Multiplier float64
+	// MaxAttempts is the number of consecutive failed attempts after which
+	// to give up. Zero means retry forever.
+	MaxAttempts int
+}
+
+// Delay returns the wait before retry number attempt (starting at 1).
+func (p RetryPolicy) Delay(attempt int) time.Duration {
+	delay := float64(p.InitialDelay)
+	for i := 1; i < attempt && p.Multiplier > 1; i++ {
+		delay *= p.Multiplier
+		if p.MaxDelay > 0 && delay >= float64(p.MaxDelay) {
+			break
+		}
+	}
+	if p.MaxDelay > 0 && delay > float64(p.MaxDelay) {
+		return p.MaxDelay
+	}
+	return time.Duration(delay)
+}
+
+// exhausted reports whether failures consecutive failed attempts mean giving
+// up.
+func (p RetryPolicy) exhausted(failures int) bool {
+	return p.MaxAttempts > 0 && failures >= p.MaxAttempts
+}
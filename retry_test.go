@@ -0,0 +1,21 @@
+package gocurrent
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryPolicyDelay(t *testing.T) {
+	p := RetryPolicy{InitialDelay: 10 * time.Millisecond, Multiplier: 2, MaxDelay: 50 * time.Millisecond}
+	assert.Equal(t, 10*time.Millisecond, p.Delay(1))
+	assert.Equal(t, 20*time.Millisecond, p.Delay(2))
+	assert.Equal(t, 40*time.Millisecond, p.Delay(3))
+	assert.Equal(t, 50*time.Millisecond, p.Delay(4))
+	assert.Equal(t, 50*time.Millisecond, p.Delay(100))
+
+	constant := RetryPolicy{InitialDelay: time.Second}
+	assert.Equal(t, time.Second, constant.Delay(5))
+	assert.Equal(t, time.Duration(0), RetryPolicy{}.Delay(3))
+}
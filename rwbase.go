@@ -1,11 +1,108 @@
 package gocurrent
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"sync"
 	"sync/atomic"
+	"time"
 )
 
+// ErrStopTimeout is returned by StopWithTimeout when the worker goroutine did
+// not exit in time. The component may still be running and may exit later.
+var ErrStopTimeout = errors.New("timed out waiting for component to stop; it may still be running")
+
+// ErrStopped is returned by context-aware send methods when the component
+// has stopped and can no longer accept values.
+var ErrStopped = errors.New("component stopped")
+
+// ErrAlreadyRunning is returned when starting or restarting a runner whose
+// worker goroutine is still active.
+var ErrAlreadyRunning = errors.New("Channel already running")
+
+// ErrContextCanceled matches (via errors.Is) the error a component reports
+// on ClosedChan() when it stops because its context was cancelled or its
+// deadline passed. The context's own error matches too.
+var ErrContextCanceled = errors.New("component context done")
+
+// ErrReaderClosed matches (via errors.Is) the error a Reader reports on
+// ClosedChan() when its source is exhausted or closed, i.e. Read returned
+// io.EOF or net.ErrClosed. The read error itself matches too.
+var ErrReaderClosed = errors.New("reader source closed")
+
+// ComponentError wraps the errors components report on ClosedChan() with the
+// kind of component that reported it, e.g. "Reader" or "QueuedFanOut".
+// Use errors.As to retrieve it and errors.Is on the error (or Cause) to
+// branch on the reason, such as ErrContextCanceled or ErrReaderClosed.
+type ComponentError struct {
+	Component string
+	Cause     error
+}
+
+// Error returns the cause's message unchanged, so wrapping does not alter
+// what existing callers log or compare.
+func (e *ComponentError) Error() string {
+	return e.Cause.Error()
+}
+
+// Unwrap returns the cause.
+func (e *ComponentError) Unwrap() error {
+	return e.Cause
+}
+
+// reasonError tags an error with a sentinel reason that errors.Is matches in
+// addition to the error itself. Its message is the error's.
+type reasonError struct {
+	reason error
+	err    error
+}
+
+func (e *reasonError) Error() string        { return e.err.Error() }
+func (e *reasonError) Unwrap() error        { return e.err }
+func (e *reasonError) Is(target error) bool { return target == e.reason }
+
+// RunnerState is a lifecycle state of a RunnerBase-based component.
+type RunnerState int32
+
+const (
+	// StateStarting: constructed but the worker goroutine has not started.
+	StateStarting RunnerState = iota
+	// StateRunning: the worker goroutine is active.
+	StateRunning
+	// StateStopping: Stop() has been requested but the worker has not exited.
+	StateStopping
+	// StateStopped: the worker exited without error.
+	StateStopped
+	// StateErrored: the worker exited (or its source failed, ending the
+	// run) with a non-nil error — the same error delivered on the
+	// component's ClosedChan(), including a context error when stopped via
+	// a context. Errors the component carries on past leave it Running.
+	StateErrored
+)
+
+// String returns the state's name.
+func (s RunnerState) String() string {
+	switch s {
+	case StateStarting:
+		return "Starting"
+	case StateRunning:
+		return "Running"
+	case StateStopping:
+		return "Stopping"
+	case StateStopped:
+		return "Stopped"
+	case StateErrored:
+		return "Errored"
+	}
+	return fmt.Sprintf("RunnerState(%d)", int32(s))
+}
+
+// stateChanSize bounds the buffered state channel. A runner goes through at
+// most four transitions per run, so transitions are only dropped for a
+// consumer that never reads.
+const stateChanSize = 8
+
 // RunnerBase is the base of the Reader, Writer, Mapper, FanIn, and FanOut
 // primitives. It provides lifecycle management (start/stop) and coordination
 // between the owner goroutine and the worker goroutine.
@@ -20,14 +117,44 @@ type RunnerBase[C any] struct {
 	isRunning   atomic.Bool
 	wg          sync.WaitGroup
 	stopVal     C
+	component   string // reported in ComponentError; set by composing types
+
+	// ctx optionally ties the runner's lifetime to a context. When set, the
+	// worker goroutine stops itself once ctx is done and reports ctx.Err()
+	// (tagged with ErrContextCanceled) on its ClosedChan().
+	ctx context.Context
+
+	// Lifecycle state. stateMu serializes transitions with sends on the
+	// lazily created stateChan and guards err, onStop and done (which is
+	// replaced on Restart).
+	state     atomic.Int32
+	stateMu   sync.Mutex
+	stateChan chan RunnerState
+	err       error
+	onStop    []func(error) // see RegisterOnStop
+
+	// restartMu serializes Restart calls of composing types.
+	restartMu sync.Mutex
+
+	// startPending is set by deferred-start options; the constructor then
+	// skips starting and the composing type's Start() claims it.
+	startPending atomic.Bool
 }
 
 // NewRunnerBase creates a new base runner. Called by Reader, Writer, Mapper,
 // FanIn, and FanOut constructors. The controlChan is buffered(1) to allow
 // a single stop signal to be sent without blocking.
 func NewRunnerBase[C any](stopVal C) RunnerBase[C] {
+	return NewRunnerBaseN(stopVal, 1)
+}
+
+// NewRunnerBaseN is like NewRunnerBase but buffers up to bufSize control
+// messages, so components driven by many commands (FanIn and FanOut Add and
+// Remove) do not make callers wait for each one to be handled. bufSize is
+// raised to 1 if smaller.
+func NewRunnerBaseN[C any](stopVal C, bufSize int) RunnerBase[C] {
 	return RunnerBase[C]{
-		controlChan: make(chan C, 1),
+		controlChan: make(chan C, max(bufSize, 1)),
 		done:        make(chan struct{}),
 		stopVal:     stopVal,
 	}
@@ -38,9 +165,63 @@ func (r *RunnerBase[R]) DebugInfo() any {
 	return map[string]any{
 		"stopVal":   r.stopVal,
 		"isRunning": r.isRunning.Load(),
+		"state":     r.State().String(),
+	}
+}
+
+// State returns the runner's current lifecycle state.
+func (r *RunnerBase[C]) State() RunnerState {
+	return RunnerState(r.state.Load())
+}
+
+// StateChan returns a channel on which lifecycle transitions are emitted. The
+// channel is created on first use (so runners that are never observed pay
+// nothing) and immediately receives the current state. It is buffered and
+// never blocks the worker; transitions are dropped only if the buffer is
+// full. The channel is never closed.
+func (r *RunnerBase[C]) StateChan() <-chan RunnerState {
+	r.stateMu.Lock()
+	defer r.stateMu.Unlock()
+	if r.stateChan == nil {
+		r.stateChan = make(chan RunnerState, stateChanSize)
+		r.stateChan <- r.State()
+	}
+	return r.stateChan
+}
+
+// setState records a lifecycle transition and publishes it on stateChan.
+func (r *RunnerBase[C]) setState(s RunnerState) {
+	r.stateMu.Lock()
+	defer r.stateMu.Unlock()
+	r.state.Store(int32(s))
+	if r.stateChan != nil {
+		select {
+		case r.stateChan <- s:
+		default:
+		}
 	}
 }
 
+// setErr records the error that terminated the runner. Only the first
+// non-nil error is kept.
+func (r *RunnerBase[C]) setErr(err error) {
+	if err == nil {
+		return
+	}
+	r.stateMu.Lock()
+	defer r.stateMu.Unlock()
+	if r.err == nil {
+		r.err = err
+	}
+}
+
+// terminalErr returns the error recorded via setErr, if any.
+func (r *RunnerBase[C]) terminalErr() error {
+	r.stateMu.Lock()
+	defer r.stateMu.Unlock()
+	return r.err
+}
+
 // IsRunning returns true if the runner's worker goroutine is active.
 func (r *RunnerBase[C]) IsRunning() bool {
 	return r.isRunning.Load()
@@ -51,12 +232,24 @@ func (r *RunnerBase[C]) IsRunning() bool {
 // initialization is complete.
 func (r *RunnerBase[C]) start() error {
 	if !r.isRunning.CompareAndSwap(false, true) {
-		return errors.New("Channel already running")
+		return ErrAlreadyRunning
 	}
 	r.wg.Add(1)
+	r.setState(StateRunning)
 	return nil
 }
 
+// deferStart marks the runner to be started later by an explicit Start().
+func (r *RunnerBase[C]) deferStart() {
+	r.startPending.Store(true)
+}
+
+// claimStart reports whether a deferred start is still pending, clearing it
+// so exactly one Start() call launches the worker.
+func (r *RunnerBase[C]) claimStart() bool {
+	return r.startPending.CompareAndSwap(true, false)
+}
+
 // Stop sends a stop signal to the worker goroutine and waits for it to finish.
 // It is safe to call Stop() concurrently, multiple times, or after the worker
 // goroutine has already self-terminated. Only the first call that transitions
@@ -67,6 +260,7 @@ func (r *RunnerBase[C]) Stop() error {
 		// Already stopped (by another Stop() call or by self-termination)
 		return nil
 	}
+	r.setState(StateStopping)
 
 	// Either deliver the stop signal, or observe that the goroutine already
 	// exited (done closed). This select eliminates the old race between
@@ -74,26 +268,150 @@ func (r *RunnerBase[C]) Stop() error {
 	select {
 	case r.controlChan <- r.stopVal:
 		// Stop signal delivered; goroutine will read it and exit.
-	case <-r.done:
+	case <-r.Done():
 		// Goroutine already exited on its own (e.g. write error).
 	}
 	r.wg.Wait()
 	return nil
 }
 
+// StopWithTimeout is like Stop but waits at most d for the worker goroutine
+// to exit. If it has not exited in time an error wrapping ErrStopTimeout is
+// returned; the stop signal has still been delivered (or is still pending),
+// so the component may finish stopping later. Like Stop, it is safe to call
+// multiple times and concurrently with Stop.
+func (r *RunnerBase[C]) StopWithTimeout(d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	if r.isRunning.CompareAndSwap(true, false) {
+		r.setState(StateStopping)
+		select {
+		case r.controlChan <- r.stopVal:
+		case <-r.Done():
+		case <-timer.C:
+			return fmt.Errorf("%w (stop signal not delivered after %v)", ErrStopTimeout, d)
+		}
+	}
+
+	select {
+	case <-r.Done():
+		return nil
+	case <-timer.C:
+		return fmt.Errorf("%w (waited %v)", ErrStopTimeout, d)
+	}
+}
+
 // Done returns a channel that is closed when the runner's worker goroutine exits.
 // Useful for coordinating with other goroutines that need to know when the runner
 // has stopped (e.g., FanIn's pipeClosed callback uses this to avoid sending on
-// controlChan after the FanIn goroutine has exited).
+// controlChan after the FanIn goroutine has exited). Unlike ClosedChan() it
+// carries no error and can be selected on by any number of goroutines; use
+// Wait to also learn the error. A restarted component has a new Done channel.
 func (r *RunnerBase[C]) Done() <-chan struct{} {
+	r.stateMu.Lock()
+	defer r.stateMu.Unlock()
 	return r.done
 }
 
+// Wait blocks until the runner's worker goroutine exits, without stopping
+// it, and returns the error it terminated with: the error reported on the
+// component's ClosedChan(), or nil. It may be called from any number of
+// goroutines, before or after the runner exits.
+func (r *RunnerBase[C]) Wait() error {
+	<-r.Done()
+	return r.terminalErr()
+}
+
+// reset prepares a stopped runner to be started again by a composing type's
+// Restart method: it waits for the previous worker to exit, discards a stale
+// stop signal, and re-arms done and the terminal error. The caller must hold
+// restartMu. Returns ErrAlreadyRunning if the runner is running.
+func (r *RunnerBase[C]) reset() error {
+	if r.isRunning.Load() {
+		return ErrAlreadyRunning
+	}
+	r.wg.Wait()
+	// Stop() may have delivered a stop signal that the (already exited)
+	// worker never read; it must not stop the next run.
+	select {
+	case <-r.controlChan:
+	default:
+	}
+	r.stateMu.Lock()
+	defer r.stateMu.Unlock()
+	r.done = make(chan struct{})
+	r.err = nil
+	return nil
+}
+
+// RegisterOnStop adds a hook that is called with the terminal error (nil
+// after a plain Stop) each time the component stops, e.g. to flush a log or
+// close a file. Hooks run on the worker goroutine after the component has
+// released its channels and before Done() is closed, so Wait returns only
+// once they have finished; a hook must therefore not wait on the component
+// itself. Hooks run in reverse order of registration, like deferred calls,
+// and stay registered across Restart.
+func (r *RunnerBase[C]) RegisterOnStop(fn func(error)) {
+	r.stateMu.Lock()
+	defer r.stateMu.Unlock()
+	r.onStop = append(r.onStop, fn)
+}
+
+// ctxDone returns the done channel of the runner's context, or nil if no
+// context was configured. A nil channel blocks forever in a select, so worker
+// loops can select on it unconditionally.
+func (r *RunnerBase[C]) ctxDone() <-chan struct{} {
+	if r.ctx == nil {
+		return nil
+	}
+	return r.ctx.Done()
+}
+
+// ctxErr returns the error of the runner's context, or nil if no context was
+// configured or it has not been cancelled.
+func (r *RunnerBase[C]) ctxErr() error {
+	if r.ctx == nil {
+		return nil
+	}
+	return r.ctx.Err()
+}
+
+// wrapErr wraps err in a ComponentError naming this runner's component.
+// nil and already wrapped errors are returned as is.
+func (r *RunnerBase[C]) wrapErr(err error) error {
+	var ce *ComponentError
+	if err == nil || errors.As(err, &ce) {
+		return err
+	}
+	return &ComponentError{Component: r.component, Cause: err}
+}
+
+// contextErr returns the error to report when the runner stops because its
+// context is done: ctx.Err() tagged with ErrContextCanceled and wrapped in a
+// ComponentError.
+func (r *RunnerBase[C]) contextErr() error {
+	return r.wrapErr(&reasonError{reason: ErrContextCanceled, err: r.ctxErr()})
+}
+
 // cleanup is called by composing types (via defer) when their worker goroutine
 // exits. It signals completion via the done channel and decrements the WaitGroup.
 // controlChan is intentionally NOT closed — it is left for garbage collection.
 func (r *RunnerBase[C]) cleanup() {
 	r.isRunning.Store(false)
-	close(r.done)
+	err := r.terminalErr()
+	if err != nil {
+		r.setState(StateErrored)
+	} else {
+		r.setState(StateStopped)
+	}
+	r.stateMu.Lock()
+	done := r.done
+	hooks := r.onStop
+	r.stateMu.Unlock()
+	for i := len(hooks) - 1; i >= 0; i-- {
+		hooks[i](err)
+	}
+	close(done)
 	r.wg.Done()
 }
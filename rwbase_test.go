@@ -1,7 +1,9 @@
 package gocurrent
 
 import (
+	"context"
 	"errors"
+	"slices"
 	"sync"
 	"testing"
 	"time"
@@ -197,3 +199,560 @@ func TestConcurrentStopAndSelfTerminate(t *testing.T) {
 		wg.Wait()
 	}
 }
+
+// TestIsRunningConcurrentWithStop spams IsRunning() from several goroutines
+// (directly and through Block.IsRunning()) while Stop() runs. isRunning is an
+// atomic.Bool, so this must be clean under the race detector.
+// Run with: go test -race -run TestIsRunningConcurrentWithStop
+func TestIsRunningConcurrentWithStop(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		writer := NewWriter(func(val int) error { return nil })
+		fanin := NewFanIn[int]()
+		block := NewBlock("spam")
+		block.Add(writer)
+		block.Add(fanin)
+
+		stop := make(chan struct{})
+		var wg sync.WaitGroup
+		for j := 0; j < 4; j++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for {
+					select {
+					case <-stop:
+						return
+					default:
+						writer.IsRunning()
+						fanin.IsRunning()
+						block.IsRunning()
+					}
+				}
+			}()
+		}
+
+		writer.Stop()
+		fanin.Stop()
+		close(stop)
+		wg.Wait()
+
+		if writer.IsRunning() || fanin.IsRunning() || block.IsRunning() {
+			t.Fatalf("Iteration %d: components should not be running after Stop()", i)
+		}
+	}
+}
+
+// TestTripleConcurrentStop calls Stop() three times concurrently on every
+// RunnerBase primitive. Only the first call delivers the stop signal; the
+// redundant calls must return nil without panicking or deadlocking.
+func TestTripleConcurrentStop(t *testing.T) {
+	components := map[string]Component{
+		"Reader": NewReader(func() (int, error) {
+			time.Sleep(time.Millisecond)
+			return 0, nil
+		}, WithOutputBuffer[int](1000)),
+		"Writer":       NewWriter(func(val int) error { return nil }),
+		"Mapper":       NewMapper(make(chan int), make(chan int), idMapperFunc[int]),
+		"FanIn":        NewFanIn[int](),
+		"QueuedFanOut": NewQueuedFanOut[int](),
+	}
+
+	for name, comp := range components {
+		var wg sync.WaitGroup
+		errs := make(chan error, 3)
+		for i := 0; i < 3; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				errs <- comp.Stop()
+			}()
+		}
+		wg.Wait()
+		close(errs)
+		for err := range errs {
+			if err != nil {
+				t.Errorf("%s: Stop() returned error: %v", name, err)
+			}
+		}
+		if comp.IsRunning() {
+			t.Errorf("%s: still running after Stop()", name)
+		}
+	}
+}
+
+// TestStopWithTimeoutStuckLoop verifies that StopWithTimeout returns
+// ErrStopTimeout instead of hanging when the worker goroutine is stuck, and
+// that the component still stops once it gets unstuck.
+func TestStopWithTimeoutStuckLoop(t *testing.T) {
+	unblock := make(chan struct{})
+	writing := make(chan struct{})
+	writer := NewWriter(func(val int) error {
+		close(writing)
+		<-unblock // deliberately stuck inside Write
+		return nil
+	})
+	writer.Send(1)
+	<-writing
+
+	start := time.Now()
+	err := writer.StopWithTimeout(50 * time.Millisecond)
+	if !errors.Is(err, ErrStopTimeout) {
+		t.Fatalf("Expected ErrStopTimeout, got: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("StopWithTimeout took too long: %v", elapsed)
+	}
+
+	// Once unstuck, the already-delivered stop signal takes effect.
+	close(unblock)
+	if err := writer.StopWithTimeout(time.Second); err != nil {
+		t.Errorf("Expected clean stop after unblocking, got: %v", err)
+	}
+	if writer.IsRunning() {
+		t.Error("Writer should not be running")
+	}
+}
+
+// TestStopWithTimeoutPrompt verifies StopWithTimeout returns nil for a
+// well-behaved component, and again for redundant calls.
+func TestStopWithTimeoutPrompt(t *testing.T) {
+	fanin := NewFanIn[int]()
+	if err := fanin.StopWithTimeout(time.Second); err != nil {
+		t.Errorf("Expected nil, got: %v", err)
+	}
+	if err := fanin.StopWithTimeout(time.Second); err != nil {
+		t.Errorf("Expected nil on redundant call, got: %v", err)
+	}
+}
+
+// nextState reads the next state transition or fails on timeout.
+func nextState(t *testing.T, ch <-chan RunnerState) RunnerState {
+	t.Helper()
+	select {
+	case s := <-ch:
+		return s
+	case <-time.After(time.Second):
+		t.Fatal("Timeout waiting for state transition")
+		return 0
+	}
+}
+
+// TestRunnerStateTransitions verifies the Running → Stopping → Stopped
+// sequence on StateChan and the State() accessor.
+func TestRunnerStateTransitions(t *testing.T) {
+	writer := NewWriter(func(val int) error { return nil })
+	if s := writer.State(); s != StateRunning {
+		t.Fatalf("Expected Running, got %v", s)
+	}
+
+	states := writer.StateChan()
+	if s := nextState(t, states); s != StateRunning {
+		t.Errorf("StateChan should start with the current state, got %v", s)
+	}
+
+	writer.Stop()
+	if s := nextState(t, states); s != StateStopping {
+		t.Errorf("Expected Stopping, got %v", s)
+	}
+	if s := nextState(t, states); s != StateStopped {
+		t.Errorf("Expected Stopped, got %v", s)
+	}
+	if s := writer.State(); s != StateStopped {
+		t.Errorf("Expected State() Stopped, got %v", s)
+	}
+}
+
+// TestRunnerStateErrored verifies a worker that exits with an error ends in
+// the Errored state.
+func TestRunnerStateErrored(t *testing.T) {
+	writer := NewWriter(func(val int) error { return errors.New("boom") })
+	states := writer.StateChan()
+	nextState(t, states) // Running
+
+	writer.Send(1)
+	if s := nextState(t, states); s != StateErrored {
+		t.Errorf("Expected Errored, got %v", s)
+	}
+	writer.Stop()
+	if s := writer.State(); s != StateErrored {
+		t.Errorf("Expected Errored to stick after Stop, got %v (%s)", s, s)
+	}
+}
+
+// TestReaderStateRunningAfterError verifies that a reader without a
+// WithOnError callback stays Running after a read error, and only ends
+// Errored once it is stopped.
+func TestReaderStateRunningAfterError(t *testing.T) {
+	reader := NewReader(func() (int, error) {
+		return 0, errors.New("boom")
+	})
+	defer reader.Stop()
+	states := reader.StateChan()
+
+	if msg := <-reader.OutputChan(); msg.Error == nil {
+		t.Error("Expected the read error on OutputChan")
+	}
+	if err := <-reader.ClosedChan(); err == nil {
+		t.Error("Expected the read error on ClosedChan")
+	}
+	if s := reader.State(); s != StateRunning {
+		t.Errorf("Expected Running after a read error, got %v", s)
+	}
+	if !reader.IsRunning() {
+		t.Error("Expected the reader to keep running until stopped")
+	}
+	if s := nextState(t, states); s != StateRunning {
+		t.Errorf("Expected StateChan to start with Running, got %v", s)
+	}
+
+	reader.Stop()
+	want := []RunnerState{StateStopping, StateErrored}
+	for _, w := range want {
+		if s := nextState(t, states); s != w {
+			t.Errorf("Expected %v after Stop, got %v", w, s)
+		}
+	}
+}
+
+// TestReaderStateRunningAfterSkippedError verifies that a reader whose
+// WithOnError callback skips a read error keeps reading and stays Running.
+func TestReaderStateRunningAfterSkippedError(t *testing.T) {
+	var n int
+	reader := NewReader(func() (int, error) {
+		n++
+		if n == 1 {
+			return 0, errors.New("transient")
+		}
+		return n, nil
+	}, WithOnError[int](func(error) bool { return true }))
+	defer reader.Stop()
+	states := reader.StateChan()
+
+	if msg := <-reader.OutputChan(); msg.Error != nil || msg.Value != 2 {
+		t.Errorf("Expected the skipped error to be followed by 2, got %v", msg)
+	}
+	<-reader.OutputChan()
+	if s := reader.State(); s != StateRunning {
+		t.Errorf("Expected Running after a skipped error, got %v", s)
+	}
+	if !reader.IsRunning() {
+		t.Error("Expected the reader to keep running")
+	}
+	if s := nextState(t, states); s != StateRunning {
+		t.Errorf("Expected StateChan to start with Running, got %v", s)
+	}
+	select {
+	case s := <-states:
+		t.Errorf("Unexpected transition to %v while still reading", s)
+	default:
+	}
+}
+
+// TestReaderStateErroredOrder verifies that a read error that stops the
+// reader is published as Running → Stopping → Errored, and that Errored is
+// only observed once the reader is no longer running.
+func TestReaderStateErroredOrder(t *testing.T) {
+	fail := make(chan struct{})
+	reader := NewReader(func() (int, error) {
+		<-fail
+		return 0, errors.New("boom")
+	}, WithOnError[int](func(error) bool { return false }))
+	defer reader.Stop()
+	states := reader.StateChan()
+	go func() {
+		<-reader.OutputChan()
+		<-reader.ClosedChan()
+	}()
+	close(fail)
+
+	var got []RunnerState
+	for len(got) == 0 || got[len(got)-1] != StateErrored {
+		s := nextState(t, states)
+		if s == StateErrored && reader.IsRunning() {
+			t.Error("Errored observed while the reader is still running")
+		}
+		got = append(got, s)
+	}
+	want := []RunnerState{StateRunning, StateStopping, StateErrored}
+	if !slices.Equal(got, want) {
+		t.Errorf("Expected states %v, got %v", want, got)
+	}
+	select {
+	case s := <-states:
+		t.Errorf("Unexpected transition to %v after Errored", s)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+// TestWriterOnErrorContinue verifies that a writer keeps writing after an
+// error its OnError callback classifies as "continue", and stops on one
+// classified as "terminate".
+func TestWriterOnErrorContinue(t *testing.T) {
+	transient := errors.New("transient")
+	fatal := errors.New("fatal")
+	written := make(chan int, 10)
+	writer := NewWriter(func(val int) error {
+		switch val {
+		case 2:
+			return transient
+		case 4:
+			return fatal
+		}
+		written <- val
+		return nil
+	}, WithWriterOnError[int](func(err error) bool {
+		return errors.Is(err, transient)
+	}))
+	defer writer.Stop()
+
+	for i := 1; i <= 4; i++ {
+		writer.Send(i)
+	}
+	for _, want := range []int{1, 3} {
+		select {
+		case got := <-written:
+			if got != want {
+				t.Errorf("Expected %d, got %d", want, got)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("Timeout waiting for write after a continued error")
+		}
+	}
+	select {
+	case err := <-writer.ClosedChan():
+		if !errors.Is(err, fatal) {
+			t.Errorf("Expected fatal error, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Writer should stop after a terminate-classified error")
+	}
+}
+
+// TestWriterConcurrency verifies that WithWriterConcurrency runs writes in
+// parallel, writes every value exactly once, and stops all workers.
+func TestWriterConcurrency(t *testing.T) {
+	var mu sync.Mutex
+	active, peak, total := 0, 0, 0
+	writer := NewWriter(func(val int) error {
+		mu.Lock()
+		active++
+		peak = max(peak, active)
+		total += val
+		mu.Unlock()
+		time.Sleep(2 * time.Millisecond)
+		mu.Lock()
+		active--
+		mu.Unlock()
+		return nil
+	}, WithWriterConcurrency[int](4))
+
+	for i := 1; i <= 100; i++ {
+		writer.Send(i)
+	}
+	writer.Stop()
+	select {
+	case <-writer.ClosedChan():
+	case <-time.After(time.Second):
+		t.Fatal("ClosedChan should fire once all workers exit")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if active != 0 {
+		t.Errorf("Expected no writes in progress after Stop, got %d", active)
+	}
+	if peak < 2 || peak > 4 {
+		t.Errorf("Expected between 2 and 4 concurrent writes, got %d", peak)
+	}
+	// Stop may discard values still queued, but with an unbuffered input
+	// every accepted Send was taken by a worker and written.
+	if total != 5050 {
+		t.Errorf("Expected total 5050, got %d", total)
+	}
+}
+
+// TestWriterSendContext verifies that a cancelled context aborts a send to a
+// writer that is stalled in Write, and that sends to a stopped writer fail
+// with ErrStopped.
+func TestWriterSendContext(t *testing.T) {
+	release := make(chan struct{})
+	writer := NewWriter(func(val int) error {
+		<-release
+		return nil
+	})
+	defer writer.Stop()
+	defer close(release)
+
+	// The first value stalls the writer in Write.
+	if err := writer.SendContext(context.Background(), 1); err != nil {
+		t.Fatalf("Expected first send to be accepted, got %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := writer.SendContext(ctx, 2); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	if err := writer.SendContext(ctx, 3); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+
+	stopped := NewWriter(func(int) error { return nil })
+	stopped.Stop()
+	if err := stopped.SendContext(context.Background(), 4); !errors.Is(err, ErrStopped) {
+		t.Errorf("Expected ErrStopped, got %v", err)
+	}
+}
+
+// TestWaitBoundedReader verifies that Wait joins a self-terminating reader
+// from several goroutines and returns its terminal error.
+func TestWaitBoundedReader(t *testing.T) {
+	reader := NewSeqReader(func(yield func(int) bool) {
+		for i := 1; i <= 3 && yield(i); i++ {
+		}
+	}, WithOutputBuffer[int](3))
+	errs := make(chan error, 3)
+	for i := 0; i < 3; i++ {
+		go func() { errs <- reader.Wait() }()
+	}
+	for i := 0; i < 3; i++ {
+		select {
+		case err := <-errs:
+			if err != nil {
+				t.Errorf("Expected nil from Wait, got %v", err)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("Wait did not return after the reader finished")
+		}
+	}
+	if n := len(reader.OutputChan()); n != 3 {
+		t.Errorf("Expected all 3 values read before Wait returned, got %d", n)
+	}
+
+	fatal := errors.New("fatal")
+	failing := NewReader(func() (int, error) { return 0, fatal },
+		WithOnError[int](func(error) bool { return false }), WithOutputBuffer[int](1))
+	if err := failing.Wait(); !errors.Is(err, fatal) {
+		t.Errorf("Expected fatal from Wait, got %v", err)
+	}
+	if err := failing.Wait(); !errors.Is(err, fatal) {
+		t.Errorf("Repeated Wait should return the same error, got %v", err)
+	}
+}
+
+func TestRegisterOnStop(t *testing.T) {
+	fatal := errors.New("fatal")
+	writer := NewWriter(func(int) error { return fatal })
+
+	var mu sync.Mutex
+	var calls []string
+	record := func(name string) func(error) {
+		return func(err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			if !errors.Is(err, fatal) {
+				t.Errorf("Expected hook %s to get the terminal error, got %v", name, err)
+			}
+			calls = append(calls, name)
+		}
+	}
+	writer.RegisterOnStop(record("first"))
+	writer.RegisterOnStop(record("second"))
+
+	writer.Send(1)
+	writer.Wait()
+	mu.Lock()
+	defer mu.Unlock()
+	if len(calls) != 2 || calls[0] != "second" || calls[1] != "first" {
+		t.Errorf("Expected hooks in LIFO order [second first], got %v", calls)
+	}
+
+	var stopErr error = fatal
+	mapper := NewMapper(make(chan int), make(chan int), idMapperFunc[int])
+	mapper.RegisterOnStop(func(err error) { stopErr = err })
+	mapper.Stop()
+	if stopErr != nil {
+		t.Errorf("Expected nil error for a plain Stop, got %v", stopErr)
+	}
+}
+
+func TestWriterStats(t *testing.T) {
+	bad := errors.New("bad value")
+	release := make(chan struct{})
+	writer := NewWriter(func(v int) error {
+		<-release
+		if v < 0 {
+			return bad
+		}
+		return nil
+	}, WithInputBuffer[int](10), WithWriterOnError[int](func(error) bool { return true }))
+	defer writer.Stop()
+
+	if stats := writer.Stats(); !stats.LastWriteAt.IsZero() || stats.Writes != 0 {
+		t.Errorf("Expected empty stats before any write, got %+v", stats)
+	}
+
+	before := time.Now()
+	for _, v := range []int{1, 2, -1, 3, 4} {
+		writer.Send(v)
+	}
+	// The first value is taken by the blocked Write; the rest are queued.
+	deadline := time.Now().Add(time.Second)
+	for writer.Stats().QueueDepth != 4 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if depth := writer.Stats().QueueDepth; depth != 4 {
+		t.Errorf("Expected 4 queued values, got %d", depth)
+	}
+
+	close(release)
+	deadline = time.Now().Add(time.Second)
+	for writer.Stats().Writes+writer.Stats().Errors != 5 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	stats := writer.Stats()
+	if stats.Writes != 4 || stats.Errors != 1 || stats.QueueDepth != 0 {
+		t.Errorf("Expected 4 writes, 1 error and an empty queue, got %+v", stats)
+	}
+	if stats.LastWriteAt.Before(before) {
+		t.Errorf("Expected LastWriteAt after %v, got %v", before, stats.LastWriteAt)
+	}
+	info := writer.DebugInfo().(map[string]any)
+	if info["stats"].(WriterStats).Writes != 4 {
+		t.Errorf("Expected DebugInfo to include stats, got %v", info)
+	}
+}
+
+func TestWriterDeferredStartRejectsSends(t *testing.T) {
+	written := make(chan int, 1)
+	writer := NewWriter(func(v int) error {
+		written <- v
+		return nil
+	}, WithWriterDeferredStart[int]())
+	defer writer.Stop()
+
+	if writer.Send(1) {
+		t.Error("Expected Send before Start to be rejected")
+	}
+	if err := writer.SendContext(context.Background(), 1); !errors.Is(err, ErrStopped) {
+		t.Errorf("Expected ErrStopped from SendContext before Start, got %v", err)
+	}
+
+	if err := writer.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if !writer.Send(2) {
+		t.Fatal("Expected Send after Start to be accepted")
+	}
+	select {
+	case v := <-written:
+		if v != 2 {
+			t.Errorf("Expected only the value sent after Start, got %d", v)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Timeout waiting for write")
+	}
+}
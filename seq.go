@@ -0,0 +1,84 @@
+package gocurrent
+
+import (
+	"context"
+	"errors"
+	"iter"
+)
+
+// errSeqDone is returned by the ReaderFunc of a sequence reader once the
+// sequence is exhausted, making the reader stop cleanly.
+var errSeqDone = errors.New("sequence exhausted")
+
+// NewSeqReader creates a Reader that emits the values of seq as messages.
+// The reader stops by itself, with a nil error on ClosedChan(), once seq is
+// exhausted, or earlier on Stop(). seq is pulled from the reader's goroutine
+// one value at a time, so it only advances as fast as OutputChan() is read.
+//
+// Example:
+//
+//	reader := NewSeqReader(slices.Values(items))
+//	for msg := range reader.OutputChan() { ... }
+func NewSeqReader[R any](seq iter.Seq[R], opts ...ReaderOption[R]) *Reader[R] {
+	next, stop := iter.Pull(seq)
+	read := func() (R, error) {
+		v, ok := next()
+		if !ok {
+			return v, errSeqDone
+		}
+		return v, nil
+	}
+	return NewReader(read, append([]ReaderOption[R]{withRelease[R](stop)}, opts...)...)
+}
+
+// NewSeqReader2 is like NewSeqReader for a sequence of value/error pairs.
+// Each error is delivered as the Message's Error and, as with any Reader,
+// ends reading unless a WithOnError callback skips it.
+func NewSeqReader2[R any](seq iter.Seq2[R, error], opts ...ReaderOption[R]) *Reader[R] {
+	next, stop := iter.Pull2(seq)
+	read := func() (R, error) {
+		v, err, ok := next()
+		if !ok {
+			return v, errSeqDone
+		}
+		return v, err
+	}
+	return NewReader(read, append([]ReaderOption[R]{withRelease[R](stop)}, opts...)...)
+}
+
+// withRelease sets a function run when the reader's reading goroutine exits.
+func withRelease[R any](fn func()) ReaderOption[R] {
+	return func(r *Reader[R]) {
+		r.release = fn
+	}
+}
+
+// Seq returns an iterator over the values received from ch, ending when ch is
+// closed. It lets a component's output be consumed with range-over-func:
+//
+//	for v := range Seq(mapper.OutputChan()) { ... }
+func Seq[T any](ch <-chan T) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for v := range ch {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// SeqCtx is like Seq but also ends when ctx is done.
+func SeqCtx[T any](ctx context.Context, ch <-chan T) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case v, ok := <-ch:
+				if !ok || !yield(v) {
+					return
+				}
+			}
+		}
+	}
+}
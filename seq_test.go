@@ -0,0 +1,101 @@
+package gocurrent
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSeqReaderDrains(t *testing.T) {
+	reader := NewSeqReader(slices.Values([]int{1, 2, 3}))
+	var got []int
+	for i := 0; i < 3; i++ {
+		msg := withTimeout(t, reader.OutputChan())
+		assert.NoError(t, msg.Error)
+		got = append(got, msg.Value)
+	}
+	assert.Equal(t, []int{1, 2, 3}, got)
+	assert.NoError(t, withTimeout(t, reader.ClosedChan()))
+	withTimeout(t, reader.Done())
+	assert.False(t, reader.IsRunning())
+}
+
+func TestSeqReaderStopReleasesSeq(t *testing.T) {
+	released := make(chan struct{})
+	seq := func(yield func(int) bool) {
+		defer close(released)
+		for i := 0; ; i++ {
+			if !yield(i) {
+				return
+			}
+		}
+	}
+	reader := NewSeqReader(seq)
+	assert.Equal(t, 0, withTimeout(t, reader.OutputChan()).Value)
+	reader.Stop()
+	// The pending send is abandoned and the sequence's defers run.
+	withTimeout(t, released)
+}
+
+func TestSeqReader2Errors(t *testing.T) {
+	boom := errors.New("boom")
+	seq := func(yield func(string, error) bool) {
+		_ = yield("a", nil) && yield("", boom) && yield("c", nil)
+	}
+	reader := NewSeqReader2(seq)
+	msg := withTimeout(t, reader.OutputChan())
+	assert.Equal(t, "a", msg.Value)
+	assert.ErrorIs(t, withTimeout(t, reader.OutputChan()).Error, boom)
+	// As with any Reader, a read error is also reported on ClosedChan and
+	// ends reading.
+	assert.ErrorIs(t, withTimeout(t, reader.ClosedChan()), boom)
+	select {
+	case msg := <-reader.OutputChan():
+		t.Fatalf("Unexpected message after the error: %v", msg)
+	case <-time.After(20 * time.Millisecond):
+	}
+	reader.Stop()
+
+	// A WithOnError callback can skip errors to read on past them.
+	reader = NewSeqReader2(seq, WithOnError[string](func(error) bool { return true }))
+	assert.Equal(t, "a", withTimeout(t, reader.OutputChan()).Value)
+	assert.Equal(t, "c", withTimeout(t, reader.OutputChan()).Value)
+	withTimeout(t, reader.Done())
+}
+
+func TestSeqDrains(t *testing.T) {
+	ch := make(chan int, 3)
+	ch <- 1
+	ch <- 2
+	ch <- 3
+	close(ch)
+	assert.Equal(t, []int{1, 2, 3}, slices.Collect(Seq(ch)))
+
+	// Breaking out early leaves the rest of the channel unread.
+	ch = make(chan int, 3)
+	ch <- 1
+	ch <- 2
+	for v := range Seq(ch) {
+		assert.Equal(t, 1, v)
+		break
+	}
+	assert.Equal(t, 2, <-ch)
+}
+
+func TestSeqCtxCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := make(chan int)
+	done := make(chan []int)
+	go func() {
+		done <- slices.Collect(SeqCtx(ctx, ch))
+	}()
+	ch <- 1
+	ch <- 2
+	cancel()
+	// The channel is never closed; the range ends on cancellation.
+	assert.Equal(t, []int{1, 2}, withTimeout(t, done))
+}
@@ -0,0 +1,114 @@
+package gocurrent
+
+import "hash/maphash"
+
+// ShardedMap is a thread-safe map that spreads keys across several
+// independently locked [Map] shards, so writers of different keys rarely
+// contend on the same lock. It offers the same API as Map.
+//
+// Per-key operations lock a single shard. Len, Range and ToMap lock every
+// shard (for reading, in a fixed order) for the duration of the count or
+// copy, so they see a consistent view across shards.
+type ShardedMap[K comparable, V any] struct {
+	seed   maphash.Seed
+	shards []Map[K, V]
+}
+
+// NewShardedMap creates a map with the given number of shards. A shard count
+// below 1 is treated as 1. A few times GOMAXPROCS is a reasonable choice for
+// write-heavy workloads.
+func NewShardedMap[K comparable, V any](shards int) *ShardedMap[K, V] {
+	if shards < 1 {
+		shards = 1
+	}
+	return &ShardedMap[K, V]{
+		seed:   maphash.MakeSeed(),
+		shards: make([]Map[K, V], shards),
+	}
+}
+
+func (m *ShardedMap[K, V]) shard(key K) *Map[K, V] {
+	h := maphash.Comparable(m.seed, key)
+	return &m.shards[h%uint64(len(m.shards))]
+}
+
+// Get returns the value stored for key. The ok result reports whether the
+// key was present.
+func (m *ShardedMap[K, V]) Get(key K) (value V, ok bool) {
+	return m.shard(key).Get(key)
+}
+
+// Set stores value for key.
+func (m *ShardedMap[K, V]) Set(key K, value V) {
+	m.shard(key).Set(key, value)
+}
+
+// Delete removes key from the map.
+func (m *ShardedMap[K, V]) Delete(key K) {
+	m.shard(key).Delete(key)
+}
+
+// LoadOrStore behaves like [Map.LoadOrStore].
+func (m *ShardedMap[K, V]) LoadOrStore(key K, value V) (actual V, loaded bool) {
+	return m.shard(key).LoadOrStore(key, value)
+}
+
+// GetOrCompute behaves like [Map.GetOrCompute]; fn blocks only the key's
+// shard rather than the whole map.
+func (m *ShardedMap[K, V]) GetOrCompute(key K, fn func() V) V {
+	return m.shard(key).GetOrCompute(key, fn)
+}
+
+// Update behaves like [Map.Update]; fn blocks only the key's shard.
+func (m *ShardedMap[K, V]) Update(key K, fn func(old V, ok bool) V) V {
+	return m.shard(key).Update(key, fn)
+}
+
+// rlockAll read-locks every shard in index order and returns the matching
+// unlock function.
+func (m *ShardedMap[K, V]) rlockAll() (unlock func()) {
+	for i := range m.shards {
+		m.shards[i].mu.RLock()
+	}
+	return func() {
+		for i := range m.shards {
+			m.shards[i].mu.RUnlock()
+		}
+	}
+}
+
+// Len returns the number of entries across all shards.
+func (m *ShardedMap[K, V]) Len() int {
+	defer m.rlockAll()()
+	n := 0
+	for i := range m.shards {
+		n += len(m.shards[i].items)
+	}
+	return n
+}
+
+// ToMap returns a consistent point-in-time copy of all shards as a plain map.
+func (m *ShardedMap[K, V]) ToMap() map[K]V {
+	defer m.rlockAll()()
+	n := 0
+	for i := range m.shards {
+		n += len(m.shards[i].items)
+	}
+	out := make(map[K]V, n)
+	for i := range m.shards {
+		for k, v := range m.shards[i].items {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+// Range calls fn for each entry until fn returns false, iterating over a
+// consistent snapshot as [Map.Range] does. fn may modify the map.
+func (m *ShardedMap[K, V]) Range(fn func(key K, value V) bool) {
+	for k, v := range m.ToMap() {
+		if !fn(k, v) {
+			return
+		}
+	}
+}
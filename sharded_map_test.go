@@ -0,0 +1,89 @@
+package gocurrent
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShardedMap_CoreAPI(t *testing.T) {
+	m := NewShardedMap[string, int](8)
+	for i := 0; i < 100; i++ {
+		m.Set(fmt.Sprint(i), i)
+	}
+	assert.Equal(t, 100, m.Len())
+
+	v, ok := m.Get("42")
+	assert.True(t, ok)
+	assert.Equal(t, 42, v)
+
+	m.Delete("42")
+	_, ok = m.Get("42")
+	assert.False(t, ok)
+	assert.Equal(t, 99, m.Len())
+
+	sum := 0
+	m.Range(func(k string, v int) bool {
+		sum += v
+		return true
+	})
+	assert.Equal(t, 99*100/2-42, sum)
+	assert.Len(t, m.ToMap(), 99)
+
+	actual, loaded := m.LoadOrStore("1", 100)
+	assert.True(t, loaded)
+	assert.Equal(t, 1, actual)
+	assert.Equal(t, 7, m.GetOrCompute("new", func() int { return 7 }))
+}
+
+func TestShardedMap_ConcurrentUpdates(t *testing.T) {
+	m := NewShardedMap[int, int](4)
+	var wg sync.WaitGroup
+	for g := 0; g < 16; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				m.Update(i%10, func(old int, _ bool) int { return old + 1 })
+				m.Len()
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 10, m.Len())
+	m.Range(func(k, v int) bool {
+		assert.Equal(t, 1600, v)
+		return true
+	})
+}
+
+// benchmarkConcurrentSets runs b.N Sets spread across 16 goroutines.
+func benchmarkConcurrentSets(b *testing.B, set func(key, value int)) {
+	const goroutines = 16
+	var wg sync.WaitGroup
+	per := b.N/goroutines + 1
+	b.ResetTimer()
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < per; i++ {
+				set(g*per+i, i)
+			}
+		}(g)
+	}
+	wg.Wait()
+}
+
+func BenchmarkMap_Set16(b *testing.B) {
+	var m Map[int, int]
+	benchmarkConcurrentSets(b, m.Set)
+}
+
+func BenchmarkShardedMap_Set16(b *testing.B) {
+	m := NewShardedMap[int, int](64)
+	benchmarkConcurrentSets(b, m.Set)
+}
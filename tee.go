@@ -0,0 +1,164 @@
+package gocurrent
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// Tee duplicates one input channel onto two output channels. It is a
+// two-output broadcast with a fixed shape, so callers get both outputs
+// directly instead of registering them as with the FanOut types.
+//
+// By default every value is delivered to both outputs, in whichever order
+// they become ready, before the next value is read. A slow consumer on
+// either side therefore slows both. With WithTeeDropLagging(true) the tee
+// waits only for the faster side; the other side receives the value only if
+// it can accept it without blocking (typically because its buffer, see
+// WithTeeBuffer, has room) and otherwise the value is dropped for that side.
+//
+// The Tee owns its outputs and closes them when the input is closed or the
+// tee is stopped.
+type Tee[T any] struct {
+	RunnerBase[string]
+	input       <-chan T
+	out1        chan T
+	out2        chan T
+	dropLagging bool
+	dropped     [2]atomic.Int64
+	closedChan  chan error
+}
+
+// TeeOption is a functional option for configuring a Tee.
+type TeeOption[T any] func(*Tee[T])
+
+// WithTeeBuffer sets the buffer size of both output channels.
+func WithTeeBuffer[T any](size int) TeeOption[T] {
+	return func(t *Tee[T]) {
+		t.out1 = make(chan T, size)
+		t.out2 = make(chan T, size)
+	}
+}
+
+// WithTeeDropLagging drops values for an output that cannot keep up instead
+// of blocking the other output.
+func WithTeeDropLagging[T any](drop bool) TeeOption[T] {
+	return func(t *Tee[T]) {
+		t.dropLagging = drop
+	}
+}
+
+// WithTeeContext ties the tee's lifetime to ctx. When ctx is done the tee
+// stops itself and ClosedChan() receives ctx.Err().
+func WithTeeContext[T any](ctx context.Context) TeeOption[T] {
+	return func(t *Tee[T]) {
+		t.ctx = ctx
+	}
+}
+
+// NewTee creates a tee reading from input.
+//
+// Example:
+//
+//	tee := NewTee(events)
+//	metricsIn, mainIn := tee.Outputs()
+//	go recordMetrics(metricsIn)
+//	process(mainIn)
+func NewTee[T any](input <-chan T, opts ...TeeOption[T]) *Tee[T] {
+	out := &Tee[T]{
+		RunnerBase: NewRunnerBase("stop"),
+		input:      input,
+		out1:       make(chan T),
+		out2:       make(chan T),
+		closedChan: make(chan error, 1),
+	}
+	out.component = "Tee"
+	for _, opt := range opts {
+		opt(out)
+	}
+	out.start()
+	return out
+}
+
+// Outputs returns the two output channels.
+func (t *Tee[T]) Outputs() (out1, out2 <-chan T) {
+	return t.out1, t.out2
+}
+
+// Dropped returns the number of values dropped for each output. Always zero
+// unless WithTeeDropLagging is enabled.
+func (t *Tee[T]) Dropped() (out1, out2 int64) {
+	return t.dropped[0].Load(), t.dropped[1].Load()
+}
+
+// ClosedChan returns the channel used to signal when the tee is done.
+func (t *Tee[T]) ClosedChan() <-chan error {
+	return t.closedChan
+}
+
+func (t *Tee[T]) cleanup() {
+	close(t.out1)
+	close(t.out2)
+	close(t.closedChan)
+	t.RunnerBase.cleanup()
+}
+
+func (t *Tee[T]) start() {
+	t.RunnerBase.start()
+	go func() {
+		defer t.cleanup()
+		for {
+			select {
+			case <-t.controlChan:
+				return
+			case <-t.ctxDone():
+				t.stopForContext()
+				return
+			case value, ok := <-t.input:
+				if !ok {
+					return
+				}
+				if !t.send(value) {
+					return
+				}
+			}
+		}
+	}()
+}
+
+// send delivers value according to the lag policy. It returns false if the
+// tee was stopped while sending.
+func (t *Tee[T]) send(value T) bool {
+	outs := [2]chan T{t.out1, t.out2}
+	for outs[0] != nil || outs[1] != nil {
+		select {
+		case outs[0] <- value:
+			outs[0] = nil
+		case outs[1] <- value:
+			outs[1] = nil
+		case <-t.controlChan:
+			return false
+		case <-t.ctxDone():
+			t.stopForContext()
+			return false
+		}
+		if t.dropLagging {
+			for i, out := range outs {
+				if out == nil {
+					continue
+				}
+				select {
+				case out <- value:
+				default:
+					t.dropped[i].Add(1)
+				}
+			}
+			return true
+		}
+	}
+	return true
+}
+
+func (t *Tee[T]) stopForContext() {
+	t.setErr(t.contextErr())
+	t.closedChan <- t.contextErr()
+}
@@ -0,0 +1,83 @@
+package gocurrent
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTeeBothOutputsSeeEveryValue(t *testing.T) {
+	input := make(chan int)
+	tee := NewTee(input)
+	out1, out2 := tee.Outputs()
+
+	var got1, got2 []int
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for v := range out1 {
+			got1 = append(got1, v)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for v := range out2 {
+			got2 = append(got2, v)
+		}
+	}()
+
+	var expected []int
+	for i := 0; i < 100; i++ {
+		input <- i
+		expected = append(expected, i)
+	}
+	close(input)
+	wg.Wait()
+
+	assert.Equal(t, expected, got1)
+	assert.Equal(t, expected, got2)
+	d1, d2 := tee.Dropped()
+	assert.Zero(t, d1)
+	assert.Zero(t, d2)
+}
+
+func TestTeeDropLagging(t *testing.T) {
+	input := make(chan int)
+	tee := NewTee(input, WithTeeBuffer[int](2), WithTeeDropLagging[int](true))
+	out1, out2 := tee.Outputs()
+
+	// Nobody reads out2, so once its buffer is full it only loses values
+	var got1 []int
+	for i := 0; i < 10; i++ {
+		input <- i
+		got1 = append(got1, withTimeout(t, out1))
+	}
+	close(input)
+	<-tee.ClosedChan()
+
+	assert.Equal(t, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, got1)
+	var got2 []int
+	for v := range out2 {
+		got2 = append(got2, v)
+	}
+	assert.Equal(t, []int{0, 1}, got2, "Lagging output keeps only what fit in its buffer")
+	d1, d2 := tee.Dropped()
+	assert.Zero(t, d1)
+	assert.Equal(t, int64(8), d2)
+}
+
+func TestTeeStopWhileBlocked(t *testing.T) {
+	input := make(chan int, 1)
+	tee := NewTee(input)
+	input <- 1
+
+	// Neither output is read; Stop must still return and close the outputs
+	stopped := make(chan error, 1)
+	go func() { stopped <- tee.Stop() }()
+	withTimeout(t, stopped)
+	out1, _ := tee.Outputs()
+	_, ok := <-out1
+	assert.False(t, ok)
+}
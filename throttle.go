@@ -0,0 +1,147 @@
+package gocurrent
+
+import (
+	"context"
+	"time"
+)
+
+// Throttler forwards at most one value per interval from input to output.
+// The first value after an idle period is forwarded immediately (leading
+// edge) and opens an interval during which further values are dropped.
+//
+// With WithThrottleTrailing(true), the last value dropped during an interval
+// is forwarded when the interval ends, and that emission opens a new
+// interval. This guarantees the most recent value is eventually delivered.
+// As with Mapper, the channels are owned by the caller.
+type Throttler[T any] struct {
+	RunnerBase[string]
+	input      <-chan T
+	output     chan<- T
+	interval   time.Duration
+	trailing   bool
+	closedChan chan error
+	clock      Clock
+
+	deadlockTimeout time.Duration // see WithThrottlerDeadlockDetection
+}
+
+// ThrottlerOption is a functional option for configuring a Throttler.
+type ThrottlerOption[T any] func(*Throttler[T])
+
+// WithThrottleTrailing also emits the last value dropped in each interval
+// when that interval ends.
+func WithThrottleTrailing[T any](trailing bool) ThrottlerOption[T] {
+	return func(t *Throttler[T]) {
+		t.trailing = trailing
+	}
+}
+
+// WithThrottlerContext ties the throttler's lifetime to ctx. When ctx is done
+// the throttler stops itself and ClosedChan() receives ctx.Err().
+func WithThrottlerContext[T any](ctx context.Context) ThrottlerOption[T] {
+	return func(t *Throttler[T]) {
+		t.ctx = ctx
+	}
+}
+
+// WithThrottlerClock sets the clock timing the intervals, e.g. a FakeClock
+// in tests. The default is RealClock.
+func WithThrottlerClock[T any](clock Clock) ThrottlerOption[T] {
+	return func(t *Throttler[T]) {
+		t.clock = clock
+	}
+}
+
+// WithThrottlerDeadlockDetection warns when an output send stays blocked for
+// longer than timeout. See "Deadlock detection" in the README.
+func WithThrottlerDeadlockDetection[T any](timeout time.Duration) ThrottlerOption[T] {
+	return func(t *Throttler[T]) {
+		t.deadlockTimeout = timeout
+	}
+}
+
+// NewThrottler creates a throttler between input and output.
+//
+// Example:
+//
+//	// Redraw progress at most 10 times a second, always showing the latest
+//	th := NewThrottler(progress, redraw, 100*time.Millisecond,
+//	    WithThrottleTrailing[int](true))
+//	defer th.Stop()
+func NewThrottler[T any](input <-chan T, output chan<- T, interval time.Duration, opts ...ThrottlerOption[T]) *Throttler[T] {
+	out := &Throttler[T]{
+		RunnerBase: NewRunnerBase("stop"),
+		input:      input,
+		output:     output,
+		interval:   interval,
+		closedChan: make(chan error, 1),
+	}
+	out.component = "Throttler"
+	for _, opt := range opts {
+		opt(out)
+	}
+	out.start()
+	return out
+}
+
+// ClosedChan returns the channel used to signal when the throttler is done.
+func (t *Throttler[T]) ClosedChan() <-chan error {
+	return t.closedChan
+}
+
+func (t *Throttler[T]) cleanup() {
+	close(t.closedChan)
+	t.RunnerBase.cleanup()
+}
+
+func (t *Throttler[T]) start() {
+	t.RunnerBase.start()
+	go func() {
+		defer t.cleanup()
+		var trailing T
+		hasTrailing := false
+		timer := clockOr(t.clock).NewTimer(t.interval)
+		timer.Stop()
+		defer timer.Stop()
+		// intervalEnd is non-nil while an interval is open
+		var intervalEnd <-chan time.Time
+		for {
+			select {
+			case <-t.controlChan:
+				return
+			case <-t.ctxDone():
+				t.setErr(t.contextErr())
+				t.closedChan <- t.contextErr()
+				return
+			case value, ok := <-t.input:
+				if !ok {
+					return
+				}
+				if intervalEnd == nil {
+					t.send(value)
+					timer.Reset(t.interval)
+					intervalEnd = timer.C()
+				} else if t.trailing {
+					trailing, hasTrailing = value, true
+				}
+			case <-intervalEnd:
+				intervalEnd = nil
+				if hasTrailing {
+					t.send(trailing)
+					var zero T
+					trailing, hasTrailing = zero, false
+					timer.Reset(t.interval)
+					intervalEnd = timer.C()
+				}
+			}
+		}
+	}()
+}
+
+// send delivers a value to the output channel, watched for deadlocks if
+// enabled.
+func (t *Throttler[T]) send(value T) {
+	done := watchBlocking(defaultLogger(), t.deadlockTimeout, "Throttler", "send to output")
+	t.output <- value
+	done()
+}
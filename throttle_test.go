@@ -0,0 +1,63 @@
+package gocurrent
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// drainFor collects everything received on ch during d.
+func drainFor[T any](ch <-chan T, d time.Duration) (out []T) {
+	deadline := time.After(d)
+	for {
+		select {
+		case v := <-ch:
+			out = append(out, v)
+		case <-deadline:
+			return
+		}
+	}
+}
+
+func TestThrottlerLeadingOnly(t *testing.T) {
+	input := make(chan int)
+	output := make(chan int, 10)
+	th := NewThrottler(input, output, 100*time.Millisecond)
+	defer th.Stop()
+
+	for i := 1; i <= 5; i++ {
+		input <- i
+	}
+	assert.Equal(t, []int{1}, drainFor(output, 200*time.Millisecond),
+		"Only the first value of the interval should pass")
+
+	// After the interval the next value passes straight away
+	input <- 6
+	assert.Equal(t, 6, withTimeout(t, output))
+}
+
+func TestThrottlerLeadingAndTrailing(t *testing.T) {
+	input := make(chan int)
+	output := make(chan int, 10)
+	th := NewThrottler(input, output, 100*time.Millisecond, WithThrottleTrailing[int](true))
+	defer th.Stop()
+
+	for i := 1; i <= 5; i++ {
+		input <- i
+	}
+	assert.Equal(t, 1, withTimeout(t, output))
+	start := time.Now()
+	assert.Equal(t, 5, withTimeout(t, output), "Last dropped value should trail the interval")
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+
+	// The trailing emission opened a new interval, so this value is held
+	input <- 6
+	select {
+	case v := <-output:
+		t.Fatalf("Value %d passed inside the trailing interval", v)
+	case <-time.After(30 * time.Millisecond):
+	}
+	assert.Equal(t, 6, withTimeout(t, output))
+	assert.Empty(t, drainFor(output, 200*time.Millisecond))
+}
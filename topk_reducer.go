@@ -0,0 +1,133 @@
+package gocurrent
+
+import (
+	"container/heap"
+	"sort"
+)
+
+// topKEntry is an item held by a TopKHeap together with its score and
+// arrival sequence (used for tie-breaking).
+type topKEntry[T any] struct {
+	item  T
+	score float64
+	seq   uint64
+}
+
+// topKEntries is the min-heap behind a TopKHeap. The root is the lowest
+// ranked entry.
+type topKEntries[T any] []topKEntry[T]
+
+func (h topKEntries[T]) Len() int { return len(h) }
+
+func (h topKEntries[T]) Less(i, j int) bool {
+	if h[i].score != h[j].score {
+		return h[i].score < h[j].score
+	}
+	return h[i].seq > h[j].seq
+}
+
+func (h topKEntries[T]) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *topKEntries[T]) Push(x any) { *h = append(*h, x.(topKEntry[T])) }
+
+func (h *topKEntries[T]) Pop() any {
+	old := *h
+	last := len(old) - 1
+	e := old[last]
+	*h = old[:last]
+	return e
+}
+
+// TopKHeap is the collection type used by [NewTopKReducer]. It is a bounded
+// min-heap holding at most K items, so memory is O(K) regardless of how many
+// items arrive in a window. The lowest ranked item sits at the root and is
+// evicted when a better item arrives. Only the reducer adds items; callers
+// can read it with Len and Sorted.
+//
+// Ranking: a higher score ranks higher. Among equal scores the item that
+// arrived first ranks higher, so a later item with the same score as the
+// current K-th item does not displace it.
+type TopKHeap[T any] struct {
+	k       int
+	score   func(T) float64
+	entries topKEntries[T]
+	nextSeq uint64
+}
+
+func newTopKHeap[T any](k int, score func(T) float64) *TopKHeap[T] {
+	return &TopKHeap[T]{k: k, score: score, entries: make(topKEntries[T], 0, k)}
+}
+
+// Len returns the number of items held, at most K.
+func (h *TopKHeap[T]) Len() int { return len(h.entries) }
+
+// offer considers item for inclusion in the top K.
+func (h *TopKHeap[T]) offer(item T) {
+	e := topKEntry[T]{item: item, score: h.score(item), seq: h.nextSeq}
+	h.nextSeq++
+	if len(h.entries) < h.k {
+		heap.Push(&h.entries, e)
+		return
+	}
+	// Strictly better than the current minimum, or it would lose the tie.
+	if e.score > h.entries[0].score {
+		h.entries[0] = e
+		heap.Fix(&h.entries, 0)
+	}
+}
+
+// Sorted returns the held items from highest to lowest rank.
+func (h *TopKHeap[T]) Sorted() []T {
+	entries := make([]topKEntry[T], len(h.entries))
+	copy(entries, h.entries)
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].score != entries[j].score {
+			return entries[i].score > entries[j].score
+		}
+		return entries[i].seq < entries[j].seq
+	})
+	out := make([]T, len(entries))
+	for i, e := range entries {
+		out[i] = e.item
+	}
+	return out
+}
+
+// TopKReducerOption is a functional option for configuring a top-K reducer.
+type TopKReducerOption[T any] = ReducerOption[T, *TopKHeap[T], []T]
+
+// NewTopKReducer creates a reducer that tracks the k highest scoring items
+// seen in each window and emits them (highest first) on every flush. Each
+// flush starts a fresh window: items from previous windows are not carried
+// over. See [TopKHeap] for the ranking and tie-breaking rules.
+//
+// Panics if k <= 0.
+//
+// Example:
+//
+//	topk := NewTopKReducer(3, func(p Player) float64 { return p.Score },
+//	    WithFlushPeriod[Player, *TopKHeap[Player], []Player](time.Minute))
+//	defer topk.Stop()
+//	leaders := <-topk.OutputChan()
+func NewTopKReducer[T any](k int, score func(T) float64, opts ...TopKReducerOption[T]) *Reducer[T, *TopKHeap[T], []T] {
+	if k <= 0 {
+		panic("NewTopKReducer requires k > 0")
+	}
+	collectOpt := WithCollectFunc[T, *TopKHeap[T], []T](func(h *TopKHeap[T], inputs ...T) (*TopKHeap[T], bool) {
+		if h == nil {
+			h = newTopKHeap(k, score)
+		}
+		for _, input := range inputs {
+			h.offer(input)
+		}
+		return h, false
+	})
+	reduceOpt := WithReduceFunc[T, *TopKHeap[T], []T](func(h *TopKHeap[T]) []T {
+		if h == nil {
+			return nil
+		}
+		return h.Sorted()
+	})
+	allOpts := append([]TopKReducerOption[T]{collectOpt, reduceOpt}, opts...)
+	return NewReducer(allOpts...)
+}
@@ -0,0 +1,65 @@
+package gocurrent
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type scored struct {
+	name  string
+	score float64
+}
+
+func scoreOf(s scored) float64 { return s.score }
+
+func TestTopKReducerAcrossWindows(t *testing.T) {
+	reducer := NewTopKReducer(3, scoreOf,
+		WithFlushPeriod[scored, *TopKHeap[scored], []scored](10*time.Second))
+	defer reducer.Stop()
+
+	for _, s := range []scored{{"a", 5}, {"b", 1}, {"c", 9}, {"d", 3}, {"e", 7}, {"f", 2}} {
+		reducer.Send(s)
+	}
+	go reducer.Flush()
+	top := withTimeout(t, reducer.OutputChan())
+	assert.Equal(t, []scored{{"c", 9}, {"e", 7}, {"a", 5}}, top)
+
+	// The next window starts empty; earlier items do not carry over.
+	reducer.Send(scored{"g", 4})
+	reducer.Send(scored{"h", 6})
+	go reducer.Flush()
+	top = withTimeout(t, reducer.OutputChan())
+	assert.Equal(t, []scored{{"h", 6}, {"g", 4}}, top)
+
+	// An empty window emits an empty result.
+	go reducer.Flush()
+	assert.Empty(t, withTimeout(t, reducer.OutputChan()))
+}
+
+func TestTopKReducerTieBreaking(t *testing.T) {
+	reducer := NewTopKReducer(2, scoreOf,
+		WithFlushPeriod[scored, *TopKHeap[scored], []scored](10*time.Second))
+	defer reducer.Stop()
+
+	// Equal scores: earlier arrivals win and later ones do not displace them.
+	for _, s := range []scored{{"a", 1}, {"b", 1}, {"c", 1}, {"d", 0}} {
+		reducer.Send(s)
+	}
+	go reducer.Flush()
+	assert.Equal(t, []scored{{"a", 1}, {"b", 1}}, withTimeout(t, reducer.OutputChan()))
+}
+
+func TestTopKReducerBoundedMemory(t *testing.T) {
+	h := newTopKHeap(3, func(i int) float64 { return float64(i) })
+	for i := range 10000 {
+		h.offer(i)
+	}
+	assert.Equal(t, 3, h.Len(), "Heap should never hold more than K items")
+	assert.Equal(t, []int{9999, 9998, 9997}, h.Sorted())
+}
+
+func TestTopKReducerInvalidK(t *testing.T) {
+	assert.Panics(t, func() { NewTopKReducer(0, scoreOf) })
+}
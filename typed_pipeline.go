@@ -0,0 +1,140 @@
+package gocurrent
+
+import "sync"
+
+// pipelineStage is a running stage of a TypedPipeline.
+type pipelineStage interface {
+	Component
+	Done() <-chan struct{}
+}
+
+// pipelineInput is the input channel shared by every TypedPipeline derived
+// from the same NewTypedPipeline call.
+type pipelineInput[I any] struct {
+	ch     chan I
+	mu     sync.RWMutex
+	closed bool
+}
+
+// TypedPipeline is a linear chain of mapping stages whose element type may
+// change from stage to stage. I is the type accepted by the first stage and
+// O the type produced by the last.
+//
+// Build one with NewTypedPipeline and AddStage. Each AddStage call consumes
+// the pipeline it is given and returns the extended pipeline; keep using the
+// returned value only. The pipeline owns every channel it creates,
+// including its input and output.
+//
+// Stop shuts the pipeline down gracefully: the input is closed, each stage
+// drains what it has received and exits, and that closes the next stage's
+// input, so stages stop in order and OutputChan() is closed last. The
+// output must be read until it is closed for Stop to complete.
+type TypedPipeline[I any, O any] struct {
+	in     *pipelineInput[I]
+	output chan O
+	stages []pipelineStage
+}
+
+// NewTypedPipeline creates an empty pipeline whose input and output are the
+// same channel of T. Add stages with AddStage.
+//
+// Example:
+//
+//	p1 := NewTypedPipeline[int]()
+//	p2 := AddStage(p1, func(i int) (string, bool, bool) { return strconv.Itoa(i), false, false })
+//	p3 := AddStage(p2, func(s string) (int, bool, bool) { return len(s), false, false })
+//	p3.Send(12345)
+//	fmt.Println(<-p3.OutputChan()) // 5
+func NewTypedPipeline[T any]() *TypedPipeline[T, T] {
+	ch := make(chan T)
+	return &TypedPipeline[T, T]{
+		in:     &pipelineInput[T]{ch: ch},
+		output: ch,
+	}
+}
+
+// AddStage appends a stage applying fn to every value leaving p and returns
+// the extended pipeline. fn has the same (output, skip, stop) contract as a
+// Mapper's MapFunc. Methods cannot introduce type parameters, hence a free
+// function.
+//
+// A stage that finishes early, e.g. because fn asks to stop, stops the
+// stages before it and discards whatever they still send, so Send and Stop
+// never block on it.
+func AddStage[I, M, O any](p *TypedPipeline[I, M], fn func(M) (O, bool, bool)) *TypedPipeline[I, O] {
+	in := p.output
+	var prev pipelineStage
+	if len(p.stages) > 0 {
+		prev = p.stages[len(p.stages)-1]
+	}
+	out := make(chan O)
+	stage := NewMapper(in, out, fn, WithMapperOnDone(func(*Mapper[M, O]) {
+		close(out)
+		// The previous stage (or Send) may be blocked sending to in; keep
+		// draining until it has exited and closed in.
+		go func() {
+			for range in {
+			}
+		}()
+		if prev != nil {
+			go prev.Stop()
+		}
+	}))
+	stages := make([]pipelineStage, len(p.stages), len(p.stages)+1)
+	copy(stages, p.stages)
+	return &TypedPipeline[I, O]{
+		in:     p.in,
+		output: out,
+		stages: append(stages, stage),
+	}
+}
+
+// InputChan returns the pipeline's input channel. Prefer Send, which is
+// safe to call after Stop.
+func (p *TypedPipeline[I, O]) InputChan() chan<- I {
+	return p.in.ch
+}
+
+// OutputChan returns the output channel of the last stage.
+func (p *TypedPipeline[I, O]) OutputChan() <-chan O {
+	return p.output
+}
+
+// Send sends a value into the first stage. Values sent after Stop are
+// dropped.
+func (p *TypedPipeline[I, O]) Send(value I) {
+	p.in.mu.RLock()
+	defer p.in.mu.RUnlock()
+	if !p.in.closed {
+		p.in.ch <- value
+	}
+}
+
+// Len returns the number of stages.
+func (p *TypedPipeline[I, O]) Len() int {
+	return len(p.stages)
+}
+
+// IsRunning returns true while any stage is running.
+func (p *TypedPipeline[I, O]) IsRunning() bool {
+	for _, s := range p.stages {
+		if s.IsRunning() {
+			return true
+		}
+	}
+	return false
+}
+
+// Stop closes the input and waits for the stages to finish, first to last.
+func (p *TypedPipeline[I, O]) Stop() error {
+	p.in.mu.Lock()
+	if !p.in.closed {
+		p.in.closed = true
+		close(p.in.ch)
+	}
+	p.in.mu.Unlock()
+	for _, s := range p.stages {
+		<-s.Done()
+	}
+	return nil
+}
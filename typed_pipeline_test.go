@@ -0,0 +1,91 @@
+package gocurrent
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTypedPipelineThreeStages(t *testing.T) {
+	p1 := NewTypedPipeline[int]()
+	p2 := AddStage(p1, func(i int) (int, bool, bool) { return i * 10, false, false })
+	p3 := AddStage(p2, func(i int) (string, bool, bool) { return strconv.Itoa(i), false, false })
+	p4 := AddStage(p3, func(s string) (int, bool, bool) { return len(s), false, false })
+	assert.Equal(t, 3, p4.Len())
+
+	go func() {
+		for _, v := range []int{1, 22, 333, 4444} {
+			p4.Send(v)
+		}
+		p4.Stop()
+	}()
+
+	var got []int
+	for n := range p4.OutputChan() {
+		got = append(got, n)
+	}
+	assert.Equal(t, []int{2, 3, 4, 5}, got)
+	assert.False(t, p4.IsRunning())
+
+	// Sends after Stop are dropped rather than panicking
+	p4.Send(1)
+}
+
+func TestTypedPipelineSkip(t *testing.T) {
+	p := AddStage(NewTypedPipeline[int](), func(i int) (int, bool, bool) {
+		return i, i%2 == 1, false
+	})
+	var _ InputComponent[int] = p
+	var _ OutputComponent[int] = p
+
+	go func() {
+		for i := 0; i < 6; i++ {
+			p.Send(i)
+		}
+		p.Stop()
+	}()
+	var got []int
+	for v := range p.OutputChan() {
+		got = append(got, v)
+	}
+	assert.Equal(t, []int{0, 2, 4}, got)
+}
+
+func TestTypedPipelineNoStages(t *testing.T) {
+	p := NewTypedPipeline[string]()
+	go p.Send("hello")
+	assert.Equal(t, "hello", withTimeout(t, p.OutputChan()))
+	p.Stop()
+	_, ok := <-p.OutputChan()
+	assert.False(t, ok)
+}
+
+// TestTypedPipelineMidStop verifies that a stage asking to stop does not
+// leave the stages before it, Send or Stop blocked.
+func TestTypedPipelineMidStop(t *testing.T) {
+	p1 := NewTypedPipeline[int]()
+	p2 := AddStage(p1, func(i int) (int, bool, bool) { return i, false, false })
+	p3 := AddStage(p2, func(i int) (int, bool, bool) { return i, false, i == 2 })
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 1; i <= 5; i++ {
+			p3.Send(i)
+		}
+		p3.Stop()
+	}()
+	var got []int
+	for v := range p3.OutputChan() {
+		got = append(got, v)
+	}
+	assert.Equal(t, []int{1, 2}, got)
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Send or Stop hung after a stage stopped")
+	}
+	assert.False(t, p3.IsRunning())
+}
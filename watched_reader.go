@@ -0,0 +1,142 @@
+package gocurrent
+
+import (
+	"context"
+	"time"
+)
+
+// WatchedReader wraps a Reader with a watchdog: if no message arrives for
+// idleTimeout, the current Reader is stopped and replaced by a new one built
+// from a fresh ReaderFunc. This encapsulates the usual reconnect loop for
+// unreliable sources.
+//
+// A fresh ReaderFunc is needed (rather than Reader.Restart) because the
+// stalled Read may never return. The abandoned Read is left to return on its
+// own, so the factory (or the restart callback) should release whatever it
+// is blocked on, e.g. by closing the old connection.
+type WatchedReader[R any] struct {
+	RunnerBase[string]
+	factory     func() ReaderFunc[R]
+	idleTimeout time.Duration
+	onRestart   func(restarts int)
+	output      chan Message[R]
+	closedChan  chan error
+	clock       Clock
+}
+
+// WatchedReaderOption is a functional option for configuring a WatchedReader.
+type WatchedReaderOption[R any] func(*WatchedReader[R])
+
+// WithWatchedReaderOnRestart sets a callback invoked, from the watchdog
+// goroutine, each time a stalled reader is replaced. It receives the number
+// of restarts so far.
+func WithWatchedReaderOnRestart[R any](fn func(restarts int)) WatchedReaderOption[R] {
+	return func(w *WatchedReader[R]) {
+		w.onRestart = fn
+	}
+}
+
+// WithWatchedReaderContext ties the watched reader's lifetime to ctx. When
+// ctx is done it stops itself and ClosedChan() receives ctx.Err().
+func WithWatchedReaderContext[R any](ctx context.Context) WatchedReaderOption[R] {
+	return func(w *WatchedReader[R]) {
+		w.ctx = ctx
+	}
+}
+
+// WithWatchedReaderClock sets the clock timing the idle timeout, e.g. a
+// FakeClock in tests. The default is RealClock.
+func WithWatchedReaderClock[R any](clock Clock) WatchedReaderOption[R] {
+	return func(w *WatchedReader[R]) {
+		w.clock = clock
+	}
+}
+
+// NewWatchedReader creates a WatchedReader whose readers run readFactory()
+// and are replaced whenever idleTimeout passes without a message. Messages
+// from all readers, including errors, are delivered on OutputChan(), which
+// is owned and closed on Stop.
+//
+// Example:
+//
+//	wr := NewWatchedReader(func() ReaderFunc[[]byte] {
+//	    conn := dial()
+//	    return func() ([]byte, error) { return readFrame(conn) }
+//	}, 30*time.Second)
+//	defer wr.Stop()
+func NewWatchedReader[R any](readFactory func() ReaderFunc[R], idleTimeout time.Duration, opts ...WatchedReaderOption[R]) *WatchedReader[R] {
+	out := &WatchedReader[R]{
+		RunnerBase:  NewRunnerBase("stop"),
+		factory:     readFactory,
+		idleTimeout: idleTimeout,
+		output:      make(chan Message[R]),
+		closedChan:  make(chan error, 1),
+	}
+	out.component = "WatchedReader"
+	for _, opt := range opts {
+		opt(out)
+	}
+	out.clock = clockOr(out.clock)
+	out.start()
+	return out
+}
+
+// OutputChan returns the channel on which messages can be received.
+func (w *WatchedReader[R]) OutputChan() <-chan Message[R] {
+	return w.output
+}
+
+// ClosedChan returns the channel used to signal when the watched reader is
+// done.
+func (w *WatchedReader[R]) ClosedChan() <-chan error {
+	return w.closedChan
+}
+
+func (w *WatchedReader[R]) cleanup() {
+	close(w.output)
+	close(w.closedChan)
+	w.RunnerBase.cleanup()
+}
+
+func (w *WatchedReader[R]) start() {
+	w.RunnerBase.start()
+	go func() {
+		defer w.cleanup()
+		reader := NewReader(w.factory())
+		// Stop the current reader on exit; it may have been replaced.
+		defer func() { reader.Stop() }()
+		idle := w.clock.NewTimer(w.idleTimeout)
+		defer idle.Stop()
+		restarts := 0
+		for {
+			select {
+			case <-w.controlChan:
+				return
+			case <-w.ctxDone():
+				w.setErr(w.contextErr())
+				w.closedChan <- w.contextErr()
+				return
+			case msg := <-reader.OutputChan():
+				select {
+				case w.output <- msg:
+				case <-w.controlChan:
+					return
+				case <-w.ctxDone():
+					w.setErr(w.contextErr())
+					w.closedChan <- w.contextErr()
+					return
+				}
+				idle.Reset(w.idleTimeout)
+			case <-idle.C():
+				reader.Stop()
+				restarts++
+				logf("WatchedReader: no message for %v, restarting (%d)", w.idleTimeout, restarts)
+				if w.onRestart != nil {
+					w.onRestart(restarts)
+				}
+				reader = NewReader(w.factory())
+				idle.Reset(w.idleTimeout)
+			}
+		}
+	}()
+}
@@ -0,0 +1,54 @@
+package gocurrent
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatchedReaderRestartsOnStall(t *testing.T) {
+	stall := make(chan struct{})
+	defer close(stall)
+	var built atomic.Int32
+	factory := func() ReaderFunc[int] {
+		generation := int(built.Add(1))
+		sent := false
+		return func() (int, error) {
+			if generation == 1 && sent {
+				<-stall // the first source hangs after one message
+				return 0, nil
+			}
+			sent = true
+			return generation, nil
+		}
+	}
+	restarts := make(chan int, 10)
+	wr := NewWatchedReader(factory, 100*time.Millisecond,
+		WithWatchedReaderOnRestart[int](func(n int) { restarts <- n }))
+	defer wr.Stop()
+
+	assert.Equal(t, 1, withTimeout(t, wr.OutputChan()).Value)
+	assert.Equal(t, 1, withTimeout(t, restarts))
+	assert.Equal(t, 2, withTimeout(t, wr.OutputChan()).Value)
+	assert.Equal(t, int32(2), built.Load())
+
+	// A steady source keeps the watchdog quiet.
+	for i := 0; i < 5; i++ {
+		assert.Equal(t, 2, withTimeout(t, wr.OutputChan()).Value)
+	}
+	assert.Len(t, restarts, 0)
+}
+
+func TestWatchedReaderStopClosesOutput(t *testing.T) {
+	wr := NewWatchedReader(func() ReaderFunc[int] {
+		return func() (int, error) { return 1, nil }
+	}, time.Second)
+	withTimeout(t, wr.OutputChan())
+	wr.Stop()
+	for range wr.OutputChan() {
+	}
+	withTimeout(t, wr.ClosedChan())
+	assert.False(t, wr.IsRunning())
+}
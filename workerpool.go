@@ -0,0 +1,161 @@
+package gocurrent
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// WorkerPool runs a job function over values from an input channel using a
+// fixed number of worker goroutines, and merges the results and errors onto
+// two output channels.
+//
+// Each worker is a [Mapper] reading from the shared input channel, so every
+// job is handled by exactly one worker: whichever is free first. This
+// balances load better than strict round-robin when job durations vary.
+// Results are delivered in completion order, not submission order.
+//
+//	          ┌─► Mapper(job) ─┐
+//	input ────┼─► Mapper(job) ─┼──► Results() / Errors()
+//	          └─► Mapper(job) ─┘
+//
+// Consumers must keep draining both Results() and Errors() until they are
+// closed; an unread output stalls the workers.
+type WorkerPool[I any, O any] struct {
+	input     chan I
+	selfOwnIn bool
+	job       func(I) (O, error)
+	workers   []*Mapper[I, O]
+	results   chan O
+	errors    chan error
+	running   atomic.Bool
+	submitMu  sync.RWMutex  // guards closing input against concurrent Submit
+	stopping  chan struct{} // closed by Stop to release blocked Submit calls
+	stopOnce  sync.Once
+	stopped   chan struct{}
+}
+
+// NewWorkerPool creates a pool of workers goroutines applying job to every
+// value received on input. If input is nil the pool creates and owns an
+// unbuffered input channel, fed via Submit. The pool starts immediately.
+//
+// Example:
+//
+//	pool := NewWorkerPool(nil, 10, fetchURL)
+//	go func() {
+//	    for _, u := range urls {
+//	        pool.Submit(u)
+//	    }
+//	    pool.Stop()
+//	}()
+//	go func() {
+//	    for err := range pool.Errors() {
+//	        log.Println(err)
+//	    }
+//	}()
+//	for page := range pool.Results() {
+//	    process(page)
+//	}
+func NewWorkerPool[I any, O any](input chan I, workers int, job func(I) (O, error)) *WorkerPool[I, O] {
+	if workers <= 0 {
+		workers = 1
+	}
+	p := &WorkerPool[I, O]{
+		input:    input,
+		job:      job,
+		results:  make(chan O),
+		errors:   make(chan error),
+		stopping: make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+	if p.input == nil {
+		p.input = make(chan I)
+		p.selfOwnIn = true
+	}
+	p.running.Store(true)
+	for i := 0; i < workers; i++ {
+		p.workers = append(p.workers, NewMapper(p.input, p.results, p.run))
+	}
+	return p
+}
+
+// run adapts the job function to a MapFunc, routing errors to the errors
+// channel instead of the results.
+func (p *WorkerPool[I, O]) run(in I) (out O, skip bool, stop bool) {
+	out, err := p.job(in)
+	if err != nil {
+		p.errors <- err
+		return out, true, false
+	}
+	return out, false, false
+}
+
+// InputChan returns the channel jobs are read from.
+func (p *WorkerPool[I, O]) InputChan() chan<- I {
+	return p.input
+}
+
+// Results returns the channel of successful job outputs. It is closed once
+// the pool has stopped and all workers have exited.
+func (p *WorkerPool[I, O]) Results() <-chan O {
+	return p.results
+}
+
+// Errors returns the channel of job errors. It is closed together with
+// Results().
+func (p *WorkerPool[I, O]) Errors() <-chan error {
+	return p.errors
+}
+
+// Submit queues a job. It blocks until a worker accepts it and returns false
+// if the pool has been stopped, including when Stop is called while it is
+// waiting.
+func (p *WorkerPool[I, O]) Submit(value I) bool {
+	p.submitMu.RLock()
+	defer p.submitMu.RUnlock()
+	if !p.running.Load() {
+		return false
+	}
+	select {
+	case p.input <- value:
+		return true
+	case <-p.stopping:
+		return false
+	}
+}
+
+// IsRunning returns true until Stop has been called.
+func (p *WorkerPool[I, O]) IsRunning() bool {
+	return p.running.Load()
+}
+
+// Stop shuts the pool down gracefully. Submit calls still waiting for a
+// worker return false. With an owned input channel, the input is closed and
+// the workers finish every job they have already accepted before exiting.
+// With a caller-provided input channel the workers are stopped directly and
+// unread input is left for the caller. In both cases Results() and Errors()
+// are closed once all workers have exited. Safe to call multiple times.
+func (p *WorkerPool[I, O]) Stop() error {
+	p.stopOnce.Do(func() {
+		p.running.Store(false)
+		// Release any Submit still waiting for a worker.
+		close(p.stopping)
+		if p.selfOwnIn {
+			// Wait out in-flight Submit calls before closing the input.
+			p.submitMu.Lock()
+			close(p.input)
+			p.submitMu.Unlock()
+		} else {
+			for _, w := range p.workers {
+				w.Stop()
+			}
+		}
+		for _, w := range p.workers {
+			<-w.Done()
+		}
+		close(p.results)
+		close(p.errors)
+		close(p.stopped)
+	})
+	<-p.stopped
+	return nil
+}
@@ -0,0 +1,148 @@
+package gocurrent
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkerPool(t *testing.T) {
+	const numJobs = 500
+	const numWorkers = 10
+
+	var active, maxActive atomic.Int32
+	pool := NewWorkerPool[int, int](nil, numWorkers, func(x int) (int, error) {
+		n := active.Add(1)
+		defer active.Add(-1)
+		for {
+			m := maxActive.Load()
+			if n <= m || maxActive.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		if x%50 == 0 {
+			return 0, fmt.Errorf("job %d failed", x)
+		}
+		return x * 2, nil
+	})
+
+	go func() {
+		for i := 0; i < numJobs; i++ {
+			assert.True(t, pool.Submit(i))
+		}
+		pool.Stop()
+	}()
+
+	var errs []error
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for err := range pool.Errors() {
+			errs = append(errs, err)
+		}
+	}()
+
+	var results []int
+	for r := range pool.Results() {
+		results = append(results, r)
+	}
+	wg.Wait()
+
+	assert.Len(t, errs, numJobs/50)
+	assert.Len(t, results, numJobs-numJobs/50)
+	sort.Ints(results)
+	expected := make([]int, 0, len(results))
+	for i := 0; i < numJobs; i++ {
+		if i%50 != 0 {
+			expected = append(expected, i*2)
+		}
+	}
+	assert.Equal(t, expected, results)
+	assert.LessOrEqual(t, maxActive.Load(), int32(numWorkers), "Never more jobs in flight than workers")
+	assert.False(t, pool.Submit(1), "Submit after Stop should fail")
+	assert.NoError(t, pool.Stop())
+}
+
+func TestWorkerPoolCallerOwnedInput(t *testing.T) {
+	input := make(chan string, 10)
+	pool := NewWorkerPool(input, 3, func(s string) (int, error) {
+		return len(s), nil
+	})
+
+	input <- "a"
+	input <- "bb"
+	input <- "ccc"
+	var results []int
+	for range 3 {
+		results = append(results, withTimeout(t, pool.Results()))
+	}
+	sort.Ints(results)
+	assert.Equal(t, []int{1, 2, 3}, results)
+
+	go func() {
+		for range pool.Errors() {
+		}
+	}()
+	assert.NoError(t, pool.Stop())
+	_, ok := <-pool.Results()
+	assert.False(t, ok, "Results should be closed after Stop")
+}
+
+// TestWorkerPoolStopReleasesSubmit verifies that with a caller-provided
+// input, a Submit waiting for a busy worker returns once Stop is called.
+func TestWorkerPoolStopReleasesSubmit(t *testing.T) {
+	release := make(chan struct{})
+	pool := NewWorkerPool(make(chan int), 1, func(v int) (int, error) {
+		<-release
+		return v, nil
+	})
+	go func() {
+		for range pool.Results() {
+		}
+	}()
+	assert.True(t, pool.Submit(1))
+
+	submitted := make(chan bool, 1)
+	go func() { submitted <- pool.Submit(2) }()
+	time.Sleep(20 * time.Millisecond) // let Submit block on the input
+	stopped := make(chan error, 1)
+	go func() { stopped <- pool.Stop() }()
+
+	// The only worker is still busy, so Submit must give up on its own.
+	assert.False(t, withTimeout(t, submitted))
+	close(release)
+	assert.NoError(t, withTimeout(t, stopped))
+}
+
+// TestWorkerPoolOwnedInputStopReleasesSubmit verifies that with the pool's
+// own input, too, a Submit waiting for a busy worker returns once Stop is
+// called.
+func TestWorkerPoolOwnedInputStopReleasesSubmit(t *testing.T) {
+	release := make(chan struct{})
+	pool := NewWorkerPool(nil, 1, func(v int) (int, error) {
+		<-release
+		return v, nil
+	})
+	go func() {
+		for range pool.Results() {
+		}
+	}()
+	assert.True(t, pool.Submit(1))
+
+	submitted := make(chan bool, 1)
+	go func() { submitted <- pool.Submit(2) }()
+	time.Sleep(20 * time.Millisecond) // let Submit block on the input
+	stopped := make(chan error, 1)
+	go func() { stopped <- pool.Stop() }()
+
+	// The only worker is still busy, so Submit must give up on its own.
+	assert.False(t, withTimeout(t, submitted))
+	close(release)
+	assert.NoError(t, withTimeout(t, stopped))
+}
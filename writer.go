@@ -1,7 +1,10 @@
 package gocurrent
 
 import (
-	"log"
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // WriterFunc is the type of the writer method used by the writer goroutine primitive to serialize its writes.
@@ -14,6 +17,29 @@ type Writer[W any] struct {
 	msgChannel chan W
 	Write      WriterFunc[W]
 	closedChan chan error
+	inFlight   *InFlightLimiter
+	onError    func(error) bool
+	workers    int
+
+	// Counters (see Stats)
+	writes      atomic.Int64
+	writeErrs   atomic.Int64
+	lastWriteAt atomic.Int64 // UnixNano, 0 until the first write
+}
+
+// WriterStats are cumulative counters of a Writer.
+type WriterStats struct {
+	// Writes is the number of Write calls that succeeded.
+	Writes int64
+	// Errors is the number of Write calls that failed, including those a
+	// WithWriterOnError callback chose to skip.
+	Errors int64
+	// LastWriteAt is when the last Write call returned, successful or not.
+	// It is zero until the first write.
+	LastWriteAt time.Time
+	// QueueDepth is the number of values waiting in the input buffer (see
+	// WithInputBuffer); always 0 for an unbuffered writer.
+	QueueDepth int
 }
 
 // WriterOption is a functional option for configuring a Writer
@@ -26,6 +52,52 @@ func WithInputBuffer[W any](size int) WriterOption[W] {
 	}
 }
 
+// WithWriterOnError installs a callback consulted when Write fails.
+// Returning true drops the failed value and keeps the writer running;
+// returning false stops it with the error on ClosedChan(), which is also the
+// behavior without a callback.
+func WithWriterOnError[W any](fn func(error) bool) WriterOption[W] {
+	return func(w *Writer[W]) {
+		w.onError = fn
+	}
+}
+
+// WithWriterConcurrency runs n goroutines that call Write concurrently, each
+// taking the next value from the shared input. With n > 1 values may be
+// written out of order and Write must be safe for concurrent use. A failing
+// Write stops all workers, and ClosedChan() fires only after every worker
+// has exited. The default, n == 1, serializes writes.
+func WithWriterConcurrency[W any](n int) WriterOption[W] {
+	return func(w *Writer[W]) {
+		w.workers = n
+	}
+}
+
+// WithWriterContext ties the writer's lifetime to ctx. When ctx is done the
+// writer stops itself and ClosedChan() receives ctx.Err().
+func WithWriterContext[W any](ctx context.Context) WriterOption[W] {
+	return func(w *Writer[W]) {
+		w.ctx = ctx
+	}
+}
+
+// WithWriterInFlightLimiter makes the writer hold a slot of l from the
+// moment it takes a value off its input until Write returns.
+func WithWriterInFlightLimiter[W any](l *InFlightLimiter) WriterOption[W] {
+	return func(w *Writer[W]) {
+		w.inFlight = l
+	}
+}
+
+// WithWriterDeferredStart creates the writer without starting it; call Start
+// (or Block.Start) to begin writing. Until then sends are rejected: Send
+// returns false and SendContext returns ErrStopped.
+func WithWriterDeferredStart[W any]() WriterOption[W] {
+	return func(w *Writer[W]) {
+		w.deferStart()
+	}
+}
+
 // NewWriter creates a new writer instance with functional options.
 // The writer function is required as the first parameter, with optional
 // configuration via functional options.
@@ -44,27 +116,62 @@ func NewWriter[W any](write WriterFunc[W], opts ...WriterOption[W]) *Writer[W] {
 		msgChannel: make(chan W), // default unbuffered
 		closedChan: make(chan error, 1),
 	}
+	out.component = "Writer"
 
 	// Apply options
 	for _, opt := range opts {
 		opt(out)
 	}
 
-	out.start()
+	if !out.startPending.Load() {
+		out.start()
+	}
 	return out
 }
 
+// Start starts a writer created with WithWriterDeferredStart. It returns
+// ErrAlreadyRunning if the writer was not deferred or has already been
+// started.
+func (wc *Writer[W]) Start() error {
+	if !wc.claimStart() {
+		return ErrAlreadyRunning
+	}
+	wc.start()
+	return nil
+}
+
+// DebugInfo returns diagnostic information including the writer's Stats.
 func (w *Writer[W]) DebugInfo() any {
 	return map[string]any{
 		"base":    w.RunnerBase.DebugInfo(),
 		"msgChan": w.msgChannel,
+		"stats":   w.Stats(),
+	}
+}
+
+// Stats returns the writer's cumulative counters. Safe to call from any
+// goroutine.
+func (w *Writer[W]) Stats() WriterStats {
+	stats := WriterStats{
+		Writes:     w.writes.Load(),
+		Errors:     w.writeErrs.Load(),
+		QueueDepth: w.Len(),
 	}
+	if at := w.lastWriteAt.Load(); at != 0 {
+		stats.LastWriteAt = time.Unix(0, at)
+	}
+	return stats
+}
+
+// Len returns the number of values waiting in the input buffer.
+func (w *Writer[W]) Len() int {
+	return len(w.msgChannel)
 }
 
 func (ch *Writer[T]) cleanup() {
-	log.Println("Cleaning up writer...")
+	logf("Cleaning up writer...")
 	v := ch.msgChannel
-	defer log.Println("Finished cleaning up writer: ", v)
+	defer logf("Finished cleaning up writer: %v", v)
 	// msgChannel is NOT closed here — blocked Send() calls will see Done()
 	// and return false, avoiding the concurrent close+send race.
 	close(ch.closedChan)
@@ -93,29 +200,117 @@ func (wc *Writer[W]) Send(req W) bool {
 	}
 }
 
+// SendContext sends a message to the Writer, giving up when ctx is done. It
+// returns nil once the message is accepted, ctx.Err() if ctx is done first,
+// and ErrStopped if the writer is stopped.
+func (wc *Writer[W]) SendContext(ctx context.Context, req W) error {
+	if !wc.IsRunning() {
+		return ErrStopped
+	}
+	select {
+	case wc.msgChannel <- req:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-wc.Done():
+		return ErrStopped
+	}
+}
+
 // ClosedChan returns the channel used to signal when the writer is done
 func (wc *Writer[W]) ClosedChan() <-chan error {
 	return wc.closedChan
 }
 
-// start launches the writer goroutine
+// Restart relaunches a stopped writer with the same WriterFunc and input
+// channel. ClosedChan() is re-armed, so call it again after Restart to
+// observe the new run, and Stats() starts from zero. Restart returns
+// ErrAlreadyRunning if the writer is still running, and must not be called
+// concurrently with Stop().
+func (wc *Writer[W]) Restart() error {
+	wc.restartMu.Lock()
+	defer wc.restartMu.Unlock()
+	if err := wc.reset(); err != nil {
+		return err
+	}
+	wc.writes.Store(0)
+	wc.writeErrs.Store(0)
+	wc.lastWriteAt.Store(0)
+	wc.closedChan = make(chan error, 1)
+	wc.start()
+	return nil
+}
+
+// start launches the writer goroutines: a coordinator that owns the
+// lifecycle and the configured number of workers.
 func (wc *Writer[W]) start() {
 	wc.RunnerBase.start()
+	closedChan := wc.closedChan
+	workers := max(wc.workers, 1)
 	go func() {
 		defer wc.cleanup()
-		for {
-			select {
-			case newRequest := <-wc.msgChannel:
-				err := wc.Write(newRequest)
-				if err != nil {
-					log.Println("Write Error: ", err)
-					wc.closedChan <- err
-					return
-				}
-			case controlRequest := <-wc.controlChan:
-				log.Println("Received kill signal.  Quitting Writer.", controlRequest, wc.InputChan())
+
+		quit := make(chan struct{})   // closed to make the workers exit
+		failed := make(chan struct{}) // closed by the first failing worker
+		var failOnce sync.Once
+		fail := func(err error) {
+			failOnce.Do(func() {
+				wc.setErr(wc.wrapErr(err))
+				close(failed)
+			})
+		}
+
+		var wg sync.WaitGroup
+		for range workers {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				wc.work(quit, fail)
+			}()
+		}
+
+		select {
+		case controlRequest := <-wc.controlChan:
+			logf("Received kill signal.  Quitting Writer. %v %v", controlRequest, wc.InputChan())
+		case <-wc.ctxDone():
+			fail(wc.contextErr())
+		case <-failed:
+		}
+		close(quit)
+		wg.Wait()
+		if err := wc.terminalErr(); err != nil {
+			closedChan <- err
+		}
+	}()
+}
+
+// work is the loop of a single writer worker. It returns when quit is closed
+// or after reporting a failed Write via fail.
+func (wc *Writer[W]) work(quit <-chan struct{}, fail func(error)) {
+	for {
+		select {
+		case newRequest := <-wc.msgChannel:
+			if !acquireOr(wc.inFlight, quit, nil) {
 				return
 			}
+			err := wc.Write(newRequest)
+			wc.inFlight.Release()
+			wc.lastWriteAt.Store(time.Now().UnixNano())
+			if err != nil {
+				wc.writeErrs.Add(1)
+			} else {
+				wc.writes.Add(1)
+			}
+			if err != nil && wc.onError != nil && wc.onError(err) {
+				continue
+			}
+			if err != nil {
+				logf("Write Error: %v", err)
+				fail(err)
+				return
+			}
+		case <-quit:
+			return
 		}
-	}()
+	}
 }